@@ -8,7 +8,8 @@
 {{end}}{{with .Title}}title="{{.}}"
 {{end}}{{with .Translate}}translate="{{.}}"
 {{end}}{{if eq 1 .ContentEditable}}contenteditable="true"{{else if eq 2 .ContentEditable }}contenteditable="false"
-{{end}}{{if .Hidden | eq 1}}hidden="true"{{else if .Hidden | eq 2 }}hidden="false"
+{{end}}{{if .Hidden | eq 1}}hidden
+{{end}}{{if .Spellcheck | eq 1}}spellcheck="true"{{else if .Spellcheck | eq 2 }}spellcheck="false"
 {{end}}{{with .Class}}class="{{join " " .}}"
 {{end}}{{with .Aria}}{{range $k, $v := .}}{{$k}}="{{$v}}"{{end}}
 {{end}}{{with .Data}}{{range $k, $v := .}}{{$k}}="{{$v}}"{{end}}{{end}}{{end}}
@@ -17,10 +18,11 @@
 {{end}}{{with .Value}}value="{{.}}"
 {{end}}{{with .Form}}form="{{.}}"
 {{end}}{{with .Menu}}menu="{{.}}"
-{{end}}{{with .Type}}type="{{.}}"
+{{end}}{{with .Type}}type="{{lower .}}"
 {{end}}{{if .Autofocus}}autofocus="true"
 {{end}}{{if .Disabled}}disabled="true"
-{{end}}>{{end}}
+{{end}}{{if .FormNoValidate}}formnovalidate
+{{end}}>{{if .Fields}}{{template "form.fieldloop" .Fields}}{{else}}{{.Value}}{{end}}</button>{{end}}
 
 {{define "form.keygen"}}<keygen {{template "globalAttrs" .}}{{with .Name}}name="{{.}}"
 {{end}}{{with .Form}}form="{{.}}"
@@ -31,13 +33,65 @@
 
 {{define "form.label"}}<label {{template "globalAttrs" .}}{{with .For}}for="{{.}}"
 {{end}}{{with .Form}}form="{{.}}"
-{{end}}>{{.Text}}</label>
+{{end}}>{{.Text}}{{with .Fields}}{{template "form.fieldloop" .}}{{end}}</label>
 {{end}}
 
 {{define "form.output"}}<output {{template "globalAttrs" .}}{{with .Name}}name="{{.}}"
 {{end}}{{with .Form}}form="{{.}}"
-{{end}}{{with .For}}for="{{.}}"{{end}}>{{end}}
+{{end}}{{with .For}}for="{{.}}"{{end}}>{{.Value}}</output>{{end}}
+
 
+{{define "form.computed"}}
+{{if len .Label | lt 0}}<label for="{{.Name}}">{{.Label}}</label>
+{{end}}{{if .ReadOnly}}<input type="text" {{template "globalAttrs" .}}{{with .Name}}name="{{.}}"
+{{end}}{{with .Form}}form="{{.}}"
+{{end}}{{with .Value}}value="{{.}}"
+{{end}}readonly>{{else}}<output {{template "globalAttrs" .}}{{with .Name}}name="{{.}}"
+{{end}}{{with .Form}}form="{{.}}"
+{{end}}{{with .For}}for="{{.}}"{{end}}>{{.Value}}</output>{{end}}{{end}}
+
+{{define "form.money"}}
+{{if len .Label | lt 0}}<label for="{{.Name}}">{{.Label}}</label>
+{{end}}<span {{template "globalAttrs" .}}><input type="number" name="{{.Name}}"
+value="{{.Value}}"
+step="{{.Step}}"
+{{if .Disabled}}disabled
+{{end}}{{if .Required}}required
+{{end}}>{{if len .Currencies | lt 1}}<select name="{{.CurrencyName}}"
+{{if .Disabled}}disabled
+{{end}}{{if .Required}}required
+{{end}}>{{$cur := .Currency}}{{range .Currencies}}
+<option value="{{.}}"{{if eq . $cur}} selected{{end}}>{{.}}</option>{{end}}
+</select>{{end}}</span>{{end}}
+
+{{define "form.duration"}}
+{{if len .Label | lt 0}}<label for="{{.Name}}">{{.Label}}</label>
+{{end}}<span {{template "globalAttrs" .}}>{{if .Units}}<input type="number" name="{{.Name}}"
+value="{{.Count}}"
+step="1"
+{{else}}<input type="text" name="{{.Name}}"
+value="{{.Count}}"
+pattern="[0-9]+:[0-5][0-9]"
+inputmode="numeric"
+{{end}}{{if .Disabled}}disabled
+{{end}}{{if .Required}}required
+{{end}}>{{if .Units}}<select name="{{.UnitName}}"
+{{if .Disabled}}disabled
+{{end}}{{if .Required}}required
+{{end}}>{{range .UnitOptions}}
+<option value="{{.Value}}"{{if .Selected}} selected{{end}}>{{.Label}}</option>{{end}}
+</select>{{end}}</span>{{end}}
+
+{{/* Inline script and style content is not trusted by html/template, so
+only external scripts and stylesheet media are rendered here. */}}
+{{define "form.script"}}<script {{template "globalAttrs" .}}{{with .Src}}src="{{.}}"
+{{end}}{{with .Type}}type="{{.}}"
+{{end}}{{with .Nonce}}nonce="{{.}}"
+{{end}}{{if .Async}}async
+{{end}}{{if .Defer}}defer{{end}}></script>{{end}}
+
+{{define "form.style"}}<style {{template "globalAttrs" .}}{{with .Media}}media="{{.}}"
+{{end}}{{with .Nonce}}nonce="{{.}}"{{end}}></style>{{end}}
 
 {{define "form.progress"}}<progress {{template "globalAttrs" .}}{{with .Value}}value="{{.}}"
 {{end}}{{with .Max}}max="{{.}}"{{end}}>{{end}}
@@ -69,6 +123,7 @@
 {{define "form.select"}}
 {{if len .Label | lt 0}}<label for="{{.Name}}">{{.Label}}</label>{{end}}
 <select {{template "globalAttrs" .}}{{with .Name}}name="{{.}}"
+{{end}}{{with .Autocomplete}}autocomplete="{{.}}"
 {{end}}{{with .Autofocus}}autofocus
 {{end}}{{with .Disabled}}disabled
 {{end}}{{with .Multiple}}multiple
@@ -88,6 +143,7 @@
 {{end}}{{with .MaxLength}}maxlength="{{.}}"
 {{end}}{{with .MinLength}}minlength="{{.}}"
 {{end}}{{with .Rows}}rows="{{.}}"
+{{end}}{{if .Counter}}data-counter="{{.MaxLength}}"
 {{end}}{{with .Autofocus}}autofocus
 {{end}}{{with .Disabled}}disabled
 {{end}}{{with .ReadOnly}}readonly
@@ -102,6 +158,9 @@
 {{define "form.email"}}{{template "form.input" .}}{{end}}
 {{define "form.date"}}{{template "form.input" .}}{{end}}
 {{define "form.time"}}{{template "form.input" .}}{{end}}
+{{define "form.month"}}{{template "form.input" .}}{{end}}
+{{define "form.week"}}{{template "form.input" .}}{{end}}
+{{define "form.search"}}{{template "form.input" .}}{{end}}
 {{define "form.number"}}{{template "form.input" .}}{{end}}
 {{define "form.range"}}{{template "form.input" .}}{{end}}
 {{define "form.color"}}{{template "form.input" .}}{{end}}
@@ -129,6 +188,36 @@
 {{end}}{{with .Autofocus}}autofocus
 {{end}}{{with .Checked}}checked
 {{end}}{{with .Disabled}}disabled
+{{end}}{{with .FormNoValidate}}formnovalidate
+{{end}}{{with .Required}}required
+{{end}}>{{end}}
+
+{{define "form.alphacolor"}}
+{{if len .Label | lt 0}}<label for="{{.Name}}">{{.Label}}</label>
+{{end}}<input type="text" {{template "globalAttrs" .}}{{with .Name}}name="{{.}}"
+{{end}}{{with .Form}}form="{{.}}"
+{{end}}pattern="{{or .Pattern "#[0-9a-fA-F]{6}([0-9a-fA-F]{2})?"}}"
+{{with .Placeholder}}placeholder="{{.}}"
+{{end}}{{with .Value}}value="{{.}}"
+{{end}}{{with .Autofocus}}autofocus
+{{end}}{{with .Disabled}}disabled
+{{end}}{{with .ReadOnly}}readonly
+{{end}}{{with .Required}}required
+{{end}}>{{end}}
+
+{{define "form.datetimelocal"}}
+{{if len .Label | lt 0}}<label for="{{.Name}}">{{.Label}}</label>
+{{end}}<input type="datetime-local" {{template "globalAttrs" .}}{{with .Name}}name="{{.}}"
+{{end}}{{with .Autocomplete}}autocomplete="{{.}}"
+{{end}}{{with .Form}}form="{{.}}"
+{{end}}{{with .List}}list="{{.}}"
+{{end}}{{with .Min}}min="{{.}}"
+{{end}}{{with .Max}}max="{{.}}"
+{{end}}{{with .Step}}step="{{.}}"
+{{end}}{{with .Value}}value="{{.}}"
+{{end}}{{with .Autofocus}}autofocus
+{{end}}{{with .Disabled}}disabled
+{{end}}{{with .ReadOnly}}readonly
 {{end}}{{with .Required}}required
 {{end}}>{{end}}
 
@@ -136,6 +225,7 @@
 {{if len .Label | lt 0}}<label for="{{.Name}}">{{.Label}}</label>
 {{end}}<input type="{{$t := typeOf . | split "."}}{{lower $t._1}}" {{template "globalAttrs" .}}{{with .Name}}name="{{.}}"
 {{end}}{{with .Accept}}accept="{{.}}"
+{{end}}{{with .Capture}}capture="{{.}}"
 {{end}}{{with .Alt}}alt="{{.}}"
 {{end}}{{with .Autocomplete}}autocomplete="{{.}}"
 {{end}}{{with .Dirname}}dirname="{{.}}"
@@ -156,6 +246,7 @@
 {{end}}{{with .Autofocus}}autofocus
 {{end}}{{with .Checked}}checked
 {{end}}{{with .Disabled}}disabled
+{{end}}{{with .FormNoValidate}}formnovalidate
 {{end}}{{with .Multiple}}multiple
 {{end}}{{with .ReadOnly}}readonly
 {{end}}{{with .Required}}required
@@ -198,6 +289,11 @@
 {{if . | typeIsLike "form.Label" }}{{template "form.label" . }}{{end}}
 {{if . | typeIsLike "form.Keygen" }}{{template "form.keygen" . }}{{end}}
 {{if . | typeIsLike "form.Output" }}{{template "form.output" . }}{{end}}
+{{if . | typeIsLike "form.Computed" }}{{template "form.computed" . }}{{end}}
+{{if . | typeIsLike "form.Money" }}{{template "form.money" . }}{{end}}
+{{if . | typeIsLike "form.Duration" }}{{template "form.duration" . }}{{end}}
+{{if . | typeIsLike "form.Script" }}{{template "form.script" . }}{{end}}
+{{if . | typeIsLike "form.Style" }}{{template "form.style" . }}{{end}}
 {{if . | typeIsLike "form.Progress" }}{{template "form.progress" . }}{{end}}
 {{if . | typeIsLike "form.Meter" }}{{template "form.meter" . }}{{end}}
 {{if . | typeIsLike "form.DataList" }}{{template "form.datalist" . }}{{end}}
@@ -212,17 +308,23 @@
 {{if . | typeIsLike "form.Email" }}{{template "form.email" . }}{{end}}
 {{if . | typeIsLike "form.Date" }}{{template "form.date" . }}{{end}}
 {{if . | typeIsLike "form.Time" }}{{template "form.time" . }}{{end}}
+{{if . | typeIsLike "form.Month" }}{{template "form.month" . }}{{end}}
+{{if . | typeIsLike "form.Week" }}{{template "form.week" . }}{{end}}
+{{if . | typeIsLike "form.DatetimeLocal" }}{{template "form.datetimelocal" . }}{{end}}
+{{if . | typeIsLike "form.Search" }}{{template "form.search" . }}{{end}}
 {{if . | typeIsLike "form.Number" }}{{template "form.number" . }}{{end}}
 {{if . | typeIsLike "form.Range" }}{{template "form.range" . }}{{end}}
 {{if . | typeIsLike "form.Color" }}{{template "form.color" . }}{{end}}
+{{if . | typeIsLike "form.AlphaColor" }}{{template "form.alphacolor" . }}{{end}}
 {{if . | typeIsLike "form.Checkbox" }}{{template "form.checkbox" . }}{{end}}
 {{if . | typeIsLike "form.Radio" }}{{template "form.radio" . }}{{end}}
 {{if . | typeIsLike "form.File" }}{{template "form.file" . }}{{end}}
 {{if . | typeIsLike "form.Image" }}{{template "form.image" . }}{{end}}
-{{if . | typeIsLike "form.Reset" }}{{template "form.range" . }}{{end}}
+{{if . | typeIsLike "form.Reset" }}{{template "form.reset" . }}{{end}}
 {{if . | typeIsLike "form.ButtonInput" }}{{template "form.buttoninput" . }}{{end}}
 {{if . | typeIsLike "form.Hidden" }}{{template "form.hidden" . }}{{end}}
 {{if . | typeIsLike "form.Div" }}{{template "form.div" .}}{{end}}
+{{if . | typeIsLike "form.Form" }}{{template "form.embedded" .}}{{end}}
 {{if . | typeIsLike "form.String" }}{{.}}{{end}}
 {{end}}
 {{end}}
@@ -235,19 +337,27 @@
 {{end}}{{template "form.fieldloop" .Fields}}
 </fieldset>{{end}}
 
+{{/* Embedded forms are rendered as fieldsets. Prefixes are not applied. */}}
+{{define "form.embedded"}}<fieldset {{template "globalAttrs" .}}{{with .Name}}name="{{.}}"{{end}}>
+{{template "form.fieldloop" .Fields}}
+</fieldset>{{end}}
+
 {{define "form.div"}}<div {{template "globalAttrs" .}}>{{template "form.fieldloop" .Fields}}</div>{{end}}
 
 
 {{define "form"}}
 <form {{template "globalAttrs" .  }}{{with .Name}}name="{{.}}" {{end}}
-{{with .AcceptCharset}}acceptchars="{{.}}"
+{{with .AcceptCharset}}accept-charset="{{join " " .}}"
 {{end}}{{with .Enctype}}enctype="{{.}}"
 {{end}}{{with .Action }}action="{{.}}"
 {{end}}{{with .Method}}method="{{.}}"
 {{end}}{{with .Target}}target="{{.}}"
-{{end}}{{with .Autocomplete}}autocomplete="true"
-{{end}}{{with .Novalidate}}novalidate="true" {{end}}>
+{{end}}{{with .Relationship}}rel="{{.}}"
+{{end}}{{with .Autocomplete}}autocomplete="{{.}}"
+{{end}}{{with .Novalidate}}novalidate {{end}}>
 {{template "form.fieldloop" .Fields}}
 </form>
 {{end}}
 
+{{/* Fields that belong to the form, but are rendered outside of it. */}}
+{{define "form.external"}}{{template "form.fieldloop" .External}}{{end}}
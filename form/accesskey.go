@@ -0,0 +1,108 @@
+package form
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// AccessKey describes a keyboard shortcut assigned to a field.
+type AccessKey struct {
+	// Key is the shortcut, in lower case.
+	Key string
+	// Field is the field the shortcut activates.
+	Field Field
+	// Description names the field for users, such as in a legend.
+	Description string
+}
+
+// AccessKeys returns all access keys assigned to the form's fields, in order.
+//
+// The HTML AccessKey attribute may list several keys, each of which is
+// returned separately. Fields in embedded forms are included, since they
+// share a page with the form.
+func (f *Form) AccessKeys() []AccessKey {
+	keys := []AccessKey{}
+	walkFields(f.allFields(), func(field Field) {
+		if sub, ok := field.(*Form); ok {
+			keys = append(keys, sub.AccessKeys()...)
+			return
+		}
+		h := htmlOf(field)
+		if h == nil {
+			return
+		}
+		for _, k := range strings.Fields(h.AccessKey) {
+			keys = append(keys, AccessKey{
+				Key:         strings.ToLower(k),
+				Field:       field,
+				Description: describe(field),
+			})
+		}
+	})
+	return keys
+}
+
+// AccessKeyConflicts returns the access keys that are assigned to more than one field.
+//
+// User agents silently pick one of the conflicting fields (or cycle
+// between them), so these are almost always mistakes.
+func (f *Form) AccessKeyConflicts() map[string][]Field {
+	byKey := map[string][]Field{}
+	for _, k := range f.AccessKeys() {
+		byKey[k.Key] = append(byKey[k.Key], k.Field)
+	}
+	for k, fields := range byKey {
+		if len(fields) < 2 {
+			delete(byKey, k)
+		}
+	}
+	return byKey
+}
+
+// AccessKeyLegend renders a visible list of the form's shortcuts.
+//
+// The legend is a definition list with the class "accesskeys", with each
+// key in a kbd element. It returns nil if the form has no access keys.
+func (f *Form) AccessKeyLegend() *html.Node {
+	keys := f.AccessKeys()
+	if len(keys) == 0 {
+		return nil
+	}
+	dl := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Dl,
+		Data:     "dl",
+		Attr:     []html.Attribute{{Key: "class", Val: "accesskeys"}},
+	}
+	for _, k := range keys {
+		kbd := &html.Node{Type: html.ElementNode, DataAtom: atom.Kbd, Data: "kbd"}
+		kbd.AppendChild(&html.Node{Type: html.TextNode, Data: k.Key})
+		dt := &html.Node{Type: html.ElementNode, DataAtom: atom.Dt, Data: "dt"}
+		dt.AppendChild(kbd)
+		dd := &html.Node{Type: html.ElementNode, DataAtom: atom.Dd, Data: "dd"}
+		dd.AppendChild(&html.Node{Type: html.TextNode, Data: k.Description})
+		dl.AppendChild(dt)
+		dl.AppendChild(dd)
+	}
+	return dl
+}
+
+// describe returns a human-readable name for a field.
+//
+// This is the field's label, title, text, or value, or else its name.
+func describe(f Field) string {
+	if l := labelOf(f); len(l) > 0 {
+		return l
+	}
+	if h := htmlOf(f); h != nil && len(h.Title) > 0 {
+		return h.Title
+	}
+	for _, n := range []string{"Text", "Legend", "Value", "Name"} {
+		if v := stringField(f, n); len(v) > 0 {
+			return v
+		}
+	}
+	return ""
+}
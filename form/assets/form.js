@@ -0,0 +1,75 @@
+(function() {
+  function counter(el) {
+    var max = parseInt(el.getAttribute("data-counter"), 10) || 0;
+    var out = document.createElement("output");
+    out.className = "counter";
+    out.setAttribute("aria-live", "polite");
+    if (el.id) {
+      out.htmlFor = el.id;
+    }
+    var update = function() {
+      out.value = max > 0 ? el.value.length + " / " + max : String(el.value.length);
+    };
+    el.parentNode.insertBefore(out, el.nextSibling);
+    el.addEventListener("input", update);
+    update();
+  }
+  function upload(el) {
+    var hidden = document.createElement("input");
+    hidden.type = "hidden";
+    hidden.name = el.name;
+    el.parentNode.insertBefore(hidden, el.nextSibling);
+    el.removeAttribute("name");
+    el.addEventListener("change", function() {
+      var file = el.files[0];
+      hidden.value = "";
+      if (!file) {
+        return;
+      }
+      var token = el.form && el.form.elements["__token__"];
+      var body = new FormData();
+      body.append("__token__", token ? token.value : "");
+      body.append("name", hidden.name);
+      body.append("filename", file.name);
+      body.append("type", file.type);
+      body.append("size", String(file.size));
+      el.setCustomValidity("Uploading");
+      fetch(el.getAttribute("data-upload"), {method: "POST", body: new URLSearchParams(body)})
+        .then(function(res) {
+          if (!res.ok) {
+            return res.text().then(function(msg) { throw new Error(msg); });
+          }
+          return res.json();
+        })
+        .then(function(p) {
+          return fetch(p.url, {method: p.method, body: file, headers: {"Content-Type": file.type}})
+            .then(function(res) {
+              if (!res.ok) {
+                throw new Error(res.statusText);
+              }
+              hidden.value = p.key;
+              el.setCustomValidity("");
+            });
+        })
+        .catch(function(err) {
+          el.setCustomValidity(err.message);
+          el.reportValidity();
+        });
+    });
+  }
+  function init() {
+    var els = document.querySelectorAll("textarea[data-counter]");
+    for (var i = 0; i < els.length; i++) {
+      counter(els[i]);
+    }
+    els = document.querySelectorAll("input[type=file][data-upload]");
+    for (var i = 0; i < els.length; i++) {
+      upload(els[i]);
+    }
+  }
+  if (document.readyState === "loading") {
+    document.addEventListener("DOMContentLoaded", init);
+  } else {
+    init();
+  }
+})();
@@ -0,0 +1,20 @@
+package form
+
+// Values for Autocomplete attributes.
+//
+// A form's Autocomplete is either AutocompleteOn or AutocompleteOff, and is
+// inherited by its fields. A field's own Autocomplete overrides the form's,
+// and may also be an autofill detail token, such as "email" or "postal-code".
+//
+// Browsers do not treat these the same. In particular, most ignore "off" on
+// login fields, so that password managers keep working. To keep a password
+// manager from filling a field (for example, on a sign-up or password change
+// form), set the field's Autocomplete to AutocompleteNewPassword instead.
+const (
+	AutocompleteOn              = "on"
+	AutocompleteOff             = "off"
+	AutocompleteNewPassword     = "new-password"
+	AutocompleteCurrentPassword = "current-password"
+	AutocompleteOneTimeCode     = "one-time-code"
+	AutocompleteUsername        = "username"
+)
@@ -0,0 +1,71 @@
+package form
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// AssignIDs gives each of the form's fields that does not have an ID one
+// derived from the field's path.
+//
+// The ID is the path (see Walk) with dots and brackets replaced by dashes
+// (e.g. "signup-address-street", or "signup-plan-1" for signup.plan[1]).
+// Whitespace is also replaced by dashes. The form itself is given its name
+// as an ID. Fields of embedded forms are further qualified by the embedded
+// form's Prefix when rendered.
+//
+// The IDs are stable as long as the structure of the form is, so they can
+// be used by stylesheets, scripts, and end-to-end tests.
+//
+// A form with AutoID set calls AssignIDs when it is rendered, and also
+// gives IDs to labels (the field's ID followed by "-label") and error lists
+// (followed by "-errors").
+func (f *Form) AssignIDs() {
+	if len(f.HTML.Id) == 0 {
+		f.HTML.Id = pathID(pathSegment(f.Name))
+	}
+	f.Walk(func(path string, field Field) {
+		if h := htmlOf(field); h != nil && len(h.Id) == 0 {
+			h.Id = pathID(path)
+		}
+	})
+}
+
+// idReplacer converts the separators of a path to dashes, and removes
+// escapes.
+var idReplacer = strings.NewReplacer(`\\`, `\`, `\.`, `.`, `\[`, `[`, `\]`, `]`, ".", "-", "[", "-", "]", "")
+
+// pathID converts a path to an ID, which may not contain whitespace.
+func pathID(path string) string {
+	return strings.Join(strings.Fields(idReplacer.Replace(path)), "-")
+}
+
+// labelIDs gives each label element in a node tree that does not have an
+// ID one based on the ID of the field it labels.
+func labelIDs(n *html.Node) {
+	if n.Type == html.ElementNode && n.Data == "label" && len(attrValue(n, "id")) == 0 {
+		id := attrValue(n, "for")
+		if len(id) == 0 {
+			for c := n.FirstChild; c != nil && len(id) == 0; c = c.NextSibling {
+				id = attrValue(c, "id")
+			}
+		}
+		if len(id) > 0 {
+			setAttr(n, "id", id+"-label")
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		labelIDs(c)
+	}
+}
+
+// attrValue returns the value of an attribute of a node, or the empty string.
+func attrValue(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,107 @@
+// Package bench holds the benchmarks of the form package, and the forms
+// they use.
+//
+// The benchmarks cover the paths that run on every request: building each
+// field type's Element, rendering a whole form, converting a form to and
+// from values, and storing forms in caches. Run them with:
+//
+//	go test -run NONE -bench . -benchmem ./form/bench
+//
+// Use benchstat to compare runs before and after a change that is meant to
+// make rendering faster, such as pooling or generated code.
+//
+// Baseline, measured on one core of an Intel Xeon (amd64, Go 1.27), with
+// GOMAXPROCS=1:
+//
+//	BenchmarkElement/Text              550 ns/op       2 allocs/op
+//	BenchmarkElement/Select           6200 ns/op      33 allocs/op
+//	BenchmarkElement/TextArea         2400 ns/op      13 allocs/op
+//	BenchmarkRender/Small            11800 ns/op      34 allocs/op
+//	BenchmarkRender/Large          1530000 ns/op    6067 allocs/op
+//	BenchmarkRender/LargeDirect    1380000 ns/op    5268 allocs/op
+//	BenchmarkRender/LargeSlab      1500000 ns/op    4516 allocs/op
+//	BenchmarkAsValues/Large          52600 ns/op     417 allocs/op
+//	BenchmarkAsValues/LargeAppend     6000 ns/op       3 allocs/op
+//	BenchmarkReconcile/Large         87300 ns/op     200 allocs/op
+//	BenchmarkCache/Memory              275 ns/op       1 allocs/op
+//	BenchmarkCache/File             322000 ns/op    1291 allocs/op
+//
+// Timings vary too much between machines to be checked automatically, but
+// allocations do not. TestBudgets fails if the allocations of the main
+// paths grow well beyond the baseline, so regressions are caught by go test.
+package bench
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/engine/form"
+)
+
+// Fields returns one field of each type, keyed by type name.
+func Fields() map[string]form.Field {
+	return map[string]form.Field{
+		"Text":     &form.Text{Name: "text", Label: "Text", Value: "value", Required: true},
+		"Password": &form.Password{Name: "password", Label: "Password"},
+		"Email":    &form.Email{Name: "email", Label: "Email", Value: "a@example.com"},
+		"Number":   &form.Number{Name: "number", Min: "0", Max: "10", Value: "3"},
+		"Date":     &form.Date{Name: "date", Value: "2016-01-01"},
+		"Checkbox": &form.Checkbox{Name: "checkbox", Label: "Check", Value: "1", Checked: true},
+		"Radio":    &form.Radio{Name: "radio", Label: "Radio", Value: "1"},
+		"Hidden":   &form.Hidden{Name: "hidden", Value: "1"},
+		"Submit":   &form.Submit{Name: "submit", Value: "Go"},
+		"Button":   &form.Button{Name: "button", Value: "Push"},
+		"TextArea": &form.TextArea{Name: "textarea", Rows: 5, Value: "Lorem ipsum"},
+		"Select": &form.Select{Name: "select", Label: "Pick", Options: []form.OptionItem{
+			&form.Option{Value: "a", Label: "A"},
+			&form.Option{Value: "b", Label: "B", Selected: true},
+			&form.OptGroup{Label: "More", Options: []*form.Option{
+				{Value: "c", Label: "C"}, {Value: "d", Label: "D"},
+			}},
+		}},
+		"FieldSet": &form.FieldSet{Name: "fieldset", Legend: "Set", Fields: []form.Field{
+			&form.Text{Name: "inner"},
+		}},
+	}
+}
+
+// Small returns a login form.
+func Small() *form.Form {
+	f := form.New("login", "/login")
+	f.Fields = []form.Field{
+		&form.Text{Name: "user", Label: "User", Required: true},
+		&form.Password{Name: "password", Label: "Password", Required: true},
+		&form.Checkbox{Name: "remember", Label: "Remember me", Value: "1"},
+		&form.Submit{Name: "go", Value: "Log in"},
+	}
+	return f
+}
+
+// Large returns a form with sets fieldsets of ten fields each.
+//
+// Large(50) has 500 fields, the size at which forms are considered large.
+func Large(sets int) *form.Form {
+	f := form.New("large", "/large")
+	for i := 0; i < sets; i++ {
+		fs := &form.FieldSet{Name: fmt.Sprintf("set%d", i), Legend: fmt.Sprintf("Set %d", i)}
+		for j := 0; j < 10; j++ {
+			name := fmt.Sprintf("f%d-%d", i, j)
+			switch j % 5 {
+			case 0:
+				fs.Fields = append(fs.Fields, &form.Text{Name: name, Label: "Text", Value: "value"})
+			case 1:
+				fs.Fields = append(fs.Fields, &form.Checkbox{Name: name, Label: "Check", Value: "1"})
+			case 2:
+				fs.Fields = append(fs.Fields, &form.Select{Name: name, Label: "Pick", Options: []form.OptionItem{
+					&form.Option{Value: "a", Label: "A"}, &form.Option{Value: "b", Label: "B", Selected: true},
+				}})
+			case 3:
+				fs.Fields = append(fs.Fields, &form.TextArea{Name: name, Value: "Lorem ipsum"})
+			default:
+				fs.Fields = append(fs.Fields, &form.Number{Name: name, Label: "Count", Value: "3"})
+			}
+		}
+		f.Fields = append(f.Fields, fs)
+	}
+	f.Fields = append(f.Fields, &form.Submit{Name: "go", Value: "Go"})
+	return f
+}
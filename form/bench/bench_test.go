@@ -0,0 +1,145 @@
+package bench
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/engine/form"
+	"github.com/Masterminds/engine/form/cache"
+)
+
+func BenchmarkElement(b *testing.B) {
+	fields := Fields()
+	names := make([]string, 0, len(fields))
+	for n := range fields {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		e := fields[n].(form.FormElement)
+		b.Run(n, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				e.Element()
+			}
+		})
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	for _, c := range []struct {
+		name string
+		f    *form.Form
+		opts form.RenderOptions
+	}{
+		{"Small", Small(), form.RenderOptions{}},
+		{"Large", Large(50), form.RenderOptions{}},
+		{"LargePretty", Large(50), form.RenderOptions{Mode: form.Pretty}},
+		{"SmallDirect", Small(), form.RenderOptions{Direct: true}},
+		{"LargeDirect", Large(50), form.RenderOptions{Direct: true}},
+		{"LargeSlab", Large(50), form.RenderOptions{Slab: true}},
+	} {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				form.Render(ioutil.Discard, c.f, c.opts)
+			}
+		})
+	}
+}
+
+func BenchmarkAsValues(b *testing.B) {
+	f := Large(50)
+	b.Run("Large", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			f.AsValues()
+		}
+	})
+	b.Run("LargeAppend", func(b *testing.B) {
+		b.ReportAllocs()
+		var vals []form.Value
+		for i := 0; i < b.N; i++ {
+			vals = f.AppendValues(vals[:0])
+		}
+	})
+}
+
+func BenchmarkReconcile(b *testing.B) {
+	f := Large(50)
+	vals := f.AsValues()
+	b.Run("Large", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			form.Reconcile(f, vals)
+		}
+	})
+}
+
+func BenchmarkCache(b *testing.B) {
+	dir, err := ioutil.TempDir("", "form-bench")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fc, err := cache.NewFile(dir, time.Hour)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name string
+		c    form.Cache
+	}{
+		{"Memory", form.NewCache()},
+		{"File", form.FromCache(fc)},
+	} {
+		f := Small()
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := c.c.Set("bench", f, time.Now().Add(time.Hour)); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := c.c.Get("bench"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// TestBudgets checks the allocations of the main paths against budgets.
+//
+// The budgets are about half again the baseline (see the package
+// documentation). If a change is expected to allocate more, raise the
+// budget in the same change, and say why. The budget of Direct, which
+// exists to allocate less, is kept below the allocations of rendering
+// without it, so that it must keep paying off.
+func TestBudgets(t *testing.T) {
+	small, large := Small(), Large(50)
+	vals := large.AsValues()
+	appended := large.AppendValues(nil)
+	for _, c := range []struct {
+		name   string
+		budget float64
+		fn     func()
+	}{
+		{"Render/Small", 60, func() { form.Render(ioutil.Discard, small, form.RenderOptions{}) }},
+		{"Render/Large", 9000, func() { form.Render(ioutil.Discard, large, form.RenderOptions{}) }},
+		{"Render/LargeSlab", 6800, func() { form.Render(ioutil.Discard, large, form.RenderOptions{Slab: true}) }},
+		{"Render/LargeDirect", 5600, func() { form.Render(ioutil.Discard, large, form.RenderOptions{Direct: true}) }},
+		{"AsValues/Large", 650, func() { large.AsValues() }},
+		{"AppendValues/Large", 5, func() { appended = large.AppendValues(appended[:0]) }},
+		{"Reconcile/Large", 300, func() { form.Reconcile(large, vals) }},
+	} {
+		got := testing.AllocsPerRun(10, c.fn)
+		t.Logf("%s: %.0f allocs/op", c.name, got)
+		if got > c.budget {
+			t.Errorf("%s: %.0f allocs/op, over budget of %.0f", c.name, got, c.budget)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotStructPointer indicates that values were to be stored in something
+// other than a pointer to a struct.
+var ErrNotStructPointer = errors.New("Values can only be stored in a pointer to a struct")
+
+// BindError indicates that a submitted value could not be stored in a
+// struct field.
+type BindError struct {
+	Name, Value string
+	Err         error
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("Cannot store %q in %s: %s", e.Value, e.Name, e.Err)
+}
+
+// Bind stores submitted values in the struct dst points to.
+//
+// Each exported field of the struct takes the value of the same name, or
+// of the name in its "form" tag (which may be followed by options; see
+// FromStruct); fields tagged "-" are skipped, and the
+// fields of embedded structs are bound as if they were the struct's own.
+// Fields may be strings, bools, integers, floats, time.Times, or slices or
+// pointers of these; a slice takes all of a name's values, and other
+// fields take the first. An empty value sets a number to zero. Times are
+// parsed as RFC 3339, or in the formats of datetime-local, date and time
+// inputs. A bool is true unless its value is "false", "off" or "0".
+//
+// Fields without a value are left as they are. A checkbox that is not
+// checked is not submitted, so to clear its bool, bind the form's values
+// with an UncheckedValue:
+//
+//	f.UncheckedValue = "0"
+//	err := form.Bind(*f.AsValues(), &dst)
+//
+// If dst is not a pointer to a struct, ErrNotStructPointer is returned. If
+// a value cannot be converted, a *BindError is returned.
+func Bind(vals url.Values, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+	return bindStruct(vals, v.Elem())
+}
+
+// timeLayouts are the layouts tried, in order, when a time is decoded.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+	"2006-01",
+	"15:04:05",
+	"15:04",
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// structField is a struct field that holds the value of a form field.
+type structField struct {
+	name  string
+	index []int
+}
+
+// structFields returns the fields of a struct type that hold form values.
+//
+// A field holds the value of the form field named by its "form" tag, or by
+// its own name if it has no tag. Fields tagged "-" and unexported fields
+// are skipped, and the fields of embedded structs are included as if they
+// were the struct's own.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, _ := parseTag(sf.Tag.Get("form"))
+		if tag == "-" {
+			continue
+		}
+		if sf.Anonymous && len(tag) == 0 && sf.Type.Kind() == reflect.Struct && sf.Type != timeType {
+			for _, f := range structFields(sf.Type) {
+				f.index = append([]int{i}, f.index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if len(sf.PkgPath) > 0 {
+			continue
+		}
+		if len(tag) == 0 {
+			tag = sf.Name
+		}
+		fields = append(fields, structField{name: tag, index: sf.Index})
+	}
+	return fields
+}
+
+// bindStruct stores the values in the fields of the struct v. Fields with
+// no value are left alone.
+func bindStruct(vals url.Values, v reflect.Value) error {
+	for _, f := range structFields(v.Type()) {
+		fv := v.FieldByIndex(f.index)
+		vv, ok := vals[f.name]
+		if !ok || len(vv) == 0 {
+			continue
+		}
+		if err := bindValue(fv, vv); err != nil {
+			return &BindError{Name: f.name, Value: vv[0], Err: err}
+		}
+	}
+	return nil
+}
+
+// bindValue stores the values in v. Slices take all of the values; other
+// kinds take the first.
+func bindValue(v reflect.Value, vals []string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return bindValue(v.Elem(), vals)
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := bindValue(s.Index(i), []string{val}); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+
+	val := vals[0]
+	if v.Type() == timeType {
+		t, err := parseTime(val)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(val)
+	case reflect.Bool:
+		v.SetBool(parseBool(val))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if len(val) == 0 {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(val), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if len(val) == 0 {
+			v.SetUint(0)
+			return nil
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(val), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if len(val) == 0 {
+			v.SetFloat(0)
+			return nil
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(val), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		v.SetBytes([]byte(val))
+	default:
+		return fmt.Errorf("Unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// parseTime parses a time in one of the timeLayouts. An empty value is the
+// zero time.
+func parseTime(val string) (time.Time, error) {
+	if len(val) == 0 {
+		return time.Time{}, nil
+	}
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, val); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// parseBool reports whether a submitted value is true. A checkbox submits
+// its value (by default "on") only when it is checked, so any value other
+// than "false", "off", or "0" is true.
+func parseBool(val string) bool {
+	switch strings.ToLower(strings.TrimSpace(val)) {
+	case "false", "off", "0":
+		return false
+	}
+	return true
+}
+
+// formatValue returns the values of v as strings, using the layout for
+// times. A zero time has no value.
+func formatValue(v reflect.Value, layout string) []string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		return formatValue(v.Elem(), layout)
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return nil
+		}
+		return []string{t.Format(layout)}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return []string{v.String()}
+	case reflect.Bool:
+		return []string{strconv.FormatBool(v.Bool())}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []string{strconv.FormatInt(v.Int(), 10)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []string{strconv.FormatUint(v.Uint(), 10)}
+	case reflect.Float32, reflect.Float64:
+		return []string{strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return []string{string(v.Bytes())}
+		}
+		var vals []string
+		for i := 0; i < v.Len(); i++ {
+			vals = append(vals, formatValue(v.Index(i), layout)...)
+		}
+		return vals
+	}
+	return nil
+}
+
+// timeLayout returns the layout of the times submitted by a field.
+func timeLayout(field Field) string {
+	switch field.(type) {
+	case *Date:
+		return "2006-01-02"
+	case *Time:
+		return "15:04"
+	case *Month:
+		return "2006-01"
+	case *DatetimeLocal:
+		return "2006-01-02T15:04"
+	}
+	return time.RFC3339
+}
@@ -0,0 +1,179 @@
+package form
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// ErrBulkRecords indicates that the records of a bulk edit form are not a
+// slice of structs, or of pointers to structs.
+var ErrBulkRecords = errors.New("Bulk edit records must be a slice of structs")
+
+// NewBulkEdit builds a form for editing many records at once, as on the
+// mass-update screens of an admin.
+//
+// The prototype declares the fields of one record. Each record gets a row:
+// a copy of the prototype, with the Prefix "<name>-<i>-" (where name is the
+// prototype's Name, or "row" if it has none, and i is the record's index),
+// and with its fields set from the record's fields. Struct fields are
+// matched to form fields by their "form" tags, or by their names, as Bind
+// matches them. The rows are laid out in a Table, whose headers are the
+// labels of the prototype's fields, and the prototype's validators are
+// added to the form for each row. Use a Hidden field for a record's key, so
+// that the rows can be matched to the records when they are decoded.
+//
+// records must be a slice of structs, or of pointers to structs;
+// otherwise ErrBulkRecords is returned.
+func NewBulkEdit(proto *Form, records interface{}) (*Form, error) {
+	rv := reflect.Indirect(reflect.ValueOf(records))
+	if rv.Kind() != reflect.Slice || recordType(rv.Type().Elem()) == nil {
+		return nil, ErrBulkRecords
+	}
+
+	name := proto.Name
+	if len(name) == 0 {
+		name = "row"
+	}
+	bulk := &Form{
+		Name:     proto.Name,
+		Action:   proto.Action,
+		Method:   proto.Method,
+		Defaults: proto.Defaults,
+		Security: proto.Security,
+	}
+	table := &Table{}
+	for _, field := range proto.Fields {
+		if _, ok := field.(*Hidden); ok || isNil(field) {
+			continue
+		}
+		label := labelOf(field)
+		if len(label) == 0 {
+			label = nameOf(field)
+		}
+		table.Headers = append(table.Headers, label)
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		row, err := copyForm(proto)
+		if err != nil {
+			return nil, err
+		}
+		row.Name = proto.Name
+		row.Prefix = name + "-" + strconv.Itoa(i) + "-"
+		row.PrefixFunc = nil
+		row.validators = proto.validators
+		for n, vv := range proto.validators {
+			bulk.AddValidator(row.prefixed(n), vv...)
+		}
+		if rec := reflect.Indirect(rv.Index(i)); rec.IsValid() {
+			row.setRecord(rec)
+		}
+		table.Fields = append(table.Fields, row)
+	}
+	bulk.Fields = []Field{table}
+	return bulk, nil
+}
+
+// recordType returns the struct type of a record, or nil.
+func recordType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// setRecord sets the fields of a row of a bulk edit form from a record.
+func (f *Form) setRecord(rec reflect.Value) {
+	vals := url.Values{}
+	bools := map[string]bool{}
+	for _, sf := range structFields(rec.Type()) {
+		field := f.Field(sf.name)
+		if field == nil {
+			continue
+		}
+		v := rec.FieldByIndex(sf.index)
+		if v.Kind() == reflect.Bool {
+			bools[sf.name] = v.Bool()
+		}
+		for _, s := range formatValue(v, timeLayout(field)) {
+			vals.Add(sf.name, s)
+		}
+	}
+	reconcileFields(f.allFields(), &vals, f)
+
+	// Checkboxes hold their own values, so bools check them directly.
+	walkFields(f.allFields(), func(field Field) {
+		if c, ok := field.(*Checkbox); ok {
+			if b, ok := bools[c.Name]; ok {
+				c.Checked = b
+			}
+		}
+	})
+}
+
+// DecodeBulk stores the rows of a submitted bulk edit form (see
+// NewBulkEdit) in dst, which must point to a slice of structs, or of
+// pointers to structs.
+//
+// Row i is stored in the slice's element i, if there is one, so that the
+// fields a form does not hold keep their values; otherwise a new element is
+// appended. Elements that are pointers are changed in place. Values are
+// stored as Bind stores them, except that an unchecked checkbox sets its
+// bool to false. If a value cannot be stored, a *BindError is returned.
+func DecodeBulk(f *Form, dst interface{}) error {
+	pv := reflect.ValueOf(dst)
+	if pv.Kind() != reflect.Ptr || pv.Elem().Kind() != reflect.Slice {
+		return ErrBulkRecords
+	}
+	sv := pv.Elem()
+	et := sv.Type().Elem()
+	rt := recordType(et)
+	if rt == nil {
+		return ErrBulkRecords
+	}
+
+	i := 0
+	var err error
+	walkFields(f.allFields(), func(field Field) {
+		t, ok := field.(*Table)
+		if !ok || err != nil {
+			return
+		}
+		for _, r := range t.Fields {
+			row, ok := r.(*Form)
+			if !ok || err != nil {
+				continue
+			}
+			if i >= sv.Len() {
+				sv.Set(reflect.Append(sv, reflect.Zero(et)))
+			}
+			rec := sv.Index(i)
+			if et.Kind() == reflect.Ptr {
+				if rec.IsNil() {
+					rec.Set(reflect.New(rt))
+				}
+				rec = rec.Elem()
+			}
+			err = bindStruct(row.recordValues(), rec)
+			i++
+		}
+	})
+	return err
+}
+
+// recordValues returns the values of a row of a bulk edit form, with
+// "false" for each unchecked checkbox.
+func (f *Form) recordValues() url.Values {
+	vals := *f.values()
+	walkFields(f.allFields(), func(field Field) {
+		if c, ok := field.(*Checkbox); ok && len(c.Name) > 0 && len(vals[c.Name]) == 0 {
+			vals.Set(c.Name, "false")
+		}
+	})
+	return vals
+}
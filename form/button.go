@@ -1,11 +1,85 @@
 package form
 
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Values for the Button Type attribute.
+const (
+	// ButtonSubmit submits the form. This is the default.
+	ButtonSubmit = "submit"
+	// ButtonReset resets the form.
+	ButtonReset = "reset"
+	// ButtonButton does nothing by default.
+	ButtonButton = "button"
+)
+
+// Button describes a button element.
+//
+// A button's content may be any list of fields, including String, which
+// allows icons and other markup to be placed inside of the button. If
+// Fields is empty, the Value is used as the button's content.
 type Button struct {
 	HTML
 	Autofocus, Disabled           bool
 	Form, Menu, Name, Type, Value string
+	Fields                        []Field
+
+	// FormNoValidate skips constraint validation when the form is submitted
+	// with this button.
+	FormNoValidate bool
 }
 
+// NewButton creates a new Button.
 func NewButton(name, val string) *Button {
 	return &Button{Name: name, Value: val}
 }
+
+// NewResetButton creates a new Button that resets the form.
+//
+// Reset buttons do not submit a value, so they have no name.
+func NewResetButton(content ...Field) *Button {
+	return &Button{Type: ButtonReset, Fields: content}
+}
+
+// Element retrieves the button as an html.Node of type ElementNode.
+//
+// A Type other than ButtonSubmit, ButtonReset, or ButtonButton is rendered
+// as ButtonSubmit, which is how user agents treat invalid types.
+func (b *Button) Element() *html.Node {
+	return b.RenderElement(nil)
+}
+
+// RenderElement retrieves the button, passing the context to its content.
+func (b *Button) RenderElement(ctx *RenderContext) *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Button,
+		Data:     "button",
+	}
+	if len(b.Type) > 0 {
+		n.Attr = attr(n.Attr, "type", buttonType(b.Type))
+	}
+	n.Attr = append(n.Attr, structToAttrs(b, "Form", "Menu", "Name", "Value")...)
+	n.Attr = append(n.Attr, boolAttrs(b, "Autofocus", "Disabled", "FormNoValidate")...)
+	b.HTML.Attach(n)
+
+	if len(b.Fields) > 0 {
+		appendElements(ctx, n, b.Fields)
+	} else if len(b.Value) > 0 {
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: b.Value})
+	}
+	return n
+}
+
+// buttonType normalizes a button type.
+func buttonType(t string) string {
+	switch t = strings.ToLower(t); t {
+	case ButtonSubmit, ButtonReset, ButtonButton:
+		return t
+	}
+	return ButtonSubmit
+}
@@ -0,0 +1,66 @@
+package cache
+
+import "time"
+
+// Batcher is implemented by caches that support batch operations natively.
+//
+// Backends that can pipeline requests (or, like the in-memory cache, take a
+// lock once for many records) should implement Batcher. Callers should use
+// the GetMulti, SetMulti, and RemoveMulti functions, which fall back to
+// individual operations for caches that do not implement it.
+type Batcher interface {
+	// GetMulti retrieves the values stored under ids. Missing and expired
+	// records are omitted from the result.
+	GetMulti(ids []string) (map[string]interface{}, error)
+	// SetMulti stores each value under its key until the given time.
+	SetMulti(vals map[string]interface{}, expires time.Time) error
+	// RemoveMulti deletes the values stored under ids.
+	RemoveMulti(ids []string) error
+}
+
+// GetMulti retrieves several values from a cache.
+//
+// Missing and expired records are omitted from the result, so only errors
+// other than ErrNotFound are returned.
+func GetMulti(c Cache, ids []string) (map[string]interface{}, error) {
+	if b, ok := c.(Batcher); ok {
+		return b.GetMulti(ids)
+	}
+	res := make(map[string]interface{}, len(ids))
+	for _, id := range ids {
+		v, err := c.Get(id)
+		if err == ErrNotFound {
+			continue
+		} else if err != nil {
+			return res, err
+		}
+		res[id] = v
+	}
+	return res, nil
+}
+
+// SetMulti stores several values in a cache.
+func SetMulti(c Cache, vals map[string]interface{}, expires time.Time) error {
+	if b, ok := c.(Batcher); ok {
+		return b.SetMulti(vals, expires)
+	}
+	for id, v := range vals {
+		if err := c.Set(id, v, expires); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveMulti deletes several values from a cache.
+func RemoveMulti(c Cache, ids []string) error {
+	if b, ok := c.(Batcher); ok {
+		return b.RemoveMulti(ids)
+	}
+	for _, id := range ids {
+		if err := c.Remove(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
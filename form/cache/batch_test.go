@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// simpleCache hides the Batcher implementation of the memory cache.
+type simpleCache struct {
+	Cache
+}
+
+func TestMulti(t *testing.T) {
+	for _, c := range []Cache{NewMemory(time.Minute), simpleCache{NewMemory(time.Minute)}} {
+		vals := map[string]interface{}{"a": 1, "b": 2, "c": 3}
+		if err := SetMulti(c, vals, time.Now().Add(time.Minute)); err != nil {
+			t.Errorf("Failed to set: %s", err)
+		}
+
+		got, err := GetMulti(c, []string{"a", "b", "missing"})
+		if err != nil {
+			t.Errorf("Failed to get: %s", err)
+		}
+		if len(got) != 2 || got["a"].(int) != 1 || got["b"].(int) != 2 {
+			t.Errorf("Unexpected values %v", got)
+		}
+
+		if err := RemoveMulti(c, []string{"a", "c"}); err != nil {
+			t.Errorf("Failed to remove: %s", err)
+		}
+		if got, _ := GetMulti(c, []string{"a", "b", "c"}); len(got) != 1 {
+			t.Errorf("Expected one remaining value, got %v", got)
+		}
+	}
+}
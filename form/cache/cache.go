@@ -0,0 +1,32 @@
+// Package cache provides storage for short-lived application state.
+//
+// The form package uses a Cache to store prepared forms between the time they
+// are rendered and the time they are submitted. Applications may use the same
+// Cache for related state (such as wizard data or drafts), instead of running
+// a second store.
+//
+// Cache implementations are required to handle expiration internally. An
+// expired record should behave exactly like a missing one.
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound indicates that a record is not in the cache.
+//
+// Expired records should also return this error.
+var ErrNotFound = errors.New("Not found")
+
+// Cache describes a store of values that expire.
+//
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get retrieves the value stored under id.
+	Get(id string) (interface{}, error)
+	// Set stores a value under id until the given time.
+	Set(id string, v interface{}, expires time.Time) error
+	// Remove deletes the value stored under id.
+	Remove(id string) error
+}
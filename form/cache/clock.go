@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time.
+//
+// Caches use a Clock to decide whether records have expired. Tests can
+// substitute a clock that they control with SetClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now calls fn.
+func (fn ClockFunc) Now() time.Time {
+	return fn()
+}
+
+// SystemClock is the clock of the operating system.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// Clocked is implemented by caches whose clock can be replaced.
+//
+// The caches in this package are Clocked, and use SystemClock by default.
+type Clocked interface {
+	SetClock(Clock)
+}
+
+// SetClock sets the clock of a cache, and returns false if the cache is not
+// Clocked.
+func SetClock(c Cache, clk Clock) bool {
+	if cc, ok := c.(Clocked); ok {
+		cc.SetClock(clk)
+		return true
+	}
+	return false
+}
+
+// clock holds a replaceable Clock. It is embedded by caches.
+type clock struct {
+	mx  sync.RWMutex
+	clk Clock
+}
+
+// SetClock implements Clocked.
+func (c *clock) SetClock(clk Clock) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.clk = clk
+}
+
+// Now returns the time of the clock, or of SystemClock if none is set.
+func (c *clock) Now() time.Time {
+	c.mx.RLock()
+	clk := c.clk
+	c.mx.RUnlock()
+	if clk == nil {
+		return SystemClock.Now()
+	}
+	return clk.Now()
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"encoding/gob"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileExt is the extension of files written by the file cache.
+const fileExt = ".gob"
+
+// fileEntry is the record stored in each file.
+type fileEntry struct {
+	Expires time.Time
+	Value   interface{}
+}
+
+// NewFile returns a new Cache that stores each value in a file under dir.
+//
+// This is useful for command line tools, tests, and single-binary
+// deployments that have no external services. The directory is created if
+// it does not exist.
+//
+// Values are encoded with encoding/gob, so their concrete types must be
+// registered with gob.Register. (The form package registers its own types.)
+// Writes are atomic: each value is written to a temporary file, which is
+// then renamed into place.
+//
+// Expired files are purged every interval (see NewSweeper). The returned
+// cache also implements io.Closer, which stops the sweeper, but leaves the
+// files in place.
+func NewFile(dir string, interval time.Duration) (Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	fc := &fileCache{dir: dir}
+	fc.sweeper = NewSweeper(fc, interval, 0)
+	return fc, nil
+}
+
+// fileCache stores values in files.
+type fileCache struct {
+	clock
+	dir     string
+	sweeper *Sweeper
+}
+
+// path returns the file name for an id.
+//
+// IDs are hex-encoded, so arbitrary IDs cannot escape the directory.
+func (c *fileCache) path(id string) string {
+	return filepath.Join(c.dir, hex.EncodeToString([]byte(id))+fileExt)
+}
+
+func (c *fileCache) read(p string) (*fileEntry, error) {
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	e := &fileEntry{}
+	if err := gob.NewDecoder(f).Decode(e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (c *fileCache) Get(id string) (interface{}, error) {
+	p := c.path(id)
+	e, err := c.read(p)
+	if err != nil {
+		return nil, err
+	}
+	if c.Now().After(e.Expires) {
+		os.Remove(p)
+		return nil, ErrNotFound
+	}
+	return e.Value, nil
+}
+
+func (c *fileCache) Set(id string, v interface{}, expires time.Time) error {
+	tmp, err := ioutil.TempFile(c.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	if err := gob.NewEncoder(tmp).Encode(&fileEntry{expires, v}); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(id))
+}
+
+func (c *fileCache) Remove(id string) error {
+	if err := os.Remove(c.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Sweep removes expired files.
+//
+// Files that cannot be decoded are left alone, since they may belong to
+// another application.
+func (c *fileCache) Sweep(now time.Time, limit int) (int, error) {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, fi := range files {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), fileExt) {
+			continue
+		}
+		p := filepath.Join(c.dir, fi.Name())
+		e, err := c.read(p)
+		if err != nil || !now.After(e.Expires) {
+			continue
+		}
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return n, err
+		}
+		n++
+		if limit > 0 && n == limit {
+			break
+		}
+	}
+	return n, nil
+}
+
+// Close stops the sweeper.
+func (c *fileCache) Close() error {
+	c.sweeper.Stop()
+	return nil
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "form-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := NewFile(dir, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %s", err)
+	}
+	defer c.(io.Closer).Close()
+
+	if err := c.Set("../draft", "hello", time.Now().Add(time.Minute)); err != nil {
+		t.Errorf("Failed to set cache: %s", err)
+	}
+	if v, err := c.Get("../draft"); err != nil || v.(string) != "hello" {
+		t.Errorf("Expected 'hello', got %v, %v", v, err)
+	}
+
+	c.Set("old", "expired", time.Now().Add(-time.Second))
+	if n, err := c.(Sweepable).Sweep(time.Now(), 0); err != nil || n != 1 {
+		t.Errorf("Expected one file swept, got %d, %v", n, err)
+	}
+	if _, err := c.Get("old"); err != ErrNotFound {
+		t.Errorf("Expected expired entry to be missing, got %v", err)
+	}
+
+	c.Remove("../draft")
+	if _, err := c.Get("../draft"); err != ErrNotFound {
+		t.Errorf("Expected entry to be removed, but it's here.")
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Expected no files to remain, found %d", len(files))
+	}
+}
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// SweepInterval is a suggested interval for sweeping the cache.
+//
+// It is not mandatory that caching backends use SweepInterval, but the
+// in-memory cache uses it to indicate how frequently it should purge the
+// cache of expired records.
+var SweepInterval = 5 * time.Minute
+
+// entry describes a value in the cache.
+//
+// exp indicates when the value is no longer valid, and can be cleaned up.
+type entry struct {
+	exp time.Time
+	val interface{}
+}
+
+// NewMemory returns a new Cache that stores values in memory.
+//
+// Expired values are purged every interval. If interval is <= 0,
+// SweepInterval is used.
+//
+// The returned cache also implements io.Closer. Closing it stops the
+// background sweeper and discards all values.
+func NewMemory(interval time.Duration) Cache {
+	mc := &memoryCache{
+		store: map[string]*entry{},
+	}
+	mc.sweeper = NewSweeper(mc, interval, 0)
+	return mc
+}
+
+// memoryCache stores values in memory.
+type memoryCache struct {
+	clock
+	mx      sync.RWMutex
+	store   map[string]*entry
+	sweeper *Sweeper
+}
+
+// Sweep runs a simple mark-and-sweep.
+func (m *memoryCache) Sweep(now time.Time, limit int) (int, error) {
+	mark := []string{}
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	for k, v := range m.store {
+		if now.After(v.exp) {
+			mark = append(mark, k)
+			if limit > 0 && len(mark) == limit {
+				break
+			}
+		}
+	}
+	for _, id := range mark {
+		delete(m.store, id)
+	}
+	return len(mark), nil
+}
+
+// Close stops the sweeper and discards all values.
+func (m *memoryCache) Close() error {
+	m.sweeper.Stop()
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.store = map[string]*entry{}
+	return nil
+}
+
+func (m *memoryCache) Get(id string) (interface{}, error) {
+	m.mx.RLock()
+	val, ok := m.store[id]
+	m.mx.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	// Expire an entry if necessary.
+	if m.Now().After(val.exp) {
+		m.Remove(id)
+		return nil, ErrNotFound
+	}
+	return val.val, nil
+}
+
+func (m *memoryCache) Set(id string, v interface{}, expires time.Time) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	m.store[id] = &entry{expires, v}
+	return nil
+}
+
+func (m *memoryCache) Remove(id string) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	delete(m.store, id)
+	return nil
+}
+
+func (m *memoryCache) GetMulti(ids []string) (map[string]interface{}, error) {
+	now := m.Now()
+	res := make(map[string]interface{}, len(ids))
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+	for _, id := range ids {
+		// Expired entries are left for the sweeper.
+		if val, ok := m.store[id]; ok && !now.After(val.exp) {
+			res[id] = val.val
+		}
+	}
+	return res, nil
+}
+
+func (m *memoryCache) SetMulti(vals map[string]interface{}, expires time.Time) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	for id, v := range vals {
+		m.store[id] = &entry{expires, v}
+	}
+	return nil
+}
+
+func (m *memoryCache) RemoveMulti(ids []string) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	for _, id := range ids {
+		delete(m.store, id)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	c := NewMemory(time.Minute)
+
+	if err := c.Set("draft", "hello", time.Now().Add(time.Minute)); err != nil {
+		t.Errorf("Failed to set cache: %s", err)
+	}
+	v, err := c.Get("draft")
+	if err != nil {
+		t.Errorf("Failed to get cached record: %s", err)
+	}
+	if v.(string) != "hello" {
+		t.Errorf("Expected 'hello', got %v", v)
+	}
+
+	c.Set("old", "expired", time.Now().Add(-time.Second))
+	if _, err := c.Get("old"); err != ErrNotFound {
+		t.Errorf("Expected expired entry to be missing, got %v", err)
+	}
+
+	c.Remove("draft")
+	if _, err := c.Get("draft"); err != ErrNotFound {
+		t.Errorf("Expected entry to be removed, but it's here.")
+	}
+}
+
+func TestClock(t *testing.T) {
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewMemory(time.Minute)
+	ns := Namespace(c, "site")
+	if !SetClock(ns, ClockFunc(func() time.Time { return now })) {
+		t.Fatal("Expected namespaced memory cache to be Clocked")
+	}
+
+	ns.Set("draft", "hello", now.Add(time.Minute))
+	if _, err := ns.Get("draft"); err != nil {
+		t.Errorf("Failed to get cached record: %s", err)
+	}
+	now = now.Add(time.Hour)
+	if _, err := ns.Get("draft"); err != ErrNotFound {
+		t.Errorf("Expected entry to expire, got %v", err)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	c := NewMemory(time.Minute)
+	a, b := Namespace(c, "a/b"), Namespace(c, "a")
+	exp := time.Now().Add(time.Minute)
+
+	a.Set("c", "one", exp)
+	b.Set("b/c", "two", exp)
+	if v, err := a.Get("c"); err != nil || v.(string) != "one" {
+		t.Errorf("Expected 'one', got %v, %v", v, err)
+	}
+	if v, err := b.Get("b/c"); err != nil || v.(string) != "two" {
+		t.Errorf("Expected 'two', got %v, %v", v, err)
+	}
+	if _, err := b.Get("c"); err != ErrNotFound {
+		t.Errorf("Expected namespaces to be isolated, got %v", err)
+	}
+	if _, err := c.Get("c"); err != ErrNotFound {
+		t.Errorf("Expected namespaced record to be hidden, got %v", err)
+	}
+	if _, err := Namespace(c, "").Get("a/b/c"); err != ErrNotFound {
+		t.Errorf("Expected the empty namespace to be isolated, got %v", err)
+	}
+}
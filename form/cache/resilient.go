@@ -0,0 +1,223 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen indicates that a Resilient cache's circuit breaker is
+// open, so the backend was not called.
+var ErrCircuitOpen = errors.New("Cache circuit breaker is open")
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState uint8
+
+const (
+	// Closed breakers pass calls to the backend.
+	Closed BreakerState = iota
+	// Open breakers fail calls without passing them to the backend.
+	Open
+	// HalfOpen breakers pass one trial call to the backend, to find out
+	// whether it has recovered.
+	HalfOpen
+)
+
+var breakerStateNames = []string{"closed", "open", "half-open"}
+
+func (s BreakerState) String() string {
+	if int(s) < len(breakerStateNames) {
+		return breakerStateNames[s]
+	}
+	return fmt.Sprintf("BreakerState(%d)", s)
+}
+
+// ResilientOptions configure a Resilient cache. Zero values are replaced
+// by the defaults given.
+type ResilientOptions struct {
+	// Retries is the number of times a failed call is retried. The default
+	// is 2; use a negative number for no retries.
+	Retries int
+	// Backoff is the longest wait before the first retry; each later retry
+	// may wait twice as long as the one before. Waits are random up to the
+	// limit, so that clients do not retry in step. The default is 10ms.
+	Backoff time.Duration
+	// Threshold is the number of failed calls in a row that open the
+	// breaker. The default is 5.
+	Threshold int
+	// Cooldown is how long the breaker stays open before it lets a trial
+	// call through. The default is 30s.
+	Cooldown time.Duration
+	// OnStateChange, if set, is called when the breaker changes state, for
+	// example to update a metrics gauge. It must not call the cache.
+	OnStateChange func(from, to BreakerState)
+}
+
+// BreakerStats counts the calls of a Resilient cache, for metrics.
+type BreakerStats struct {
+	State BreakerState
+	// Calls is the number of calls made to the cache; Failures, the number
+	// that failed after their retries; Retries, the number of retries; and
+	// Rejected, the number that failed because the breaker was open.
+	Calls, Failures, Retries, Rejected uint64
+}
+
+// Resilient returns a Cache that retries the failed calls of c, and stops
+// calling it for a while when it keeps failing.
+//
+// A call that fails is retried with jittered, exponential backoff. After
+// Threshold calls in a row have failed, the circuit breaker opens, and
+// calls fail at once with ErrCircuitOpen, so that a broken backend does not
+// slow every request down. After the Cooldown, one call is let through; if
+// it succeeds, the breaker closes again. ErrNotFound is an answer, not a
+// failure, so it is neither retried nor counted.
+func Resilient(c Cache, opts ResilientOptions) *ResilientCache {
+	if opts.Retries == 0 {
+		opts.Retries = 2
+	} else if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 10 * time.Millisecond
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = 5
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = 30 * time.Second
+	}
+	return &ResilientCache{c: c, opts: opts, sleep: time.Sleep}
+}
+
+// ResilientCache is a Cache with retries and a circuit breaker. See
+// Resilient.
+type ResilientCache struct {
+	clock
+	c     Cache
+	opts  ResilientOptions
+	sleep func(time.Duration)
+
+	mx       sync.Mutex
+	state    BreakerState
+	failures int
+	opened   time.Time
+	trial    bool
+	stats    BreakerStats
+}
+
+// SetClock sets the clock used for the cooldown, and the clock of the
+// underlying cache, if it is Clocked.
+func (r *ResilientCache) SetClock(clk Clock) {
+	r.clock.SetClock(clk)
+	SetClock(r.c, clk)
+}
+
+// State returns the state of the circuit breaker.
+func (r *ResilientCache) State() BreakerState {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.state
+}
+
+// Stats returns the cache's counts and the state of its breaker.
+func (r *ResilientCache) Stats() BreakerStats {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	s := r.stats
+	s.State = r.state
+	return s
+}
+
+func (r *ResilientCache) Get(id string) (interface{}, error) {
+	var v interface{}
+	err := r.do(func() (err error) {
+		v, err = r.c.Get(id)
+		return err
+	})
+	return v, err
+}
+
+func (r *ResilientCache) Set(id string, v interface{}, expires time.Time) error {
+	return r.do(func() error {
+		return r.c.Set(id, v, expires)
+	})
+}
+
+func (r *ResilientCache) Remove(id string) error {
+	return r.do(func() error {
+		return r.c.Remove(id)
+	})
+}
+
+// do makes a call through the breaker, with retries.
+func (r *ResilientCache) do(call func() error) error {
+	if !r.admit() {
+		return ErrCircuitOpen
+	}
+	err := call()
+	for i := 0; i < r.opts.Retries && err != nil && err != ErrNotFound; i++ {
+		r.mx.Lock()
+		r.stats.Retries++
+		r.mx.Unlock()
+		r.sleep(time.Duration(rand.Int63n(int64(r.opts.Backoff) << uint(i))))
+		err = call()
+	}
+	r.record(err == nil || err == ErrNotFound)
+	return err
+}
+
+// admit reports whether a call may be made, and counts it.
+func (r *ResilientCache) admit() bool {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.stats.Calls++
+	switch r.state {
+	case Open:
+		if r.Now().Sub(r.opened) < r.opts.Cooldown {
+			r.stats.Rejected++
+			return false
+		}
+		r.setState(HalfOpen)
+		r.trial = true
+		return true
+	case HalfOpen:
+		if r.trial {
+			r.stats.Rejected++
+			return false
+		}
+		r.trial = true
+	}
+	return true
+}
+
+// record updates the breaker with the outcome of a call.
+func (r *ResilientCache) record(ok bool) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.trial = false
+	if ok {
+		r.failures = 0
+		r.setState(Closed)
+		return
+	}
+	r.stats.Failures++
+	r.failures++
+	if r.state == HalfOpen || r.failures >= r.opts.Threshold {
+		r.opened = r.Now()
+		r.setState(Open)
+	}
+}
+
+// setState changes the state of the breaker. The lock must be held.
+func (r *ResilientCache) setState(to BreakerState) {
+	from := r.state
+	if from == to {
+		return
+	}
+	r.state = to
+	if r.opts.OnStateChange != nil {
+		r.opts.OnStateChange(from, to)
+	}
+}
@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flaky is a cache that fails while down is set.
+type flaky struct {
+	Cache
+	down  bool
+	calls int
+}
+
+var errFlaky = errors.New("backend is down")
+
+func (f *flaky) Get(id string) (interface{}, error) {
+	f.calls++
+	if f.down {
+		return nil, errFlaky
+	}
+	return f.Cache.Get(id)
+}
+
+func TestResilient(t *testing.T) {
+	now := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	backend := &flaky{Cache: NewMemory(0)}
+	changes := []string{}
+	c := Resilient(backend, ResilientOptions{
+		Threshold: 2,
+		Cooldown:  time.Minute,
+		OnStateChange: func(from, to BreakerState) {
+			changes = append(changes, from.String()+">"+to.String())
+		},
+	})
+	c.sleep = func(time.Duration) {}
+	c.SetClock(ClockFunc(func() time.Time { return now }))
+
+	if _, err := c.Get("missing"); err != ErrNotFound || backend.calls != 1 {
+		t.Errorf("Expected ErrNotFound without retries, got %v after %d calls", err, backend.calls)
+	}
+
+	backend.down, backend.calls = true, 0
+	for i := 0; i < 2; i++ {
+		if _, err := c.Get("a"); err != errFlaky {
+			t.Errorf("Expected the backend's error, got %v", err)
+		}
+	}
+	if backend.calls != 6 {
+		t.Errorf("Expected each call to be retried twice, got %d calls", backend.calls)
+	}
+	if c.State() != Open {
+		t.Fatalf("Expected the breaker to open, got %s", c.State())
+	}
+	if _, err := c.Get("a"); err != ErrCircuitOpen || backend.calls != 6 {
+		t.Errorf("Expected ErrCircuitOpen without a call, got %v", err)
+	}
+
+	// A failed trial opens the breaker again.
+	now = now.Add(time.Minute)
+	c.Get("a")
+	if c.State() != Open {
+		t.Errorf("Expected a failed trial to reopen the breaker, got %s", c.State())
+	}
+
+	now = now.Add(time.Minute)
+	backend.down = false
+	if _, err := c.Get("missing"); err != ErrNotFound || c.State() != Closed {
+		t.Errorf("Expected a good trial to close the breaker, got %v, %s", err, c.State())
+	}
+
+	expect := "closed>open open>half-open half-open>open open>half-open half-open>closed"
+	if got := strings.Join(changes, " "); got != expect {
+		t.Errorf("Expected state changes %q, got %q", expect, got)
+	}
+	s := c.Stats()
+	if s.Calls != 6 || s.Failures != 3 || s.Retries != 6 || s.Rejected != 1 || s.State != Closed {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Sweepable is implemented by caches that must purge expired records themselves.
+//
+// Backends with native expiration (Redis, memcached) do not need this.
+// Backends without it (in-memory, SQL, files) should implement Sweepable
+// and run a Sweeper.
+type Sweepable interface {
+	// Sweep removes up to limit records that expired before now, and
+	// returns the number removed. If limit is <= 0, there is no limit.
+	Sweep(now time.Time, limit int) (int, error)
+}
+
+// Sweeper periodically removes expired records from a cache.
+//
+// Each interval, the sweeper calls Sweep with the batch size until a batch
+// comes back less than full, so large backlogs are cleared in bounded
+// steps. If the cache is also a Clock, its time is passed to Sweep.
+type Sweeper struct {
+	mx   sync.Mutex
+	err  error
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewSweeper starts sweeping a cache every interval.
+//
+// If interval is <= 0, SweepInterval is used. If batch is <= 0, each sweep
+// removes all expired records at once.
+func NewSweeper(c Sweepable, interval time.Duration, batch int) *Sweeper {
+	if interval <= 0 {
+		interval = SweepInterval
+	}
+	s := &Sweeper{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go s.run(c, interval, batch)
+	return s
+}
+
+func (s *Sweeper) run(c Sweepable, interval time.Duration, batch int) {
+	defer close(s.done)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-t.C:
+			if clk, ok := c.(Clock); ok {
+				now = clk.Now()
+			}
+			s.sweep(c, now, batch)
+		}
+	}
+}
+
+func (s *Sweeper) sweep(c Sweepable, now time.Time, batch int) {
+	for {
+		n, err := c.Sweep(now, batch)
+		if err != nil {
+			s.mx.Lock()
+			s.err = err
+			s.mx.Unlock()
+			return
+		}
+		if batch <= 0 || n < batch {
+			return
+		}
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+	}
+}
+
+// Err returns the last error returned by the cache's Sweep method.
+func (s *Sweeper) Err() error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.err
+}
+
+// Stop stops the sweeper, and waits for a sweep in progress to finish.
+//
+// It is safe to call Stop more than once.
+func (s *Sweeper) Stop() {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+}
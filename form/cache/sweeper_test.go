@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+type countingSweeper struct {
+	calls chan int
+}
+
+func (c *countingSweeper) Sweep(now time.Time, limit int) (int, error) {
+	c.calls <- limit
+	return 0, nil
+}
+
+func TestSweeper(t *testing.T) {
+	c := &countingSweeper{calls: make(chan int, 10)}
+	s := NewSweeper(c, time.Millisecond, 50)
+
+	select {
+	case limit := <-c.calls:
+		if limit != 50 {
+			t.Errorf("Expected batch size 50, got %d", limit)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Sweeper never swept.")
+	}
+	s.Stop()
+	s.Stop()
+}
+
+func TestMemorySweep(t *testing.T) {
+	c := NewMemory(time.Minute)
+	defer c.(io.Closer).Close()
+
+	c.Set("a", 1, time.Now().Add(-time.Second))
+	c.Set("b", 2, time.Now().Add(-time.Second))
+	c.Set("c", 3, time.Now().Add(time.Minute))
+
+	n, err := c.(Sweepable).Sweep(time.Now(), 1)
+	if err != nil || n != 1 {
+		t.Errorf("Expected one record swept, got %d, %v", n, err)
+	}
+	if n, _ := c.(Sweepable).Sweep(time.Now(), 0); n != 1 {
+		t.Errorf("Expected one more record swept, got %d", n)
+	}
+	if _, err := c.Get("c"); err != nil {
+		t.Errorf("Expected unexpired record to remain: %s", err)
+	}
+}
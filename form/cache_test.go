@@ -1,8 +1,15 @@
 package form
 
 import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
 	"testing"
 	"time"
+
+	"github.com/Masterminds/engine/form/cache"
 )
 
 func TestCache(t *testing.T) {
@@ -30,3 +37,125 @@ func TestCache(t *testing.T) {
 	}
 
 }
+
+func TestFromCache(t *testing.T) {
+	shared := cache.NewMemory(time.Minute)
+	c := FromCache(shared)
+
+	shared.Set("draft", "not a form", time.Now().Add(time.Minute))
+	if _, err := c.Get("draft"); err != ErrFormNotFound {
+		t.Errorf("Expected ErrFormNotFound for a non-form record, got %v", err)
+	}
+
+	f := New("test", "test")
+	c.Set("form", f, time.Now().Add(time.Minute))
+	if v, err := shared.Get("form"); err != nil || v.(*Form) != f {
+		t.Errorf("Expected form in the shared cache, got %v, %v", v, err)
+	}
+}
+
+func TestFileCacheForms(t *testing.T) {
+	dir, err := ioutil.TempDir("", "form-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fc, err := cache.NewFile(dir, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %s", err)
+	}
+	defer fc.(io.Closer).Close()
+
+	fh := NewFormHandler(FromCache(fc), time.Minute)
+	f := New("test", "test")
+	f.Fields = []Field{
+		&Div{Fields: []Field{&Text{Name: "t"}}},
+		&Select{Name: "s", Options: []OptionItem{&Option{Value: "a"}}},
+	}
+
+	id, err := fh.Prepare(f)
+	if err != nil {
+		t.Fatalf("Failed to prepare form: %s", err)
+	}
+	ff, err := fh.Retrieve(&url.Values{"t": []string{"hello"}, SecureTokenName: []string{id}})
+	if err != nil {
+		t.Fatalf("Failed to retrieve form: %s", err)
+	}
+	if v := ff.Fields[0].(*Div).Fields[0].(*Text).Value; v != "hello" {
+		t.Errorf("Expected 'hello', got %q", v)
+	}
+	if ff.State() != Submitted {
+		t.Errorf("Expected submitted state, got %s", ff.State())
+	}
+}
+
+func TestDefinitions(t *testing.T) {
+	loads := 0
+	d := NewDefinitions(LocalFetcher(func(name string) (*Form, error) {
+		loads++
+		if name != "contact" {
+			return nil, ErrFormNotFound
+		}
+		f := New(name, "/contact")
+		f.Add(&Text{Name: "email"})
+		return f, nil
+	}))
+
+	f1, err := d.Get(context.Background(), "contact")
+	if err != nil {
+		t.Fatalf("Failed to get definition: %s", err)
+	}
+	f1.Fields[0].(*Text).Value = "changed"
+
+	f2, err := d.Get(context.Background(), "contact")
+	if err != nil {
+		t.Fatalf("Failed to get definition: %s", err)
+	}
+	if f2.Action != "/contact" || f2.Fields[0].(*Text).Value != "" {
+		t.Errorf("Expected an unmodified copy, got %+v", f2.Fields[0])
+	}
+	if loads != 1 {
+		t.Errorf("Expected one load, got %d", loads)
+	}
+
+	if _, err := d.Get(context.Background(), "missing"); err != ErrFormNotFound {
+		t.Errorf("Expected ErrFormNotFound, got %v", err)
+	}
+}
+
+func TestEncryptSensitive(t *testing.T) {
+	if _, err := EncryptSensitive(NewCache(), []byte("short")); err == nil {
+		t.Errorf("Expected an error for an invalid key.")
+	}
+
+	inner := NewCache()
+	c, err := EncryptSensitive(inner, []byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Failed to create cache: %s", err)
+	}
+	f := New("test", "test")
+	f.Sensitive = []string{"ssn"}
+	f.Add(&Text{Name: "ssn", Value: "123-45-6789"}, &Text{Name: "name", Value: "Matt"})
+
+	c.Set("id", f, time.Now().Add(time.Minute))
+	if v := f.Field("ssn").(*Text).Value; v != "123-45-6789" {
+		t.Errorf("Expected original form to be unchanged, got %q", v)
+	}
+
+	raw, _ := inner.Get("id")
+	if v := raw.Field("ssn").(*Text).Value; v == "123-45-6789" || v == "" {
+		t.Errorf("Expected cached value to be encrypted, got %q", v)
+	}
+	if v := raw.Field("name").(*Text).Value; v != "Matt" {
+		t.Errorf("Expected non-sensitive value to be plain, got %q", v)
+	}
+
+	ff, err := c.Get("id")
+	if err != nil {
+		t.Fatalf("Failed to get form: %s", err)
+	}
+	if v := ff.Field("ssn").(*Text).Value; v != "123-45-6789" {
+		t.Errorf("Expected decrypted value, got %q", v)
+	}
+}
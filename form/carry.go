@@ -0,0 +1,86 @@
+package form
+
+import (
+	"net/url"
+)
+
+// CarryName is the name of the hidden field that carries query parameters
+// through a form's submission. See CarryQuery.
+var CarryName = "__query__"
+
+// CarryQuery carries the named parameters of a query through the form's
+// submission, so that a handler can return the user to the page they came
+// from.
+//
+// A form on a paginated or filtered listing is usually posted to a handler
+// that redirects back to the listing, which should show the same page with
+// the same filters. CarryQuery stores the named parameters of q (typically
+// the listing request's URL.Query()) in a hidden field, and ReturnURL adds
+// them to the redirect's URL. Only the named parameters are carried, so
+// that tracking and other parameters are left behind. Calling it again
+// replaces the parameters carried.
+//
+// The carried parameters are submitted with the form, so a user can change
+// their values, but they only change the query of the URL given to
+// ReturnURL, never its host or path. The names are kept with the form (and
+// cached with it), and parameters that were not named are dropped when the
+// submission is read, so a user cannot add parameters of their own.
+func (f *Form) CarryQuery(q url.Values, names ...string) *Form {
+	f.carried = append([]string(nil), names...)
+	carried := url.Values{}
+	for _, name := range names {
+		if vv, ok := q[name]; ok {
+			carried[name] = vv
+		}
+	}
+	if h, ok := f.Field(CarryName).(*Hidden); ok {
+		h.Value = carried.Encode()
+		return f
+	}
+	f.Fields = append(f.Fields, &Hidden{Name: CarryName, Value: carried.Encode()})
+	return f
+}
+
+// CarriedQuery returns the query parameters carried by the form, limited
+// to those named by CarryQuery. A form on which CarryQuery was not called
+// carries none.
+func (f *Form) CarriedQuery() url.Values {
+	allowed := url.Values{}
+	h, ok := f.Field(CarryName).(*Hidden)
+	if !ok {
+		return allowed
+	}
+	q, err := url.ParseQuery(h.Value)
+	if err != nil {
+		return allowed
+	}
+	for _, name := range f.carried {
+		if vv, ok := q[name]; ok {
+			allowed[name] = vv
+		}
+	}
+	return allowed
+}
+
+// ReturnURL adds the query parameters carried by the form (see CarryQuery)
+// to target, replacing any parameters of the same names, for example:
+//
+//	http.Redirect(w, r, f.ReturnURL("/items"), http.StatusSeeOther)
+//
+// If the target cannot be parsed, it is returned as it is.
+func (f *Form) ReturnURL(target string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	carried := f.CarriedQuery()
+	if len(carried) == 0 {
+		return target
+	}
+	q := u.Query()
+	for name, vv := range carried {
+		q[name] = vv
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,84 @@
+package form
+
+import (
+	"context"
+	"sort"
+)
+
+// ChangeFunc is called when a submission changes the value of a field,
+// with the field's name and its values before and after the submission.
+type ChangeFunc func(ctx context.Context, name string, old, new []string) error
+
+// OnChange registers functions to be called by DispatchChanges when the
+// named field is changed by a submission.
+//
+// As with validators, names are not prefixed, and the fields of embedded
+// forms are named with the embedded form's Prefix. Like validators, change
+// functions are not stored by caches that serialize forms, so they should
+// be registered on the retrieved form when such a cache is used.
+func (f *Form) OnChange(name string, fn ...ChangeFunc) *Form {
+	if f.onChange == nil {
+		f.onChange = map[string][]ChangeFunc{}
+	}
+	f.onChange[name] = append(f.onChange[name], fn...)
+	return f
+}
+
+// Changed returns the sorted names of the fields whose values were changed
+// by reconciliation, such as fields that were edited in a submitted form.
+//
+// The values are compared with those the form had before it was first
+// reconciled. A form that has not been reconciled has no changes.
+func (f *Form) Changed() []string {
+	if f.initial == nil {
+		return nil
+	}
+	before, after := *f.initial, *f.values()
+	names := []string{}
+	for name, vv := range after {
+		if !equalValues(before[name], vv) {
+			names = append(names, name)
+		}
+	}
+	for name, vv := range before {
+		if _, ok := after[name]; !ok && len(vv) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DispatchChanges calls the functions registered with OnChange for each
+// changed field (see Changed), in the order of the fields' names, so that
+// audit logs and updates only deal with what the user changed.
+//
+// Every function is called, even if one fails; the first error is
+// returned.
+func (f *Form) DispatchChanges(ctx context.Context) error {
+	if len(f.onChange) == 0 {
+		return nil
+	}
+	before, after := f.initial, f.values()
+	var first error
+	for _, name := range f.Changed() {
+		for _, fn := range f.onChange[name] {
+			if err := fn(ctx, name, (*before)[name], (*after)[name]); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
@@ -0,0 +1,63 @@
+package form
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Common values for Form.AcceptCharset.
+//
+// New applications should only accept UTF-8.
+const (
+	CharsetUTF8        = "UTF-8"
+	CharsetUSASCII     = "US-ASCII"
+	CharsetISO88591    = "ISO-8859-1"
+	CharsetISO885915   = "ISO-8859-15"
+	CharsetWindows1252 = "windows-1252"
+	CharsetShiftJIS    = "Shift_JIS"
+	CharsetEUCJP       = "EUC-JP"
+	CharsetEUCKR       = "EUC-KR"
+	CharsetGBK         = "GBK"
+	CharsetGB18030     = "GB18030"
+	CharsetBig5        = "Big5"
+)
+
+// KnownCharsets is the set of charsets accepted by CheckAcceptCharset.
+//
+// Keys are lower case. Applications that need another charset may add it.
+var KnownCharsets = map[string]bool{}
+
+func init() {
+	for _, c := range []string{
+		CharsetUTF8, CharsetUSASCII, CharsetISO88591, CharsetISO885915,
+		CharsetWindows1252, CharsetShiftJIS, CharsetEUCJP, CharsetEUCKR,
+		CharsetGBK, CharsetGB18030, CharsetBig5,
+	} {
+		KnownCharsets[strings.ToLower(c)] = true
+	}
+}
+
+// CharsetError indicates an unknown charset in a form's AcceptCharset.
+type CharsetError struct {
+	Charset string
+}
+
+func (e *CharsetError) Error() string {
+	return fmt.Sprintf("Unknown charset %q", e.Charset)
+}
+
+// CheckAcceptCharset verifies that each of the form's AcceptCharset values
+// is in KnownCharsets.
+//
+// A misspelled charset is ignored by the user agent, which then submits the
+// form in the page's encoding. That usually goes unnoticed until the
+// submitted data is garbled, so FormHandler.Prepare calls this, and returns
+// a *CharsetError for the first unknown charset.
+func (f *Form) CheckAcceptCharset() error {
+	for _, c := range f.AcceptCharset {
+		if !KnownCharsets[strings.ToLower(c)] {
+			return &CharsetError{c}
+		}
+	}
+	return nil
+}
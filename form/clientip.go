@@ -0,0 +1,138 @@
+package form
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// IPResolver finds the address of the client that made a request.
+//
+// The RemoteAddr of a request is the address of the last hop, which is a
+// proxy or load balancer in most deployments. Proxies add the address they
+// received a request from to the Forwarded or X-Forwarded-For header, but
+// clients can write those headers too, so they can only be believed as far
+// as they were written by proxies that are trusted.
+//
+// The resolver walks the chain of addresses from the last hop back towards
+// the client, and stops at the first address that is not a trusted proxy.
+// The Forwarded header (RFC 7239) is used if it is present; otherwise,
+// X-Forwarded-For is. A resolver with no TrustedProxies always returns the
+// RemoteAddr.
+type IPResolver struct {
+	// TrustedProxies lists the networks of proxies whose forwarding headers
+	// are believed.
+	TrustedProxies []*net.IPNet
+}
+
+// NewIPResolver creates an IPResolver that trusts the proxies in the given
+// networks, in CIDR notation such as "10.0.0.0/8". A single address, such as
+// "127.0.0.1", is also accepted.
+func NewIPResolver(cidrs ...string) (*IPResolver, error) {
+	r := &IPResolver{}
+	for _, c := range cidrs {
+		if !strings.Contains(c, "/") {
+			if ip := net.ParseIP(c); ip != nil && ip.To4() != nil {
+				c += "/32"
+			} else {
+				c += "/128"
+			}
+		}
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, err
+		}
+		r.TrustedProxies = append(r.TrustedProxies, n)
+	}
+	return r, nil
+}
+
+func (r *IPResolver) trusted(ip net.IP) bool {
+	for _, n := range r.TrustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client that made the request, or nil
+// if the RemoteAddr is not an IP address.
+func (r *IPResolver) ClientIP(req *http.Request) net.IP {
+	ip := parseHop(req.RemoteAddr)
+	if ip == nil || !r.trusted(ip) {
+		return ip
+	}
+
+	var hops []string
+	if fwd := req.Header.Values("Forwarded"); len(fwd) > 0 {
+		hops = forwardedFor(fwd)
+	} else {
+		for _, h := range req.Header.Values("X-Forwarded-For") {
+			hops = append(hops, strings.Split(h, ",")...)
+		}
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseHop(hops[i])
+		if hop == nil {
+			// An unknown or obfuscated hop; the last known one is the
+			// best that can be done.
+			break
+		}
+		ip = hop
+		if !r.trusted(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// forwardedFor returns the "for" parameters of Forwarded headers, in order.
+func forwardedFor(headers []string) []string {
+	hops := []string{}
+	for _, h := range headers {
+		for _, elem := range strings.Split(h, ",") {
+			hop := ""
+			for _, pair := range strings.Split(elem, ";") {
+				kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+				if len(kv) == 2 && strings.EqualFold(kv[0], "for") {
+					hop = strings.Trim(kv[1], `"`)
+				}
+			}
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// parseHop parses an address from a forwarding header or RemoteAddr, with
+// or without a port, and with or without brackets around an IPv6 address.
+func parseHop(s string) net.IP {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	return net.ParseIP(strings.Trim(s, "[]"))
+}
+
+type clientIPKey struct{}
+
+// WithClientIP returns a copy of the context carrying the client's address.
+func WithClientIP(ctx context.Context, ip net.IP) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP returns the client's address carried by the context, or nil.
+func ClientIP(ctx context.Context) net.IP {
+	ip, _ := ctx.Value(clientIPKey{}).(net.IP)
+	return ip
+}
+
+// Handler returns a handler that resolves the client's address, and passes
+// it to h in the request's context (see ClientIP).
+func (r *IPResolver) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h.ServeHTTP(w, req.WithContext(WithClientIP(req.Context(), r.ClientIP(req))))
+	})
+}
@@ -0,0 +1,90 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ErrInvalidColor indicates a color value that is not in hexadecimal form.
+var ErrInvalidColor = errors.New("Invalid color")
+
+// AlphaColor provides a color field with an alpha channel.
+//
+// The native color input cannot express transparency, so this is rendered
+// as a text input that accepts #rrggbbaa values.
+type AlphaColor Input
+
+// RGBA is a parsed color.
+type RGBA struct {
+	R, G, B, A uint8
+}
+
+// String returns the color as #rrggbb if it is opaque, or else as #rrggbbaa.
+func (c RGBA) String() string {
+	if c.A == 0xff {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// ParseColor parses a #rrggbb or #rrggbbaa color.
+//
+// Colors without an alpha channel are opaque.
+func ParseColor(s string) (RGBA, error) {
+	if !strings.HasPrefix(s, "#") || (len(s) != 7 && len(s) != 9) {
+		return RGBA{}, ErrInvalidColor
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return RGBA{}, ErrInvalidColor
+	}
+	if len(s) == 7 {
+		v = v<<8 | 0xff
+	}
+	return RGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, nil
+}
+
+// RGBA parses the field's Value, which must be a #rrggbb color.
+func (i *Color) RGBA() (RGBA, error) {
+	if len(i.Value) != 7 {
+		return RGBA{}, ErrInvalidColor
+	}
+	return ParseColor(i.Value)
+}
+
+// RGBA parses the field's Value, which may be a #rrggbb or #rrggbbaa color.
+func (i *AlphaColor) RGBA() (RGBA, error) {
+	return ParseColor(i.Value)
+}
+
+// alphaColorPattern matches the values of an AlphaColor.
+const alphaColorPattern = `#[0-9a-fA-F]{6}([0-9a-fA-F]{2})?`
+
+// Element retrieves the field as an html.Node of type ElementNode.
+//
+// If the field has no Pattern, one is added to restrict it to colors.
+func (i *AlphaColor) Element() *html.Node {
+	in := *(*Input)(i)
+	if len(in.Pattern) == 0 {
+		in.Pattern = alphaColorPattern
+	}
+	return inputElement("text", &in)
+}
+
+// reconcileColor validates and normalizes a submitted color.
+//
+// Valid colors are returned in lower case, as user agents submit them.
+func reconcileColor(val string, alpha bool) (string, error) {
+	c, err := ParseColor(val)
+	if err != nil || (!alpha && len(val) != 7) {
+		return "", ErrInvalidColor
+	}
+	if !alpha {
+		return c.String(), nil
+	}
+	return strings.ToLower(val), nil
+}
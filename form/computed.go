@@ -0,0 +1,80 @@
+package form
+
+import (
+	"net/url"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Computed describes a field whose value is derived from other fields.
+//
+// The Compute function is passed the form's current values (see
+// Form.AsValues, though the form's Prefix is not applied) and returns the
+// value of the field. Computed values are
+// calculated when a form is prepared, and recalculated when submitted
+// data is reconciled. Since the value is always recomputed on the server,
+// a client cannot forge it.
+//
+// By default, a Computed field is rendered as an output element. If
+// ReadOnly is true, it is rendered as a read-only text input instead,
+// which means the user agent will submit it along with the form.
+type Computed struct {
+	HTML
+	// For is a space-separated list of the IDs of the contributing elements.
+	For, Form, Name string
+	ReadOnly        bool
+	Value           string
+	Compute         func(*url.Values) string
+
+	// Label is not an attribute, but is used to label the field.
+	Label string
+}
+
+// Element retrieves the computed field as an html.Node of type ElementNode.
+func (c *Computed) Element() *html.Node {
+	if !c.ReadOnly {
+		o := &Output{HTML: c.HTML, For: c.For, Form: c.Form, Name: c.Name, Value: c.Value}
+		return o.Element()
+	}
+
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Input,
+		Data:     "input",
+	}
+	n.Attr = attr(n.Attr, "type", "text")
+	n.Attr = append(n.Attr, structToAttrs(c, "Form", "Name", "Value")...)
+	n.Attr = attr(n.Attr, "readonly", "readonly")
+	c.HTML.Attach(n)
+	return n
+}
+
+// Compute calculates the values of all Computed fields on the form.
+//
+// Computed fields are evaluated in the order they appear in the form. Each
+// Compute function sees the values of the fields computed before it.
+func (f *Form) Compute() {
+	computeFields(f.allFields(), f)
+}
+
+func computeFields(fields []Field, f *Form) {
+	for _, field := range fields {
+		switch field := field.(type) {
+		case *Div:
+			computeFields(field.Fields, f)
+		case *FieldSet:
+			computeFields(field.Fields, f)
+		case *Table:
+			computeFields(field.Fields, f)
+		case *Lazy:
+			computeFields(field.Resolve(), f)
+		case *Form:
+			field.Compute()
+		case *Computed:
+			if field.Compute != nil {
+				field.Value = field.Compute(f.values())
+			}
+		}
+	}
+}
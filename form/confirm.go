@@ -0,0 +1,97 @@
+package form
+
+import (
+	"context"
+	"net/http"
+)
+
+// ConfirmName is the name of the hidden field that marks the preview of a
+// form whose submission is waiting to be confirmed. See ConfirmHandler.
+var ConfirmName = "__confirm__"
+
+// PreviewFunc writes the page that asks a user to confirm a submission.
+//
+// The preview is a prepared, read-only copy of the submitted form (see
+// ConfirmHandler). It is usually rendered along with a description of what
+// will happen and a link to cancel.
+type PreviewFunc func(w http.ResponseWriter, r *http.Request, preview *Form)
+
+// ConfirmHandler returns a handler that asks for confirmation ("Are you
+// sure?") before carrying out the submission of a destructive form, such
+// as one that deletes records.
+//
+// The form is retrieved with RetrieveRequest and validated. If it is
+// valid, it is not carried out; instead, a preview is prepared with its
+// own token and passed to preview. The preview holds the submitted values,
+// and its fields, hidden fields included, are disabled, so they are not
+// reconciled and cannot be changed; its buttons are not, so the user can
+// submit it to confirm. When the preview is submitted, execute is called
+// with it, holding exactly the values that were previewed. A submission
+// that is invalid, or that comes with an error, is passed straight to
+// execute with its error, so that it can be shown again.
+//
+// Each step uses up its token, so a confirmation cannot be replayed, and a
+// submission cannot skip the preview, since only the preview's token is
+// accepted as a confirmation.
+func (h *FormHandler) ConfirmHandler(preview PreviewFunc, execute SubmitFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler := h.WithContext(r.Context())
+		form, err := handler.RetrieveRequest(r)
+		if form == nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		if form.Field(ConfirmName) != nil {
+			execute(w, r, form, err)
+			return
+		}
+		if err == nil {
+			err = form.Validate(r.Context())
+		}
+		if err != nil {
+			execute(w, r, form, err)
+			return
+		}
+
+		p, err := handler.preview(r.Context(), form)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		preview(w, r, p)
+	})
+}
+
+// preview prepares a read-only copy of a submitted form, marked to be
+// confirmed.
+func (h *FormHandler) preview(ctx context.Context, form *Form) (*Form, error) {
+	p, err := copyForm(form)
+	if err != nil {
+		return nil, err
+	}
+	p.state = Built
+	p.token = ""
+	p.validators = form.validators
+	var removed Field
+	p.Fields = removeField(p.Fields, SecureTokenName, &removed)
+
+	var disable func(fields []Field)
+	disable = func(fields []Field) {
+		walkFields(fields, func(field Field) {
+			switch field := field.(type) {
+			case *Form:
+				disable(field.allFields())
+			case *Submit, *Button, *ButtonInput, *Image, *FieldSet:
+				// A disabled fieldset would disable its buttons, too.
+			default:
+				setBoolField(field, "Disabled", true)
+			}
+		})
+	}
+	disable(p.allFields())
+	p.Fields = append(p.Fields, &Hidden{Name: ConfirmName, Value: "1"})
+	if _, err := h.WithContext(ctx).Prepare(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
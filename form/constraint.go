@@ -0,0 +1,198 @@
+package form
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Constraint is a Validator with an HTML5 equivalent, such as a required
+// field or a maximum length.
+//
+// When a form is prepared or rendered, the constraints among its
+// validators set the matching attributes of their fields (see
+// ApplyConstraints), so a rule declared once is checked both by the
+// browser and by Validate.
+type Constraint interface {
+	Validator
+	// Constrain sets the field's attribute for the constraint. Fields
+	// without the attribute are left alone.
+	Constrain(field Field)
+}
+
+// constraint implements Constraint with functions.
+type constraint struct {
+	validate  func(value string) error
+	constrain func(field Field)
+}
+
+func (c constraint) Validate(ctx context.Context, value string) error {
+	return c.validate(value)
+}
+
+func (c constraint) Constrain(field Field) {
+	c.constrain(field)
+}
+
+// Required rejects empty values, and sets the required attribute.
+func Required() Constraint {
+	return constraint{
+		validate: func(value string) error {
+			if len(strings.TrimSpace(value)) == 0 {
+				return errors.New("is required")
+			}
+			return nil
+		},
+		constrain: func(field Field) {
+			setBoolField(field, "Required", true)
+		},
+	}
+}
+
+// Pattern rejects values that do not match the regular expression as a
+// whole, and sets the pattern attribute. The expression should use the
+// syntax that Go and JavaScript share. Pattern panics if the expression
+// cannot be compiled.
+func Pattern(expr string) Constraint {
+	re := regexp.MustCompile("^(?:" + expr + ")$")
+	return constraint{
+		validate: func(value string) error {
+			if len(value) > 0 && !re.MatchString(value) {
+				return errors.New("is not in the expected format")
+			}
+			return nil
+		},
+		constrain: func(field Field) {
+			setStringField(field, "Pattern", expr)
+		},
+	}
+}
+
+// MaxLength rejects values longer than n characters, and sets the
+// maxlength attribute.
+func MaxLength(n int) Constraint {
+	return constraint{
+		validate: func(value string) error {
+			if utf8.RuneCountInString(value) > n {
+				return fmt.Errorf("must be at most %d characters long", n)
+			}
+			return nil
+		},
+		constrain: func(field Field) {
+			setNumberField(field, "MaxLength", n)
+		},
+	}
+}
+
+// Min rejects values less than min, and sets the min attribute. Numbers are
+// compared as numbers; other values, such as dates and times, are compared
+// as strings, which orders the formats of date and time inputs correctly.
+func Min(min string) Constraint {
+	return constraint{
+		validate: func(value string) error {
+			if len(value) > 0 && compareValues(value, min) < 0 {
+				return fmt.Errorf("must be at least %s", min)
+			}
+			return nil
+		},
+		constrain: func(field Field) {
+			setStringField(field, "Min", min)
+		},
+	}
+}
+
+// Max rejects values greater than max, and sets the max attribute. Values
+// are compared as they are by Min.
+func Max(max string) Constraint {
+	return constraint{
+		validate: func(value string) error {
+			if len(value) > 0 && compareValues(value, max) > 0 {
+				return fmt.Errorf("must be at most %s", max)
+			}
+			return nil
+		},
+		constrain: func(field Field) {
+			setStringField(field, "Max", max)
+		},
+	}
+}
+
+// Step rejects numbers that are not multiples of step, and sets the step
+// attribute. Unlike a browser, it counts steps from zero, not from the
+// field's minimum. A step of "any" allows any number.
+func Step(step string) Constraint {
+	s, err := strconv.ParseFloat(step, 64)
+	return constraint{
+		validate: func(value string) error {
+			if len(value) == 0 || err != nil || s <= 0 {
+				return nil
+			}
+			v, verr := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if verr != nil {
+				return errors.New("is not a number")
+			}
+			if n := v / s; math.Abs(n-math.Round(n)) > 1e-9 {
+				return fmt.Errorf("must be a multiple of %s", step)
+			}
+			return nil
+		},
+		constrain: func(field Field) {
+			setStringField(field, "Step", step)
+		},
+	}
+}
+
+// compareValues compares two values as numbers, if they are both numbers,
+// and as strings otherwise.
+func compareValues(a, b string) int {
+	x, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	y, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// setNumberField sets the named field of a pointer to a struct to n, if it
+// is a string or an unsigned integer.
+func setNumberField(s interface{}, name string, n int) {
+	if setStringField(s, name, strconv.Itoa(n)) {
+		return
+	}
+	v := reflect.ValueOf(s)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	if fv := v.Elem().FieldByName(name); fv.CanSet() && fv.Kind() == reflect.Uint64 && n >= 0 {
+		fv.SetUint(uint64(n))
+	}
+}
+
+// ApplyConstraints sets the HTML5 constraint attributes of the form's
+// fields from their validators (see Constraint). It is called when the
+// form is prepared or rendered.
+func (f *Form) ApplyConstraints() {
+	for name, vv := range f.validators {
+		field := f.Field(name)
+		if field == nil {
+			continue
+		}
+		for _, v := range vv {
+			if c, ok := v.(Constraint); ok {
+				c.Constrain(field)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package form
+
+import (
+	"context"
+
+	"golang.org/x/net/html"
+)
+
+// RenderContext carries per-request data to renderers.
+//
+// Because forms are frequently cached and shared between requests, data
+// that varies per request (the user's locale, the CSP nonce, and so on)
+// should be passed in a RenderContext rather than set on the form.
+//
+// A nil *RenderContext is valid, and uses DefaultRenderers.
+type RenderContext struct {
+	// Locale is the user's language, as a BCP 47 tag such as "en-US". It is
+	// set as the lang of a form that has none.
+	Locale string
+	// Nonce is the CSP nonce of the request. It is set on every Script and
+	// Style, in place of their own.
+	Nonce string
+	// User is the application's representation of the current user.
+	User interface{}
+	// Renderers is the registry of custom renderers. If nil,
+	// DefaultRenderers is used.
+	Renderers *Renderers
+
+	// writeDirect is set by Render from RenderOptions.
+	writeDirect bool
+	// slab, if set, allocates nodes (see RenderOptions.Slab).
+	slab *nodeSlab
+}
+
+// NewRenderContext creates a RenderContext from a context.Context.
+//
+// The CSP nonce is taken from the context (see WithNonce).
+func NewRenderContext(ctx context.Context) *RenderContext {
+	return &RenderContext{Nonce: Nonce(ctx)}
+}
+
+// ContextElement describes a form element whose rendering depends on a RenderContext.
+//
+// Containers implement this so that the context reaches their children.
+type ContextElement interface {
+	FormElement
+	RenderElement(*RenderContext) *html.Node
+}
+
+func (c *RenderContext) renderers() *Renderers {
+	if c == nil || c.Renderers == nil {
+		return DefaultRenderers
+	}
+	return c.Renderers
+}
+
+func (c *RenderContext) nonce() string {
+	if c == nil {
+		return ""
+	}
+	return c.Nonce
+}
+
+func (c *RenderContext) locale() string {
+	if c == nil {
+		return ""
+	}
+	return c.Locale
+}
+
+func (c *RenderContext) direct() bool {
+	return c != nil && c.writeDirect
+}
+
+// withDirect returns a copy of the context that writes plain inputs
+// directly, or not.
+func (c *RenderContext) withDirect(on bool) *RenderContext {
+	p := c.copy()
+	p.writeDirect = on
+	return p
+}
+
+// nodes returns the slab that allocates nodes, or nil.
+func (c *RenderContext) nodes() *nodeSlab {
+	if c == nil {
+		return nil
+	}
+	return c.slab
+}
+
+// withSlab returns a copy of the context that allocates nodes from s.
+func (c *RenderContext) withSlab(s *nodeSlab) *RenderContext {
+	p := c.copy()
+	p.slab = s
+	return p
+}
+
+// copy returns a copy of the context, or a new context if c is nil.
+func (c *RenderContext) copy() *RenderContext {
+	p := &RenderContext{}
+	if c != nil {
+		*p = *c
+	}
+	return p
+}
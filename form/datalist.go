@@ -0,0 +1,40 @@
+package form
+
+// Suggest creates a DataList of suggested values for a field, and sets the
+// field's List to the DataList's ID, so that user agents offer the values
+// as the user types. The user can still enter other values.
+//
+// The field can be any input with a List, such as a Text, Search, Email,
+// URL, or Number; Suggest panics if it has none. The DataList's ID is
+// derived from the field's ID (or Name). The DataList must be added to the
+// same form, usually right after the field:
+//
+//	city := &form.Text{Name: "city"}
+//	f.Add(city, form.Suggest(city, "Berlin", "Oslo", "Paris"))
+//
+// For suggestions with labels, add Options to the DataList's Options.
+func Suggest(field Field, values ...string) *DataList {
+	d := &DataList{}
+	for _, v := range values {
+		d.Options = append(d.Options, &Option{Value: v})
+	}
+	return d.ListFor(field)
+}
+
+// ListFor sets the field's List to the data list's ID, and returns the data
+// list. A data list without an ID is given one derived from the field's ID
+// (or Name). This wires up data lists that are declared apart from their
+// fields, or that several fields share.
+//
+// ListFor panics if the field has no List (see Suggest).
+func (d *DataList) ListFor(field Field) *DataList {
+	h := htmlOf(field)
+	if h == nil || !setStringField(field, "List", stringField(field, "List")) {
+		panic("form: ListFor field has no list")
+	}
+	if len(d.Id) == 0 {
+		d.Id = h.EnsureId(nameOf(field)) + "-list"
+	}
+	setStringField(field, "List", d.Id)
+	return d
+}
@@ -0,0 +1,63 @@
+package form
+
+// FieldDefaults are attributes given to every field of a form.
+//
+// This allows a theme to style all of a form's fields (for example, with
+// class="input") without touching each field declaration.
+type FieldDefaults struct {
+	// Class names are added to each field that does not already have them.
+	Class []string
+	// Data attributes are added to each field that does not already set them.
+	Data map[string]string
+}
+
+// ApplyDefaults merges the form's Defaults into its fields.
+//
+// Fields keep their own classes and data attributes; defaults are added
+// after them. Containers (Div, FieldSet, Label) are not changed, though their
+// fields are. Embedded forms have their own Defaults. Applying defaults more
+// than once has no further effect.
+//
+// This is called when the form is prepared or rendered.
+func (f *Form) ApplyDefaults() {
+	if len(f.Defaults.Class) == 0 && len(f.Defaults.Data) == 0 {
+		return
+	}
+	walkFields(f.allFields(), func(field Field) {
+		switch field.(type) {
+		case *Div, *FieldSet, *Lazy, *Label, *Table, *Form:
+			return
+		}
+		if h := htmlOf(field); h != nil {
+			f.Defaults.apply(h)
+		}
+	})
+}
+
+// apply merges the defaults into h.
+func (d FieldDefaults) apply(h *HTML) {
+	for _, c := range d.Class {
+		if !hasClass(h.Class, c) {
+			h.Class = append(h.Class, c)
+		}
+	}
+	for k, v := range d.Data {
+		if _, ok := h.Data[k]; ok {
+			continue
+		}
+		if h.Data == nil {
+			h.Data = make(map[string]string, len(d.Data))
+		}
+		h.Data[k] = v
+	}
+}
+
+// hasClass returns true if classes contains c.
+func hasClass(classes []string, c string) bool {
+	for _, cc := range classes {
+		if cc == c {
+			return true
+		}
+	}
+	return false
+}
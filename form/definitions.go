@@ -0,0 +1,155 @@
+package form
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"sync"
+)
+
+// Loader builds the definition of a named form.
+type Loader func(name string) (*Form, error)
+
+// Fetcher retrieves an encoded form definition by name.
+//
+// A Fetcher is a read-through cache: on a miss, it is expected to build the
+// definition (usually with EncodeLoader) and keep the result. This matches
+// the shape of distributed caches like groupcache, where a group can be
+// adapted like this:
+//
+//	group := groupcache.NewGroup("forms", 64<<20, groupcache.GetterFunc(
+//		func(ctx groupcache.Context, key string, dest groupcache.Sink) error {
+//			b, err := form.EncodeLoader(load)(ctx.(context.Context), key)
+//			if err != nil {
+//				return err
+//			}
+//			return dest.SetBytes(b)
+//		}))
+//
+//	type groupFetcher struct{ *groupcache.Group }
+//
+//	func (g groupFetcher) Fetch(ctx context.Context, name string) ([]byte, error) {
+//		var b []byte
+//		err := g.Get(ctx, name, groupcache.AllocatingByteSliceSink(&b))
+//		return b, err
+//	}
+type Fetcher interface {
+	Fetch(ctx context.Context, name string) ([]byte, error)
+}
+
+// FetcherFunc adapts a function to a Fetcher.
+type FetcherFunc func(ctx context.Context, name string) ([]byte, error)
+
+// Fetch calls fn(ctx, name).
+func (fn FetcherFunc) Fetch(ctx context.Context, name string) ([]byte, error) {
+	return fn(ctx, name)
+}
+
+// Definitions caches form definitions, so that heavy forms are only built once.
+//
+// This caches the declared form, not the per-user instances managed by a
+// FormHandler. Each call to Get returns a new copy of the form, which can
+// then be prepared, rendered, and submitted without affecting other copies.
+//
+// Definitions are encoded with encoding/gob. Function fields, such as
+// Computed.Compute and Form.PrefixFunc, do not survive encoding, and must
+// be set on the returned copy if they are needed.
+type Definitions struct {
+	fetcher Fetcher
+}
+
+// NewDefinitions creates a new Definitions that reads through the Fetcher.
+func NewDefinitions(f Fetcher) *Definitions {
+	return &Definitions{fetcher: f}
+}
+
+// Get returns a copy of the named form.
+func (d *Definitions) Get(ctx context.Context, name string) (*Form, error) {
+	b, err := d.fetcher.Fetch(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeForm(b)
+}
+
+// EncodeLoader returns a function that loads a form and encodes it.
+//
+// This is intended for use as the getter of a read-through cache.
+func EncodeLoader(load Loader) func(ctx context.Context, name string) ([]byte, error) {
+	return func(ctx context.Context, name string) ([]byte, error) {
+		f, err := load(name)
+		if err != nil {
+			return nil, err
+		}
+		return EncodeForm(f)
+	}
+}
+
+// EncodeForm encodes a form with encoding/gob.
+func EncodeForm(f *Form) ([]byte, error) {
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(f)
+	return b.Bytes(), err
+}
+
+// DecodeForm decodes a form encoded with EncodeForm.
+func DecodeForm(b []byte) (*Form, error) {
+	f := &Form{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// LocalFetcher returns a Fetcher that keeps encoded definitions in memory.
+//
+// Each definition is loaded at most once, even when it is requested
+// concurrently. Failed loads are not kept, so they are retried on the next
+// request. This is suitable for a single process; fleets of servers should
+// use a distributed Fetcher.
+func LocalFetcher(load Loader) Fetcher {
+	return &localFetcher{
+		load:    EncodeLoader(load),
+		entries: map[string]*localEntry{},
+	}
+}
+
+type localEntry struct {
+	done chan struct{}
+	b    []byte
+	err  error
+}
+
+type localFetcher struct {
+	load    func(context.Context, string) ([]byte, error)
+	mx      sync.Mutex
+	entries map[string]*localEntry
+}
+
+func (l *localFetcher) Fetch(ctx context.Context, name string) ([]byte, error) {
+	l.mx.Lock()
+	e, ok := l.entries[name]
+	if !ok {
+		e = &localEntry{done: make(chan struct{})}
+		l.entries[name] = e
+	}
+	l.mx.Unlock()
+
+	if ok {
+		select {
+		case <-e.done:
+			return e.b, e.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	e.b, e.err = l.load(ctx, name)
+	if e.err != nil {
+		l.mx.Lock()
+		delete(l.entries, name)
+		l.mx.Unlock()
+	}
+	close(e.done)
+	return e.b, e.err
+}
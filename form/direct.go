@@ -0,0 +1,300 @@
+package form
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// directBuffers holds the buffers used to write elements directly.
+var directBuffers = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 256)
+		return &b
+	},
+}
+
+// directElement writes the markup of a field straight to a raw node.
+//
+// This produces the same markup that rendering the field's Element would,
+// but without building the element's attributes. The node and its string
+// are still allocated. Only plain inputs are written directly; for
+// other fields, this returns nil. Password fields are not written
+// directly, because their values are removed by the form's echo policy.
+func directElement(f Field) *html.Node {
+	typ, in := plainInput(f)
+	if in == nil {
+		return nil
+	}
+	if _, ok := f.(*Password); ok {
+		return nil
+	}
+
+	bp := directBuffers.Get().(*[]byte)
+	b := appendInput((*bp)[:0], typ, in)
+	n := &html.Node{Type: html.RawNode, Data: string(b)}
+	*bp = b
+	directBuffers.Put(bp)
+	return n
+}
+
+// directNodes writes the markup of a plain input and its label, if it has
+// one, to raw nodes, as fieldNodes builds them. The nodes share one string
+// and are allocated together, so a labeled input still costs three
+// allocations, but not the label's attributes and text or the input's
+// attributes.
+// For other fields, and fields with custom renderers, this returns nil.
+func directNodes(ctx *RenderContext, f Field, text string) []*html.Node {
+	typ, in := plainInput(f)
+	if in == nil || ctx.renderers().Lookup(f) != nil {
+		return nil
+	}
+	if _, ok := f.(*Password); ok {
+		return nil
+	}
+
+	bp := directBuffers.Get().(*[]byte)
+	b, split := (*bp)[:0], 0
+	switch f.(type) {
+	case *Checkbox, *Radio:
+		if len(text) > 0 {
+			b = append(b, "<label>"...)
+			b = appendInput(b, typ, in)
+			b = appendEscaped(b, text)
+			b = append(b, "</label>"...)
+			break
+		}
+		b = appendInput(b, typ, in)
+	default:
+		if len(text) > 0 {
+			in.Id = in.EnsureId(in.Name)
+			b = append(b, "<label"...)
+			b = appendAttr(b, "for", in.Id)
+			b = append(b, '>')
+			b = appendEscaped(b, text)
+			b = append(b, "</label>"...)
+			split = len(b)
+		}
+		b = appendInput(b, typ, in)
+	}
+	data := string(b)
+	*bp = b
+	directBuffers.Put(bp)
+
+	if split == 0 {
+		return []*html.Node{{Type: html.RawNode, Data: data}}
+	}
+	n := make([]html.Node, 2)
+	n[0] = html.Node{Type: html.RawNode, Data: data[:split]}
+	n[1] = html.Node{Type: html.RawNode, Data: data[split:]}
+	return []*html.Node{&n[0], &n[1]}
+}
+
+// plainInput returns the type and attributes of a field that is rendered
+// as a plain input, or a nil *Input.
+func plainInput(f Field) (string, *Input) {
+	var typ string
+	var in *Input
+	switch f := f.(type) {
+	case *Input:
+		typ, in = "", f
+	case *Text:
+		typ, in = "text", (*Input)(f)
+	case *Submit:
+		typ, in = "submit", (*Input)(f)
+	case *Tel:
+		typ, in = "tel", (*Input)(f)
+	case *URL:
+		typ, in = "url", (*Input)(f)
+	case *Email:
+		typ, in = "email", (*Input)(f)
+	case *Date:
+		typ, in = "date", (*Input)(f)
+	case *Time:
+		typ, in = "time", (*Input)(f)
+	case *Month:
+		typ, in = "month", (*Input)(f)
+	case *Week:
+		typ, in = "week", (*Input)(f)
+	case *DatetimeLocal:
+		typ, in = "datetime-local", (*Input)(f)
+	case *Search:
+		typ, in = "search", (*Input)(f)
+	case *Number:
+		typ, in = "number", (*Input)(f)
+	case *Range:
+		typ, in = "range", (*Input)(f)
+	case *Color:
+		typ, in = "color", (*Input)(f)
+	case *Checkbox:
+		typ, in = "checkbox", (*Input)(f)
+	case *Radio:
+		typ, in = "radio", (*Input)(f)
+	case *Reset:
+		typ, in = "reset", (*Input)(f)
+	case *ButtonInput:
+		typ, in = "button", (*Input)(f)
+	case *Hidden:
+		typ, in = "hidden", (*Input)(f)
+	case *Password:
+		typ, in = "password", (*Input)(f)
+	}
+	return typ, in
+}
+
+// appendInput appends the markup of an input to b.
+//
+// The attributes are written in the same order, and escaped in the same
+// way, as html.Render writes the node built by inputElement.
+func appendInput(b []byte, typ string, in *Input) []byte {
+	b = append(b, "<input"...)
+	if len(typ) > 0 {
+		b = appendAttr(b, "type", typ)
+	}
+	b = appendAttr(b, "accept", in.Accept)
+	b = appendAttr(b, "alt", in.Alt)
+	b = appendAttr(b, "autocomplete", in.Autocomplete)
+	b = appendAttr(b, "dirname", in.Dirname)
+	b = appendAttr(b, "form", in.Form)
+	b = appendAttr(b, "list", in.List)
+	b = appendAttr(b, "inputmode", in.InputMode)
+	b = appendAttr(b, "max", in.Max)
+	b = appendAttr(b, "min", in.Min)
+	b = appendAttr(b, "maxlength", in.MaxLength)
+	b = appendAttr(b, "name", in.Name)
+	b = appendAttr(b, "pattern", in.Pattern)
+	b = appendAttr(b, "placeholder", in.Placeholder)
+	b = appendAttr(b, "src", in.Src)
+	b = appendAttr(b, "step", in.Step)
+	b = appendAttr(b, "value", in.Value)
+	b = appendUintAttr(b, "height", in.Height)
+	b = appendUintAttr(b, "width", in.Width)
+	b = appendUintAttr(b, "size", in.Size)
+	b = appendBoolAttr(b, "autofocus", in.Autofocus)
+	b = appendBoolAttr(b, "checked", in.Checked)
+	b = appendBoolAttr(b, "disabled", in.Disabled)
+	b = appendBoolAttr(b, "formnovalidate", in.FormNoValidate)
+	b = appendBoolAttr(b, "multiple", in.Multiple)
+	b = appendBoolAttr(b, "readonly", in.ReadOnly)
+	b = appendBoolAttr(b, "required", in.Required)
+	b = appendHTML(b, &in.HTML)
+	return append(b, "/>"...)
+}
+
+// appendHTML appends the global attributes, in the order of HTML.Attach.
+func appendHTML(b []byte, g *HTML) []byte {
+	switch g.ContentEditable {
+	case OTrue:
+		b = appendAttr(b, "contenteditable", "true")
+	case OFalse:
+		b = appendAttr(b, "contenteditable", "false")
+	}
+	if g.Hidden == OTrue {
+		b = appendAttr(b, "hidden", "hidden")
+	}
+	switch g.Spellcheck {
+	case OTrue:
+		b = appendAttr(b, "spellcheck", "true")
+	case OFalse:
+		b = appendAttr(b, "spellcheck", "false")
+	}
+	for k, v := range g.Data {
+		b = append(b, ' ')
+		b = append(b, k...)
+		b = append(b, `="`...)
+		b = appendEscaped(b, v)
+		b = append(b, '"')
+	}
+	if len(g.Class) > 0 {
+		b = append(b, ` class="`...)
+		for i, c := range g.Class {
+			if i > 0 {
+				b = append(b, ' ')
+			}
+			b = appendEscaped(b, c)
+		}
+		b = append(b, '"')
+	}
+	b = appendAttr(b, "accesskey", g.AccessKey)
+	b = appendAttr(b, "id", g.Id)
+	b = appendAttr(b, "dir", g.Dir)
+	b = appendAttr(b, "lang", g.Lang)
+	b = appendAttr(b, "style", g.Style)
+	b = appendAttr(b, "tabindex", g.TabIndex)
+	b = appendAttr(b, "title", g.Title)
+	b = appendAttr(b, "translate", g.Translate)
+	return b
+}
+
+// appendAttr appends an attribute, unless its value is empty.
+func appendAttr(b []byte, key, val string) []byte {
+	if len(val) == 0 {
+		return b
+	}
+	b = append(b, ' ')
+	b = append(b, key...)
+	b = append(b, `="`...)
+	b = appendEscaped(b, val)
+	return append(b, '"')
+}
+
+// appendUintAttr appends a numeric attribute, unless it is zero.
+func appendUintAttr(b []byte, key string, val uint64) []byte {
+	if val == 0 {
+		return b
+	}
+	b = append(b, ' ')
+	b = append(b, key...)
+	b = append(b, `="`...)
+	b = strconv.AppendUint(b, val, 10)
+	return append(b, '"')
+}
+
+// appendBoolAttr appends a boolean attribute (e.g. disabled="disabled"), if it is set.
+func appendBoolAttr(b []byte, key string, val bool) []byte {
+	if !val {
+		return b
+	}
+	b = append(b, ' ')
+	b = append(b, key...)
+	b = append(b, `="`...)
+	b = append(b, key...)
+	return append(b, '"')
+}
+
+// appendEscaped appends s, escaped as html.Render escapes attribute values.
+func appendEscaped(b []byte, s string) []byte {
+	for {
+		i := strings.IndexAny(s, "&'<>\"\r")
+		if i < 0 {
+			return append(b, s...)
+		}
+		b = append(b, s[:i]...)
+		switch s[i] {
+		case '&':
+			b = append(b, "&amp;"...)
+		case '\'':
+			b = append(b, "&#39;"...)
+		case '<':
+			b = append(b, "&lt;"...)
+		case '>':
+			b = append(b, "&gt;"...)
+		case '"':
+			b = append(b, "&#34;"...)
+		case '\r':
+			b = append(b, "&#13;"...)
+		}
+		s = s[i+1:]
+	}
+}
+
+// direct returns true if the form's fields may be written directly.
+//
+// Prefixes, automatic IDs, and echo policies other than the default are
+// applied to the rendered node tree, so they cannot be applied to fields
+// that are written directly.
+func (f *Form) direct() bool {
+	return len(f.Prefix) == 0 && !f.AutoID && len(f.Echo) == 0
+}
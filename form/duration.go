@@ -0,0 +1,197 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// ErrInvalidDuration indicates a submitted duration that cannot be parsed.
+	ErrInvalidDuration = errors.New("Invalid duration")
+	// ErrDurationTooShort indicates a submitted duration less than the Min.
+	ErrDurationTooShort = errors.New("Duration is too short")
+	// ErrDurationTooLong indicates a submitted duration greater than the Max.
+	ErrDurationTooLong = errors.New("Duration is too long")
+)
+
+// Day is a unit for Duration fields.
+const Day = 24 * time.Hour
+
+// durationUnits names the units a Duration field can offer.
+var durationUnits = []struct {
+	Unit        time.Duration
+	Code, Label string
+}{
+	{time.Millisecond, "ms", "milliseconds"},
+	{time.Second, "s", "seconds"},
+	{time.Minute, "m", "minutes"},
+	{time.Hour, "h", "hours"},
+	{Day, "d", "days"},
+}
+
+// Duration is a composite field for a length of time.
+//
+// If Units is empty, it is rendered as a text input for hours and minutes,
+// like "1:30". Otherwise, it is rendered as a number input (named Name)
+// followed by a select of Units (named by UnitName). Units may be
+// time.Millisecond, time.Second, time.Minute, time.Hour, or Day.
+//
+// Submitted durations outside of Min and Max (when they are non-zero) are
+// rejected, and an error is added to the form's Errors.
+type Duration struct {
+	HTML
+	Name               string
+	Units              []time.Duration
+	Min, Max           time.Duration
+	Disabled, Required bool
+
+	// Value is the duration. In hours and minutes mode, it is truncated to
+	// the minute when rendered.
+	Value time.Duration
+
+	// Label is not an attribute of the field. It is used for a label element.
+	Label string
+}
+
+// UnitName returns the name of the unit select.
+func (d *Duration) UnitName() string {
+	return d.Name + "_unit"
+}
+
+// Count returns the Value as a number of Unit, or as hours and minutes if
+// there are no Units.
+func (d *Duration) Count() string {
+	if len(d.Units) == 0 {
+		return fmt.Sprintf("%d:%02d", d.Value/time.Hour, (d.Value%time.Hour)/time.Minute)
+	}
+	return strconv.FormatInt(int64(d.Value/d.Unit()), 10)
+}
+
+// Unit returns the unit in which the Value is displayed.
+//
+// This is the largest of the Units that evenly divides the Value, or the
+// smallest of the Units if none does.
+func (d *Duration) Unit() time.Duration {
+	var best, smallest time.Duration
+	for _, u := range d.Units {
+		if u <= 0 {
+			continue
+		}
+		if smallest == 0 || u < smallest {
+			smallest = u
+		}
+		if d.Value%u == 0 && u > best {
+			best = u
+		}
+	}
+	if best > 0 {
+		return best
+	}
+	if smallest > 0 {
+		return smallest
+	}
+	return time.Minute
+}
+
+// UnitOptions returns the options of the unit select.
+func (d *Duration) UnitOptions() []*Option {
+	opts := []*Option{}
+	unit := d.Unit()
+	for _, u := range d.Units {
+		for _, du := range durationUnits {
+			if du.Unit == u {
+				opts = append(opts, &Option{Value: du.Code, Label: du.Label, Selected: u == unit})
+			}
+		}
+	}
+	return opts
+}
+
+// Element retrieves the field as an html.Node of type ElementNode.
+//
+// The field's HTML attributes are applied to a span containing the inputs.
+func (d *Duration) Element() *html.Node {
+	n := &html.Node{Type: html.ElementNode, DataAtom: atom.Span, Data: "span"}
+	d.HTML.Attach(n)
+
+	if len(d.Units) == 0 {
+		in := &Text{
+			Name:      d.Name,
+			Value:     d.Count(),
+			Pattern:   `[0-9]+:[0-5][0-9]`,
+			InputMode: "numeric",
+			Disabled:  d.Disabled,
+			Required:  d.Required,
+		}
+		n.AppendChild(in.Element())
+		return n
+	}
+
+	in := &Number{Name: d.Name, Value: d.Count(), Step: "1", Disabled: d.Disabled, Required: d.Required}
+	n.AppendChild(in.Element())
+	sel := &Select{Name: d.UnitName(), Disabled: d.Disabled, Required: d.Required}
+	for _, o := range d.UnitOptions() {
+		sel.Options = append(sel.Options, o)
+	}
+	n.AppendChild(sel.Element())
+	return n
+}
+
+// reconcile sets the Value from submitted data.
+//
+// The Value is only changed if the submitted duration is valid.
+func (d *Duration) reconcile(count, unit string) error {
+	if len(count) == 0 {
+		return nil
+	}
+	v, err := d.parse(count, unit)
+	if err != nil {
+		return err
+	}
+	if d.Min != 0 && v < d.Min {
+		return ErrDurationTooShort
+	}
+	if d.Max != 0 && v > d.Max {
+		return ErrDurationTooLong
+	}
+	d.Value = v
+	return nil
+}
+
+func (d *Duration) parse(count, unit string) (time.Duration, error) {
+	count = strings.TrimSpace(count)
+	if len(d.Units) == 0 {
+		parts := strings.Split(count, ":")
+		if len(parts) != 2 {
+			return 0, ErrInvalidDuration
+		}
+		h, err := strconv.ParseUint(parts[0], 10, 32)
+		if err != nil {
+			return 0, ErrInvalidDuration
+		}
+		m, err := strconv.ParseUint(parts[1], 10, 8)
+		if err != nil || m > 59 {
+			return 0, ErrInvalidDuration
+		}
+		return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+	}
+
+	n, err := strconv.ParseInt(count, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidDuration
+	}
+	for _, u := range d.Units {
+		for _, du := range durationUnits {
+			if du.Unit == u && du.Code == unit {
+				return time.Duration(n) * u, nil
+			}
+		}
+	}
+	return 0, ErrInvalidDuration
+}
@@ -0,0 +1,121 @@
+package form
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// EchoPolicy describes how a field's value is rendered.
+//
+// This matters most when a form is rendered again after a failed
+// submission: the user's input should be shown so that it can be
+// corrected, but some values (like passwords) should never be sent back to
+// the browser, and others (like card numbers) should only be partly shown.
+type EchoPolicy uint8
+
+const (
+	// EchoDefault renders Password fields as EchoNever, and all other
+	// fields as EchoNormal.
+	EchoDefault EchoPolicy = iota
+	// EchoNormal renders the value as-is.
+	EchoNormal
+	// EchoNever renders the field without a value.
+	EchoNever
+	// EchoMaskMiddle renders only the first and last four characters of
+	// the value, with MaskChar in between. Values of eight characters or
+	// less are masked entirely.
+	EchoMaskMiddle
+)
+
+// MaskChar replaces the hidden characters of an EchoMaskMiddle value.
+var MaskChar = "•"
+
+// echoPolicy returns the policy for a field.
+func (f *Form) echoPolicy(field Field) EchoPolicy {
+	p := f.Echo[nameOf(field)]
+	if p != EchoDefault {
+		return p
+	}
+	if _, ok := field.(*Password); ok {
+		return EchoNever
+	}
+	return EchoNormal
+}
+
+// applyEcho applies the form's echo policies to the value attributes and
+// text areas in a node tree.
+func (f *Form) applyEcho(n *html.Node) {
+	policies := map[string]EchoPolicy{}
+	walkFields(f.allFields(), func(field Field) {
+		if p := f.echoPolicy(field); p != EchoNormal {
+			policies[nameOf(field)] = p
+		}
+	})
+	if len(policies) > 0 {
+		echoNode(n, policies)
+	}
+}
+
+func echoNode(n *html.Node, policies map[string]EchoPolicy) {
+	if n.Type == html.ElementNode {
+		p, ok := policies[attrValue(n, "name")]
+		switch {
+		case !ok:
+		case n.Data == "input":
+			switch strings.ToLower(attrValue(n, "type")) {
+			case "checkbox", "radio", "submit", "reset", "button", "image":
+				// The value of these is not user input.
+			default:
+				for i, a := range n.Attr {
+					if a.Key == "value" {
+						n.Attr[i].Val = echo(p, a.Val)
+					}
+				}
+			}
+		case n.Data == "textarea":
+			if c := n.FirstChild; c != nil && c.Type == html.TextNode {
+				c.Data = echo(p, c.Data)
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		echoNode(c, policies)
+	}
+}
+
+// echo returns the value rendered for a policy.
+func echo(p EchoPolicy, v string) string {
+	switch p {
+	case EchoNever:
+		return ""
+	case EchoMaskMiddle:
+		return maskMiddle(v)
+	}
+	return v
+}
+
+// maskMiddle replaces all but the first and last four characters of v.
+func maskMiddle(v string) string {
+	n := utf8.RuneCountInString(v)
+	if n == 0 {
+		return v
+	}
+	if n <= 8 {
+		return strings.Repeat(MaskChar, n)
+	}
+	r := []rune(v)
+	return string(r[:4]) + strings.Repeat(MaskChar, n-8) + string(r[n-4:])
+}
+
+// echoed returns true if the submitted value of a field is its masked
+// value, which means that the user did not change it.
+func (f *Form) echoed(field Field, data *url.Values) bool {
+	if f.echoPolicy(field) != EchoMaskMiddle {
+		return false
+	}
+	cur := stringField(field, "Value")
+	return len(cur) > 0 && data.Get(nameOf(field)) == maskMiddle(cur)
+}
@@ -0,0 +1,48 @@
+package form
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+// ErrInvalidEmail indicates a submitted email address that cannot be parsed.
+var ErrInvalidEmail = errors.New("Invalid email address")
+
+// Addresses returns the email addresses in the field's Value.
+//
+// If Multiple is set, user agents submit a comma-separated list of
+// addresses. Empty entries are omitted.
+func (i *Email) Addresses() []string {
+	addrs := []string{}
+	for _, a := range strings.Split(i.Value, ",") {
+		if a = strings.TrimSpace(a); len(a) > 0 {
+			addrs = append(addrs, a)
+		}
+	}
+	return addrs
+}
+
+// reconcileEmail validates a submitted email value.
+//
+// Each address is parsed according to RFC 5322, and the list is returned
+// without display names or extra space. Only one address is allowed unless
+// multiple is true.
+func reconcileEmail(val string, multiple bool) (string, error) {
+	parts := strings.Split(val, ",")
+	if !multiple && len(parts) > 1 {
+		return "", ErrInvalidEmail
+	}
+	addrs := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); len(p) == 0 {
+			continue
+		}
+		a, err := mail.ParseAddress(p)
+		if err != nil {
+			return "", ErrInvalidEmail
+		}
+		addrs = append(addrs, a.Address)
+	}
+	return strings.Join(addrs, ","), nil
+}
@@ -0,0 +1,56 @@
+package form
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// assets holds the default stylesheet (form.css) and enhancement script
+// (form.js).
+//
+//go:embed assets/form.css assets/form.js
+var assets embed.FS
+
+// EnhancementScript is optional client-side code for features that HTML
+// cannot express on its own.
+//
+// Currently, it adds a character counter after each TextArea with Counter
+// set, and uploads the files of File fields in direct-upload mode (see
+// File.UploadTo). It has no dependencies, and does nothing on pages without
+// such fields. Add it to a page with a Script field:
+//
+//	f.Add(&form.Script{Content: form.EnhancementScript})
+//
+// It is also served as form.js by AssetHandler.
+//
+//go:embed assets/form.js
+var EnhancementScript string
+
+// Stylesheet is the default theme for rendered forms.
+//
+// It styles fields, fieldsets, labels, error lists (see RenderField),
+// character counters, and access key legends. Colors and spacing are CSS
+// custom properties (such as --form-error), so most themes only need to
+// override those. It is also served as form.css by AssetHandler.
+//
+//go:embed assets/form.css
+var Stylesheet string
+
+// AssetHandler serves the default stylesheet and enhancement script, as
+// form.css and form.js.
+//
+// Mount it under a prefix with http.StripPrefix:
+//
+//	http.Handle("/form/", http.StripPrefix("/form/", form.AssetHandler()))
+//
+// To replace either file, serve your own under the same path ahead of this
+// handler.
+func AssetHandler() http.Handler {
+	sub, err := fs.Sub(assets, "assets")
+	if err != nil {
+		// The embedded directory always exists.
+		panic(err)
+	}
+	return http.FileServer(http.FS(sub))
+}
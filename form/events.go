@@ -0,0 +1,139 @@
+package form
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Kinds of FormEvent.
+const (
+	// EventPrepared is published when a form is prepared.
+	EventPrepared = "prepared"
+	// EventSubmitted is published when a submitted form is retrieved.
+	EventSubmitted = "submitted"
+	// EventFailed is published when a submission cannot be retrieved, or
+	// is retrieved with errors.
+	EventFailed = "failed"
+)
+
+// ErrEventsFull indicates that an event was dropped, because its channel
+// was full.
+var ErrEventsFull = errors.New("Event channel is full")
+
+// FormEvent is a step in the lifecycle of a form, published by a
+// FormHandler to its Events.
+//
+// Events carry no security tokens or values, so they can be published to
+// other services.
+type FormEvent struct {
+	// Kind is EventPrepared, EventSubmitted, or EventFailed.
+	Kind string `json:"kind"`
+	// Form is the form's Name. It is empty if a submission failed before
+	// its form was found, as when its token had expired.
+	Form string `json:"form,omitempty"`
+	// Namespace is the handler's Namespace.
+	Namespace string `json:"namespace,omitempty"`
+	// Error describes why a submission failed.
+	Error string `json:"error,omitempty"`
+	// Fields lists the names of the fields that have errors.
+	Fields []string `json:"fields,omitempty"`
+	// Time is the handler's time when the event was published.
+	Time time.Time `json:"time"`
+}
+
+// Events receives the events of a FormHandler, so that other services can
+// react to forms asynchronously, as by counting abandoned forms.
+//
+// Publish is called as the handler works, so it should not take long. Its
+// errors are not returned to the handler's callers, whose forms are not
+// affected by them; an implementation that must not lose events should
+// report its own errors.
+type Events interface {
+	Publish(ctx context.Context, e FormEvent) error
+}
+
+// publish sends an event to the handler's Events, if it has any.
+func (f *FormHandler) publish(ctx context.Context, kind string, form *Form, err error) {
+	if f.Events == nil {
+		return
+	}
+	e := FormEvent{Kind: kind, Namespace: f.Namespace, Time: f.now()}
+	if form != nil {
+		e.Form = form.Name
+		for name := range form.Errors {
+			e.Fields = append(e.Fields, name)
+		}
+		sort.Strings(e.Fields)
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	f.Events.Publish(ctx, e)
+}
+
+// published publishes the result of retrieving a form, and returns it.
+func (f *FormHandler) published(ctx context.Context, form *Form, err error) (*Form, error) {
+	if err != nil {
+		f.publish(ctx, EventFailed, form, err)
+	} else {
+		f.publish(ctx, EventSubmitted, form, nil)
+	}
+	return form, err
+}
+
+// ChanEvents publishes events to a channel, for services that react to
+// forms in the same process.
+//
+// Publish never blocks: if the channel is full, the event is dropped and
+// ErrEventsFull is returned, so a slow reader cannot stall the handler.
+type ChanEvents chan FormEvent
+
+// Publish sends the event to the channel.
+func (c ChanEvents) Publish(ctx context.Context, e FormEvent) error {
+	select {
+	case c <- e:
+		return nil
+	default:
+		return ErrEventsFull
+	}
+}
+
+// NATSPublisher publishes messages to NATS subjects. A *nats.Conn, from
+// github.com/nats-io/nats.go, is a NATSPublisher.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSEvents publishes events to NATS, as JSON.
+//
+// Each event is published to the subject Subject + "." + its Kind, such as
+// "forms.submitted", so that services can subscribe to the kinds they need,
+// or to all of them with "forms.>".
+type NATSEvents struct {
+	// Subject is the prefix of the events' subjects. The default is
+	// "forms".
+	Subject string
+
+	conn NATSPublisher
+}
+
+// NewNATSEvents creates NATSEvents that publish with conn.
+func NewNATSEvents(conn NATSPublisher, subject string) *NATSEvents {
+	return &NATSEvents{Subject: subject, conn: conn}
+}
+
+// Publish publishes the event to its subject.
+func (n *NATSEvents) Publish(ctx context.Context, e FormEvent) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	subject := n.Subject
+	if len(subject) == 0 {
+		subject = "forms"
+	}
+	return n.conn.Publish(subject+"."+e.Kind, b)
+}
@@ -0,0 +1,115 @@
+package form
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// CSVWriter writes submitted forms as rows of a CSV file.
+//
+// The columns are taken from the declaration of the form: there is one for
+// each field name, in the order the fields were added, headed by the
+// field's Label (or its name, if it has no label). Names include the form's
+// Prefix. Fields with several values, such as checkboxes or multiple
+// selects, have their values joined by Separator.
+//
+// Rows are written as each form is given, so a CSVWriter can stream a large
+// export to an http.ResponseWriter. Values are redacted.
+//
+// A cell that starts with =, +, -, @, a tab, or a carriage return is
+// prefixed with a single quote, so that spreadsheets do not run a value a
+// user submitted as a formula. Negative numbers are quoted this way, too.
+type CSVWriter struct {
+	// Redactor redacts values before they are written. If it is nil,
+	// DefaultRedactor is used.
+	Redactor *Redactor
+	// Separator joins multiple values of a field. If it is empty, "; " is
+	// used.
+	Separator string
+
+	w       *csv.Writer
+	names   []string
+	headers []string
+	started bool
+}
+
+// NewCSVWriter creates a CSVWriter for forms declared like decl.
+func NewCSVWriter(w io.Writer, decl *Form) *CSVWriter {
+	c := &CSVWriter{w: csv.NewWriter(w)}
+	labels := decl.labels()
+	seen := map[string]bool{}
+	for _, n := range decl.names() {
+		n = decl.prefixed(n)
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		header := labels[n]
+		if len(header) == 0 {
+			header = n
+		}
+		c.names = append(c.names, n)
+		c.headers = append(c.headers, csvCell(header))
+	}
+	return c
+}
+
+// Write writes the header, if it has not been written, and a row for f.
+func (c *CSVWriter) Write(f *Form) error {
+	if !c.started {
+		c.started = true
+		if err := c.w.Write(c.headers); err != nil {
+			return err
+		}
+	}
+
+	r := c.Redactor
+	if r == nil {
+		r = DefaultRedactor
+	}
+	sep := c.Separator
+	if len(sep) == 0 {
+		sep = "; "
+	}
+	v := *r.Values(f)
+	row := make([]string, len(c.names))
+	for i, n := range c.names {
+		row[i] = csvCell(strings.Join(v[n], sep))
+	}
+	if err := c.w.Write(row); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvCell neutralises a cell that a spreadsheet would read as a formula.
+func csvCell(s string) string {
+	if len(s) > 0 && strings.IndexByte("=+-@\t\r", s[0]) >= 0 {
+		return "'" + s
+	}
+	return s
+}
+
+// labels returns the labels of the form's fields, by prefixed name.
+//
+// Where several fields share a name, the first label is used.
+func (f *Form) labels() map[string]string {
+	labels := map[string]string{}
+	walkFields(f.allFields(), func(field Field) {
+		if sub, ok := field.(*Form); ok {
+			for n, l := range sub.labels() {
+				if _, ok := labels[f.prefixed(n)]; !ok {
+					labels[f.prefixed(n)] = l
+				}
+			}
+			return
+		}
+		n, l := f.prefixed(nameOf(field)), labelOf(field)
+		if _, ok := labels[n]; !ok && len(l) > 0 {
+			labels[n] = l
+		}
+	})
+	return labels
+}
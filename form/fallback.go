@@ -0,0 +1,173 @@
+package form
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// CookieFallback keeps forms working while the cache is failing.
+//
+// When a form cannot be cached, PrepareResponse puts a minimal record of
+// it in a cookie instead: the form's name, its security token, its
+// namespace and its expiration time, encrypted and authenticated with
+// AES-GCM. When the form is submitted and the cache is still failing,
+// RetrieveRequest rebuilds the form from the handler's Registry (or
+// DefaultRegistry), so forms must be registered under their Name to be
+// restored.
+//
+// This is a degraded mode. A restored form has the declaration's values,
+// not any set after it was built, and its token cannot be removed once it
+// has been used, so it can be submitted again until it expires.
+type CookieFallback struct {
+	// Cookie prefixes the names of the cookies. If it is empty, "form-" is
+	// used.
+	Cookie string
+	// Path, Domain and Secure set the cookies' attributes. Cookies are
+	// always HttpOnly and SameSite=Lax.
+	Path, Domain string
+	Secure       bool
+
+	aead cipher.AEAD
+}
+
+// NewCookieFallback creates a CookieFallback that encrypts its cookies
+// with the key, which must be 16, 24, or 32 bytes long.
+func NewCookieFallback(key []byte) (*CookieFallback, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &CookieFallback{aead: aead}, nil
+}
+
+// fallbackRecord is the content of a fallback cookie.
+type fallbackRecord struct {
+	Name      string `json:"n"`
+	Token     string `json:"t"`
+	Namespace string `json:"s,omitempty"`
+	Expires   int64  `json:"e"`
+}
+
+// cookieName returns the name of the cookie of a form with the token.
+func (c *CookieFallback) cookieName(tok string) string {
+	prefix := c.Cookie
+	if len(prefix) == 0 {
+		prefix = "form-"
+	}
+	if len(tok) > 16 {
+		tok = tok[:16]
+	}
+	return prefix + tok
+}
+
+// seal encrypts a record for the cookie with the name, with a nonce read
+// from r.
+func (c *CookieFallback) seal(r io.Reader, name string, rec fallbackRecord) (string, error) {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(r, nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, b, []byte(name))), nil
+}
+
+func (c *CookieFallback) open(name, v string) (*fallbackRecord, error) {
+	b, err := base64.RawURLEncoding.DecodeString(v)
+	n := c.aead.NonceSize()
+	if err != nil || len(b) < n {
+		return nil, ErrDecrypt
+	}
+	p, err := c.aead.Open(nil, b[:n], b[n:], []byte(name))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	rec := &fallbackRecord{}
+	if err := json.Unmarshal(p, rec); err != nil {
+		return nil, ErrDecrypt
+	}
+	return rec, nil
+}
+
+// restore rebuilds the form with the token from the request's fallback
+// cookie.
+func (c *CookieFallback) restore(h *FormHandler, r *http.Request, tok string) (*Form, error) {
+	name := c.cookieName(tok)
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return nil, ErrFormNotFound
+	}
+	rec, err := c.open(name, cookie.Value)
+	if err != nil || rec.Token != tok || rec.Namespace != h.Namespace || h.now().Unix() >= rec.Expires {
+		return nil, ErrFormNotFound
+	}
+
+	reg := h.Registry
+	if reg == nil {
+		reg = DefaultRegistry
+	}
+	form, err := reg.Build(r.Context(), rec.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := form.Transition(Prepared); err != nil {
+		return nil, err
+	}
+	h.setUp(form)
+	form.setToken(tok)
+	return form, nil
+}
+
+// PrepareResponse prepares a form, like Prepare, and falls back to a
+// cookie if the form cannot be cached.
+//
+// If the handler has a Fallback, and the form was prepared but the cache
+// failed to store it, the form's record is set as a cookie on w (see
+// CookieFallback), and the cache's error is not returned. The cookie must
+// be set before anything is written to w. If the record cannot be sealed
+// either, the form is left unprepared, as by Prepare.
+func (f *FormHandler) PrepareResponse(w http.ResponseWriter, form *Form) (string, error) {
+	if f.Fallback == nil {
+		return f.Prepare(form)
+	}
+	return f.prepare(form, func(form *Form) error {
+		return f.setFallback(w, form)
+	})
+}
+
+// setFallback sets the record of a prepared form as a fallback cookie.
+func (f *FormHandler) setFallback(w http.ResponseWriter, form *Form) error {
+	expires := f.now().Add(f.expiration(form))
+	c := f.Fallback
+	name := c.cookieName(form.token)
+	v, err := c.seal(f.rand(), name, fallbackRecord{
+		Name:      form.Name,
+		Token:     form.token,
+		Namespace: f.Namespace,
+		Expires:   expires.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    v,
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Expires:  expires,
+		Secure:   c.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
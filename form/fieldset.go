@@ -1,5 +1,10 @@
 package form
 
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
 // FieldSet describes a set of form fields.
 type FieldSet struct {
 	HTML
@@ -19,3 +24,40 @@ type Div struct {
 	HTML
 	Fields []Field
 }
+
+// Element retrieves the field set as an html.Node of type ElementNode.
+//
+// If a Legend is set, it is rendered as the first child.
+func (f *FieldSet) Element() *html.Node {
+	return f.RenderElement(nil)
+}
+
+// RenderElement retrieves the field set, passing the context to its fields.
+func (f *FieldSet) RenderElement(ctx *RenderContext) *html.Node {
+	s := ctx.nodes()
+	n := s.node(html.ElementNode, atom.Fieldset, "fieldset")
+	n.Attr = structToAttrs(f, "Form", "Name")
+	n.Attr = append(n.Attr, boolAttrs(f, "Disabled")...)
+	f.HTML.Attach(n)
+
+	if len(f.Legend) > 0 {
+		l := s.node(html.ElementNode, atom.Legend, "legend")
+		l.AppendChild(s.node(html.TextNode, 0, f.Legend))
+		n.AppendChild(l)
+	}
+	appendElements(ctx, n, f.Fields)
+	return n
+}
+
+// Element retrieves the div as an html.Node of type ElementNode.
+func (d *Div) Element() *html.Node {
+	return d.RenderElement(nil)
+}
+
+// RenderElement retrieves the div, passing the context to its fields.
+func (d *Div) RenderElement(ctx *RenderContext) *html.Node {
+	n := ctx.nodes().node(html.ElementNode, atom.Div, "div")
+	d.HTML.Attach(n)
+	appendElements(ctx, n, d.Fields)
+	return n
+}
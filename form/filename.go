@@ -0,0 +1,113 @@
+package form
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ExtensionError indicates that an uploaded file's extension is not in the
+// allowed list of a FilenamePolicy.
+type ExtensionError struct {
+	Filename string
+	Allowed  []string
+}
+
+func (e *ExtensionError) Error() string {
+	return fmt.Sprintf("File %q must have one of the extensions %s", e.Filename, strings.Join(e.Allowed, ", "))
+}
+
+// FilenamePolicy makes the names of uploaded files safe to use.
+//
+// Filenames are chosen by users, so they may contain directories (to
+// escape an upload directory), control or bidirectional characters (to
+// disguise an extension), or names that file systems reserve. A safe name
+// has none of these, and can be used in a path or a Content-Disposition
+// header.
+type FilenamePolicy struct {
+	// Extensions lists the allowed extensions, with the dot, such as
+	// ".png". They are matched without regard to case. If it is empty, any
+	// extension is allowed.
+	Extensions []string
+	// MaxLength limits the length of a safe name in bytes. The extension is
+	// kept when a name is shortened. If it is 0, 255 is used.
+	MaxLength int
+	// Normalize, if set, is applied to names before anything else. Set it to
+	// norm.NFC.String (from golang.org/x/text/unicode/norm) so that names
+	// that look the same are the same.
+	Normalize func(string) string
+}
+
+// DefaultFilenamePolicy is used by RetrieveMultipart when the FormHandler
+// has no FilenamePolicy. It allows any extension.
+var DefaultFilenamePolicy = &FilenamePolicy{}
+
+// windowsReserved are the device names that Windows reserves, in any
+// directory and with any extension.
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// SafeName returns a safe version of a filename, or an *ExtensionError if
+// its extension is not allowed.
+//
+// Directories are removed, control and bidirectional formatting characters
+// are dropped, characters that file systems or headers reserve are
+// replaced with "_", and leading and trailing dots and spaces are trimmed.
+// A name that is empty after this becomes "file".
+func (p *FilenamePolicy) SafeName(name string) (string, error) {
+	if p.Normalize != nil {
+		name = p.Normalize(name)
+	}
+	name = strings.ToValidUTF8(name, "_")
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r), unicode.Is(unicode.Bidi_Control, r):
+			return -1
+		case strings.ContainsRune(`<>:"|?*;`, r):
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, ". ")
+	if len(name) == 0 {
+		name = "file"
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	if windowsReserved[strings.ToUpper(base)] {
+		base = "_" + base
+	}
+	max := p.MaxLength
+	if max <= 0 {
+		max = 255
+	}
+	if len(ext) >= max {
+		base, ext = base+ext, ""
+	}
+	for len(base)+len(ext) > max {
+		_, size := utf8.DecodeLastRuneInString(base)
+		base = base[:len(base)-size]
+	}
+	name = base + ext
+
+	if len(p.Extensions) > 0 {
+		for _, e := range p.Extensions {
+			if strings.EqualFold(e, ext) {
+				return name, nil
+			}
+		}
+		return name, &ExtensionError{Filename: name, Allowed: p.Extensions}
+	}
+	return name, nil
+}
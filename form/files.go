@@ -0,0 +1,122 @@
+package form
+
+import (
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// RetrieveFiles reads a multipart/form-data submission with
+// http.Request.ParseMultipartForm, and populates the cached form it belongs
+// to, like Retrieve.
+//
+// Unlike RetrieveMultipart, this does not read the files into the form.
+// Up to maxMemory bytes of them are kept in memory, and the rest are
+// written to temporary files, so large uploads can be copied to storage
+// without being held in memory. The files of each file field are given by
+// Files, as *multipart.FileHeaders. Call RemoveFiles when they are no
+// longer needed, to delete the temporary files:
+//
+//	f, err := h.RetrieveFiles(r, 10<<20)
+//	if f != nil {
+//		defer f.RemoveFiles()
+//	}
+//
+// A file whose name the handler's FilenamePolicy rejects, or that its
+// Scanner rejects, is left out, and the rejection is added to the form's
+// Errors for its field. Files that were not submitted for a field of the
+// form are left out too. As with Retrieve, the form is not validated, but
+// if a file was rejected, ErrInvalid is returned along with the form. If
+// the form cannot be retrieved, the temporary files are removed.
+func (f *FormHandler) RetrieveFiles(r *http.Request, maxMemory int64) (*Form, error) {
+	fm, err := f.retrieveFiles(r, maxMemory)
+	return f.published(r.Context(), fm, err)
+}
+
+func (f *FormHandler) retrieveFiles(r *http.Request, maxMemory int64) (*Form, error) {
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
+		return nil, err
+	}
+	mf := r.MultipartForm
+	data := url.Values(mf.Value)
+	fm, err := f.retrieveValues(&data)
+	if fm == nil {
+		mf.RemoveAll()
+		return nil, err
+	}
+	fm.uploads = mf
+	fm.headers = map[string][]*multipart.FileHeader{}
+
+	policy := f.Filenames
+	if policy == nil {
+		policy = DefaultFilenamePolicy
+	}
+	names := fm.submittedNames()
+	submitted := make([]string, 0, len(mf.File))
+	for name := range mf.File {
+		submitted = append(submitted, name)
+	}
+	sort.Strings(submitted)
+	for _, name := range submitted {
+		field, ok := names[name]
+		if !ok {
+			continue
+		}
+		for _, fh := range mf.File[name] {
+			safe, perr := policy.SafeName(fh.Filename)
+			if perr != nil {
+				fm.Errors.Add(field, perr.Error())
+				continue
+			}
+			rejected, serr := scanHeader(r, f.Scanner, safe, fh)
+			if serr != nil {
+				return fm, serr
+			}
+			if rejected != nil {
+				fm.Errors.Add(field, rejected.Error())
+				continue
+			}
+			fm.headers[field] = append(fm.headers[field], fh)
+		}
+	}
+	if err == nil && len(fm.Errors) > 0 {
+		err = ErrInvalid
+	}
+	return fm, err
+}
+
+// scanHeader scans an uploaded file with the Scanner, if there is one.
+func scanHeader(r *http.Request, s Scanner, filename string, fh *multipart.FileHeader) (*RejectedFileError, error) {
+	if s == nil {
+		return nil, nil
+	}
+	file, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	err = s.Scan(r.Context(), filename, file)
+	if rej, ok := err.(*RejectedFileError); ok {
+		return rej, nil
+	}
+	return nil, err
+}
+
+// Files returns the files submitted for the named file field, as read by
+// RetrieveFiles. The headers' Filenames are those the user gave; pass them
+// through a FilenamePolicy before using them in a path.
+func (f *Form) Files(name string) []*multipart.FileHeader {
+	return f.headers[name]
+}
+
+// RemoveFiles deletes the temporary files of a submission read by
+// RetrieveFiles. The form's Files cannot be opened afterward.
+func (f *Form) RemoveFiles() error {
+	if f.uploads == nil {
+		return nil
+	}
+	err := f.uploads.RemoveAll()
+	f.uploads, f.headers = nil, nil
+	return err
+}
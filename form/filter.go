@@ -0,0 +1,82 @@
+package form
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// FilterForm is a form for searching and filtering a list, submitted with
+// GET so that its state is in the URL.
+//
+// A filter form needs no security token: it changes nothing, and its URLs
+// are meant to be bookmarked and shared. Its values are read from a query
+// string with FromQuery, and written to one with Query, which leaves out
+// empty values so that the URLs stay short.
+type FilterForm struct {
+	*Form
+}
+
+// NewFilterForm creates a FilterForm with the method "get".
+func NewFilterForm(name, action string) *FilterForm {
+	return &FilterForm{&Form{Name: name, Action: action, Method: "get"}}
+}
+
+// FromQuery sets the form's values from a query string.
+//
+// Parameters that are not the names of the form's fields are ignored. If a
+// value is not valid for its field, such as a number that cannot be
+// parsed, the problem is added to the form's Errors and ErrInvalid is
+// returned.
+func (f *FilterForm) FromQuery(q url.Values) error {
+	Reconcile(f.Form, &q)
+	if len(f.Errors) > 0 {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// Query returns the form's values as a query string, leaving out empty
+// values.
+func (f *FilterForm) Query() url.Values {
+	q := url.Values{}
+	f.EachValue(func(name, value string) {
+		if len(value) > 0 && name != SecureTokenName && name != FormIDName {
+			q.Add(name, value)
+		}
+	})
+	return q
+}
+
+// URL returns the canonical URL of the form's state: the form's Action,
+// with the form's Query in place of any query string. The parameters are
+// sorted by name, so equal filters have equal URLs, which suits caches and
+// rel=canonical links.
+func (f *FilterForm) URL() string {
+	u, err := url.Parse(f.Action)
+	if err != nil {
+		u = &url.URL{Path: f.Action}
+	}
+	u.RawQuery = f.Query().Encode()
+	return u.String()
+}
+
+// Decode stores the form's values in the struct dst points to, for the
+// data layer. Values are stored as Bind stores them, except that an
+// unchecked checkbox sets its bool to false.
+func (f *FilterForm) Decode(dst interface{}) error {
+	return Bind(f.recordValues(), dst)
+}
+
+// Parse sets the form's values from the request's query string (see
+// FromQuery), and stores them in dst, if it is not nil (see Decode). It
+// returns the canonical URL of the filters (see URL); a handler may
+// redirect to it if it differs from the request's URL.
+func (f *FilterForm) Parse(r *http.Request, dst interface{}) (string, error) {
+	err := f.FromQuery(r.URL.Query())
+	if dst != nil {
+		if derr := f.Decode(dst); derr != nil && err == nil {
+			err = derr
+		}
+	}
+	return f.URL(), err
+}
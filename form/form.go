@@ -11,7 +11,8 @@ In this package, you will find:
 	- types for each form field type
 	- the FormHandler for automating form storage, submission, and retrieval
 	- secure tokens for mitigating XSS attacks
-	- a caching framework for storing form data (used by FormHandler)
+	- a caching framework for storing form data (used by FormHandler, and
+	  available to applications in the cache subpackage)
 
 Engine also provides templates for rendering a form into HTML. The expected
 form workflow goes something like this:
@@ -21,14 +22,19 @@ form workflow goes something like this:
 	3. Render the form to HTML and serve it to the client
 	4. On form submission, get the form values
 	5. Look up the form and populate it with the submitted values
-	6. Work with the returned form
+	6. Work with the returned form, for example by storing its values in a
+	   struct with Bind
 
 The example below illustrates how most of this is done by the library.
 */
 package form
 
 import (
+	"encoding/hex"
+	"io"
+	"mime/multipart"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -71,10 +77,14 @@ const (
 // correctness, here and elsewhere we rarely force a particular value to
 // conform to the spec. Typically, typing is as close as we get to
 // enforcement.
+//
+// Hidden only affects presentation: a hidden field is not displayed, but it
+// is still submitted and reconciled. Since hidden is a boolean attribute,
+// OFalse is the same as ONone. See Form.Hide and Form.Remove.
 type HTML struct {
 	Class                                                       []string
 	AccessKey, Id, Dir, Lang, Style, TabIndex, Title, Translate string
-	ContentEditable, Hidden                                     OptionalBool
+	ContentEditable, Hidden, Spellcheck                         OptionalBool
 	Role                                                        string
 
 	// Data stores arbitrary attributes, such as data-* fields. It is up to
@@ -86,49 +96,35 @@ type HTML struct {
 }
 
 // EnsureId ensures that an HTML has an ID attribute.
+//
+// If there is neither an ID nor a seed, a random ID is generated from Rand.
 func (g HTML) EnsureId(seed string) string {
 	if len(g.Id) > 0 {
 		return g.Id
 	} else if len(seed) > 0 {
 		return seed
 	}
-	// TODO: Should probably generate a random ID.
-	return ""
-}
-
-// Attache attaches these attributes to an html.Node.
-func (g HTML) Attach(node *html.Node) {
-	attrs := []html.Attribute{}
-	if g.ContentEditable > 0 {
-		if g.ContentEditable == OTrue {
-			attrs = attr(attrs, "contenteditable", "true")
-		} else {
-			attrs = attr(attrs, "contenteditable", "false")
-		}
-	}
-	if g.Hidden > 0 {
-		if g.Hidden == OTrue {
-			attrs = attr(attrs, "hidden", "true")
-		} else {
-			attrs = attr(attrs, "hidden", "false")
-		}
-	}
-
-	if len(g.Data) > 0 {
-		for k, v := range g.Data {
-			attrs = attr(attrs, k, v)
-		}
+	id, err := RandomId(Rand)
+	if err != nil {
+		return ""
 	}
+	return id
+}
 
-	if len(g.Class) > 0 {
-		v := strings.Join(g.Class, " ")
-		attrs = attr(attrs, "class", v)
+// RandomId generates an ID from r.
+//
+// The ID is "id-" followed by sixteen hexadecimal digits.
+func RandomId(r io.Reader) (string, error) {
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
 	}
+	return "id-" + hex.EncodeToString(b), nil
+}
 
-	s := []string{"AccessKey", "Id", "Dir", "Lang", "Style", "TabIndex", "Title", "Translate"}
-	attrs = append(attrs, structToAttrs(g, s...)...)
-
-	node.Attr = append(node.Attr, attrs...)
+// Attache attaches these attributes to an html.Node.
+func (g HTML) Attach(node *html.Node) {
+	node.Attr = g.appendAttrs(node.Attr)
 }
 
 // String is for PCData that can be arbitarily embeded in a []Field list.
@@ -147,30 +143,219 @@ func New(name, action string) *Form {
 // Then are typically rendered through the form templating system.
 type Form struct {
 	HTML
-	AcceptCharset, Enctype, Action, Method, Name, Target string
-	Autocomplete, Novalidate                             bool
-	Fields                                               []Field
+	Enctype, Action, Method, Name, Target string
+	Novalidate                            bool
+	Fields                                []Field
+
+	// AcceptCharset lists the character encodings the server accepts for
+	// submissions. See the Charset constants and CheckAcceptCharset.
+	AcceptCharset []string
+
+	// Rel lists the link types of the form's rel attribute. See
+	// Relationship.
+	Rel []string
+
+	// AutoTabIndex numbers the form's fields when it is rendered. See
+	// AssignTabIndex.
+	AutoTabIndex bool
+
+	// AutoID gives IDs to the form's fields, labels, and error lists when it
+	// is prepared or rendered. See AssignIDs.
+	AutoID bool
+
+	// Autocomplete is AutocompleteOn or AutocompleteOff. If it is empty,
+	// the user agent's default (on) is used.
+	Autocomplete string
+
+	// External fields belong to the form, but are rendered outside of the
+	// form element. They are associated with the form using the HTML5 form
+	// attribute. See AddExternal.
+	External []Field
+
+	// Errors holds error messages for the form's fields.
+	Errors Errors
+
+	// Prefix is applied to the names and IDs of all fields when the form
+	// is rendered, converted to values, or reconciled. This allows several
+	// instances of the same form to appear on one page. The prefix is
+	// applied with PrefixFunc, or DefaultPrefixFunc if that is nil.
+	Prefix     string
+	PrefixFunc PrefixFunc
+
+	// Defaults are class names and data attributes given to all of the
+	// form's fields. See ApplyDefaults.
+	Defaults FieldDefaults
+
+	// UncheckedValue, if set, is the value AsValues gives checkboxes that
+	// are not checked (for example, "0"). Systems that expect a key for
+	// every field can use this in place of a hidden field.
+	UncheckedValue string
+
+	// NamePolicy is applied to the names of fields passed to Add and
+	// AddExternal. If it is nil, duplicate names are allowed.
+	NamePolicy NamePolicy
+
+	// Echo sets how the values of the named fields are rendered. By default,
+	// passwords are never rendered, and other values are. See EchoPolicy.
+	Echo map[string]EchoPolicy
+
+	// Sensitive lists the names of fields whose values are sensitive, such
+	// as social security numbers or dates of birth. See EncryptSensitive
+	// and MaskedValues.
+	Sensitive []string
+
+	// Security, if set, is the form's SecurityPolicy, in place of the
+	// FormHandler's.
+	Security *SecurityPolicy
+
+	state State
+	token string
+	files map[string][]Attachment
+	// uploadKeys holds the keys of the objects a DirectUpload issued for
+	// each File field.
+	uploadKeys map[string][]string
+	// carried holds the names of the query parameters the form carries.
+	// See CarryQuery.
+	carried []string
+	// versionSigned is true once the value of the VersionName field has
+	// been signed.
+	versionSigned bool
+
+	// uploads holds the files of a submission read by RetrieveFiles, and
+	// headers those of its files that were accepted.
+	uploads *multipart.Form
+	headers map[string][]*multipart.FileHeader
+
+	validators map[string][]Validator
+	onChange   map[string][]ChangeFunc
+
+	// initial holds the values the form had when it was first reconciled.
+	initial *url.Values
+}
+
+// Errors maps field names to error messages.
+type Errors map[string][]string
+
+// Add adds an error message for the named field.
+func (e *Errors) Add(name, msg string) {
+	if *e == nil {
+		*e = Errors{}
+	}
+	(*e)[name] = append((*e)[name], msg)
+}
+
+// Get returns the error messages for the named field.
+func (e Errors) Get(name string) []string {
+	return e[name]
 }
 
 // Add adds any number of fields to a form.
+//
+// Field names are checked against the form's NamePolicy. If the policy
+// rejects a name, Add panics with the policy's error (such as a
+// *DuplicateNameError), since duplicate names are a programming error.
 func (f *Form) Add(field ...Field) *Form {
+	if err := f.checkNames(field); err != nil {
+		panic(err)
+	}
 	f.Fields = append(f.Fields, field...)
 	return f
 }
 
+// AddExternal adds fields that are rendered outside of the form element.
+//
+// The Form attribute of each field is set to the form's ID (ensuring that
+// the form has one), so the user agent will submit the field with this form.
+// External fields are included in AsValues and in reconciliation. Names are
+// checked as they are by Add.
+func (f *Form) AddExternal(field ...Field) *Form {
+	if err := f.checkNames(field); err != nil {
+		panic(err)
+	}
+	f.HTML.Id = f.HTML.EnsureId(f.Name)
+	for _, ff := range field {
+		setFormAttr(ff, f.HTML.Id)
+	}
+	f.External = append(f.External, field...)
+	return f
+}
+
+// Field returns the first field with the given name, or nil if there is none.
+//
+// Nested fields and external fields are searched as well.
+func (f *Form) Field(name string) Field {
+	var found Field
+	walkFields(f.allFields(), func(field Field) {
+		if found == nil && nameOf(field) == name {
+			found = field
+		}
+	})
+	return found
+}
+
+// allFields returns the fields and the external fields of a form.
+func (f *Form) allFields() []Field {
+	if len(f.External) == 0 {
+		return f.Fields
+	}
+	all := make([]Field, 0, len(f.Fields)+len(f.External))
+	return append(append(all, f.Fields...), f.External...)
+}
+
 // Element retrieves the form as an html.Node of type ElementNode.
+//
+// The form's Fields are attached as children. External fields are not.
 func (f *Form) Element() *html.Node {
+	return f.RenderElement(nil)
+}
+
+// RenderElement retrieves the form, passing the context to its fields.
+func (f *Form) RenderElement(ctx *RenderContext) *html.Node {
 	n := &html.Node{
 		Type:     html.ElementNode,
 		DataAtom: atom.Form,
 		Data:     "form",
 	}
 
-	n.Attr = structToAttrs(f, "AcceptCharset", "Enctype", "Action", "Method", "Name", "Target")
+	n.Attr = structToAttrs(f, "Enctype", "Action", "Autocomplete", "Method", "Name", "Target")
+	if len(f.AcceptCharset) > 0 {
+		n.Attr = attr(n.Attr, "accept-charset", strings.Join(f.AcceptCharset, " "))
+	}
+	if rel := f.Relationship(); len(rel) > 0 {
+		n.Attr = attr(n.Attr, "rel", rel)
+	}
+	n.Attr = append(n.Attr, boolAttrs(f, "Novalidate")...)
 
+	if f.AutoID {
+		f.AssignIDs()
+	}
 	// We want to at least try to set an ID.
 	f.HTML.Id = f.HTML.EnsureId(f.Name)
 	f.HTML.Attach(n)
+	if l := ctx.locale(); len(l) > 0 && len(f.Lang) == 0 {
+		n.Attr = attr(n.Attr, "lang", l)
+	}
+
+	f.ResolveLabels()
+	f.ResolveInheritance()
+	f.ApplyDefaults()
+	f.ApplyConstraints()
+	if f.AutoTabIndex {
+		f.AssignTabIndex(1)
+	}
+	appendElements(ctx, n, f.Fields)
+	f.applyEcho(n)
+	if f.AutoID {
+		labelIDs(n)
+	}
+	if len(f.Prefix) > 0 {
+		// The form's name identifies its definition, so only its ID is
+		// prefixed.
+		setAttr(n, "id", f.prefixed(f.HTML.Id))
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f.prefixNode(c)
+		}
+	}
 
 	return n
 }
@@ -185,71 +370,242 @@ func (f *Form) Element() *html.Node {
 // For fields that commonly can have multiple values (Select, Checkbox),
 // values are appended. For elements that do not admit multiple values
 // (Text, Radio, TextArea, etc), only one value is set.
+//
+// Unchecked checkboxes are omitted, as they are when a form is submitted.
+// If the form has an UncheckedValue, it is set for each checkbox name that
+// has no checked boxes instead, so that every checkbox has a key.
+//
+// If the form has a Prefix, it is applied to the names.
 func (f *Form) AsValues() *url.Values {
+	return f.prefixValues(f.values())
+}
+
+// SetValues sets the form's values from v, as the inverse of AsValues.
+//
+// Like the values AsValues returns, the names in v have the form's
+// Prefix. Each field named in v is given its value, without the checks
+// that Reconcile makes, so setting a form's values to those of another
+// makes the forms' values equal. Checkboxes, radio buttons, and the
+// options of selects are checked or selected exactly when their values are
+// among those of their names, so they are cleared if their names are not
+// in v; other fields that are not named in v are left as they are.
+// Computed fields are recalculated afterward.
+func (f *Form) SetValues(v url.Values) {
+	f.setValues(*f.unprefixValues(&v))
+	f.Compute()
+}
+
+func (f *Form) setValues(data url.Values) {
+	has := func(name, value string) bool {
+		for _, v := range data[name] {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	}
+	walkFields(f.allFields(), func(field Field) {
+		switch field := field.(type) {
+		case *Form:
+			field.setValues(*field.unprefixValues(&data))
+		case *Checkbox:
+			field.Checked = has(field.Name, field.Value)
+		case *Radio:
+			field.Checked = has(field.Name, field.Value)
+		case *Select:
+			field.eachOption(func(o *Option) {
+				o.Selected = has(field.Name, o.Value)
+			})
+		case *Money:
+			if err := field.reconcile(data.Get(field.Name), data.Get(field.CurrencyName())); err != nil {
+				f.Errors.Add(field.Name, err.Error())
+			}
+		case *Duration:
+			if err := field.reconcile(data.Get(field.Name), data.Get(field.UnitName())); err != nil {
+				f.Errors.Add(field.Name, err.Error())
+			}
+		case *Computed:
+			// Computed values are recalculated by Compute.
+		default:
+			name := nameOf(field)
+			if vv, ok := data[name]; ok && len(name) > 0 && len(vv) > 0 {
+				setStringField(field, "Value", vv[0])
+			}
+			if dn := stringField(field, "Dirname"); len(dn) > 0 && len(data[dn]) > 0 {
+				setStringField(field, "Dir", data[dn][0])
+			}
+		}
+	})
+}
+
+// values returns the form's values without the Prefix applied.
+func (f *Form) values() *url.Values {
 	v := &url.Values{}
-	asValues(f.Fields, v)
+	f.eachValue(func(name, value string, multi bool) {
+		if multi {
+			v.Add(name, value)
+		} else {
+			v.Set(name, value)
+		}
+	})
 	return v
 }
 
-func asValues(fields []Field, vals *url.Values) {
+// Value is a name/value pair of a form.
+type Value struct {
+	Name, Value string
+}
+
+// EachValue calls fn with each name/value pair of the form, in order.
+//
+// The pairs are those of AsValues, and the form's Prefix is applied to the
+// names. Unlike AsValues, this does not build a map, so services that
+// process many submissions can read values without the allocations. If
+// several fields that hold a single value share a name, each is visited,
+// whereas AsValues keeps only the last.
+func (f *Form) EachValue(fn func(name, value string)) {
+	f.eachValue(func(name, value string, multi bool) {
+		fn(f.prefixed(name), value)
+	})
+}
+
+// AppendValues appends the name/value pairs of the form to vals.
+//
+// Passing the slice returned by a previous call, truncated to zero length,
+// reuses its storage. See EachValue.
+func (f *Form) AppendValues(vals []Value) []Value {
+	f.EachValue(func(name, value string) {
+		vals = append(vals, Value{Name: name, Value: value})
+	})
+	return vals
+}
+
+// eachValue calls fn with each name/value pair of the form, without the
+// Prefix applied. Pairs that AsValues adds to a name's values have multi
+// set; the others replace them.
+func (f *Form) eachValue(fn func(name, value string, multi bool)) {
+	if len(f.UncheckedValue) == 0 {
+		eachValue(f.allFields(), fn)
+		return
+	}
+	seen := map[string]bool{}
+	eachValue(f.allFields(), func(name, value string, multi bool) {
+		seen[name] = true
+		fn(name, value, multi)
+	})
+	walkFields(f.allFields(), func(field Field) {
+		if c, ok := field.(*Checkbox); ok && len(c.Name) > 0 && !seen[c.Name] {
+			seen[c.Name] = true
+			fn(c.Name, f.UncheckedValue, false)
+		}
+	})
+}
+
+func eachValue(fields []Field, fn func(name, value string, multi bool)) {
 	for _, field := range fields {
+		if isNil(field) {
+			continue
+		}
 		switch field := field.(type) {
 		case *Div:
-			asValues(field.Fields, vals)
+			eachValue(field.Fields, fn)
 		case *FieldSet:
-			asValues(field.Fields, vals)
+			eachValue(field.Fields, fn)
+		case *Lazy:
+			eachValue(field.Resolve(), fn)
+		case *Label:
+			eachValue(field.Fields, fn)
+		case *Table:
+			eachValue(field.Fields, fn)
+		case *Form:
+			field.EachValue(func(name, value string) {
+				fn(name, value, true)
+			})
 		case *Checkbox:
 			if field.Checked {
-				vals.Add(field.Name, field.Value)
+				fn(field.Name, field.Value, true)
 			}
 		case *Radio:
 			if field.Checked {
-				vals.Add(field.Name, field.Value)
+				fn(field.Name, field.Value, true)
 			}
 		case *Select:
-			for _, o := range field.Options {
-				if o, ok := o.(*OptGroup); ok {
-					for _, oo := range o.Options {
-						vals.Add(field.Name, oo.Value)
-					}
-					continue
+			field.eachOption(func(o *Option) {
+				if o.Selected {
+					fn(field.Name, o.Value, true)
 				}
-				if o, ok := o.(*Option); ok && o.Selected {
-					vals.Add(field.Name, o.Value)
-				}
-			}
+			})
 		case *Text:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
+			if field.Dirname != "" && field.Dir != "" {
+				fn(field.Dirname, field.Dir, false)
+			}
 		case *Password:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
 		case *Submit:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
 		case *Tel:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
 		case *URL:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
 		case *Email:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
 		case *Date:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
 		case *Time:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
+		case *Month:
+			fn(field.Name, field.Value, false)
+		case *Week:
+			fn(field.Name, field.Value, false)
+		case *DatetimeLocal:
+			fn(field.Name, field.Value, false)
+		case *Search:
+			fn(field.Name, field.Value, false)
+			if field.Dirname != "" && field.Dir != "" {
+				fn(field.Dirname, field.Dir, false)
+			}
 		case *Number:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
 		case *Range:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
 		case *Color:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
+		case *AlphaColor:
+			fn(field.Name, field.Value, false)
 		case *Image:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
+			if field.Coords != nil {
+				x, y := coordNames(field.Name)
+				fn(x, strconv.Itoa(field.Coords.X), false)
+				fn(y, strconv.Itoa(field.Coords.Y), false)
+			}
 		case *Button:
-			vals.Set(field.Name, field.Value)
+			// Reset buttons are never submitted.
+			if len(field.Name) > 0 && buttonType(field.Type) != ButtonReset {
+				fn(field.Name, field.Value, false)
+			}
 		case *ButtonInput:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
 		case *Hidden:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
 		case *TextArea:
-			vals.Set(field.Name, field.Value)
+			fn(field.Name, field.Value, false)
+			if field.Dirname != "" && field.Dir != "" {
+				fn(field.Dirname, field.Dir, false)
+			}
+		case *Computed:
+			fn(field.Name, field.Value, false)
+		case *Money:
+			fn(field.Name, field.Value(), false)
+			fn(field.CurrencyName(), field.Currency, false)
+		case *Duration:
+			fn(field.Name, field.Count(), false)
+			for _, o := range field.UnitOptions() {
+				if o.Selected {
+					fn(field.UnitName(), o.Value, false)
+				}
+			}
 		}
 	}
 }
@@ -1,9 +1,13 @@
 package form
 
 import (
+	"context"
 	"errors"
+	"io"
 	"net/url"
 	"time"
+
+	"github.com/Masterminds/engine/form/cache"
 )
 
 // ErrNoToken indicates that provided form data has no security token.
@@ -17,10 +21,47 @@ var DefaultFormHandler = NewFormHandler(NewCache(), time.Hour*24)
 //
 // FormHandler enforces security constraints on forms, and will modify
 // forms in place.
+//
+// When one deployment serves several sites, set Namespace (or use
+// WithContext) so that each site's forms are cached separately. A token
+// issued under one namespace cannot retrieve a form from another.
 type FormHandler struct {
 	cache Cache
 	// The duration a form will be kept before it expires.
 	Expiration time.Duration
+	// Namespace qualifies the cache keys of the handler's forms.
+	Namespace string
+	// Clock, if set, is used in place of the system clock to compute
+	// expiration times.
+	Clock cache.Clock
+	// Rand, if set, is used in place of the package's Rand to generate
+	// security tokens.
+	Rand io.Reader
+	// Registry, if set, is used in place of DefaultRegistry by Build.
+	Registry *Registry
+	// Scanner, if set, checks files uploaded with RetrieveMultipart or
+	// RetrieveFiles before they are attached to the form.
+	Scanner Scanner
+	// Filenames, if set, is used in place of DefaultFilenamePolicy to make
+	// the names of files uploaded with RetrieveMultipart safe, and to
+	// check those uploaded with RetrieveFiles.
+	Filenames *FilenamePolicy
+	// Security, if set, is the SecurityPolicy of forms that have none of
+	// their own.
+	Security *SecurityPolicy
+	// Fallback, if set, carries prepared forms in cookies while the cache
+	// is failing. See PrepareResponse.
+	Fallback *CookieFallback
+	// VersionKey, if set, signs the versions of the records that forms
+	// edit (see Form.SetVersion). Handlers that share a cache should share
+	// a key; otherwise, each handler signs with a random key of its own.
+	VersionKey []byte
+	// Events, if set, receives the events of the handler's forms: when
+	// they are prepared, and when their submissions are retrieved or fail.
+	Events Events
+
+	limiter    *rateLimiter
+	versionKey []byte
 }
 
 // NewFormHandler creates a new FormHandler.
@@ -28,7 +69,45 @@ func NewFormHandler(c Cache, expiration time.Duration) *FormHandler {
 	return &FormHandler{
 		cache:      c,
 		Expiration: expiration,
+		limiter:    newRateLimiter(),
+		versionKey: newVersionKey(),
+	}
+}
+
+// WithContext returns a copy of the handler for the context's namespace.
+//
+// If the context does not carry a namespace (see WithNamespace), the
+// handler itself is returned. Otherwise, the context's namespace replaces
+// the handler's Namespace.
+func (f *FormHandler) WithContext(ctx context.Context) *FormHandler {
+	ns := Namespace(ctx)
+	if len(ns) == 0 {
+		return f
+	}
+	h := *f
+	h.Namespace = ns
+	return &h
+}
+
+// now returns the time of the handler's clock.
+func (f *FormHandler) now() time.Time {
+	if f.Clock != nil {
+		return f.Clock.Now()
+	}
+	return cache.SystemClock.Now()
+}
+
+// rand returns the handler's source of randomness.
+func (f *FormHandler) rand() io.Reader {
+	if f.Rand != nil {
+		return f.Rand
 	}
+	return Rand
+}
+
+// key returns the cache key for a form ID.
+func (f *FormHandler) key(id string) string {
+	return cache.NamespaceKey(f.Namespace, id)
 }
 
 // Prepare modifies the form for caching and security, then inserts it into cache.
@@ -37,12 +116,108 @@ func NewFormHandler(c Cache, expiration time.Duration) *FormHandler {
 // generated ID will be returned. And the form will be placed into the cache.
 //
 // This form can later be retrieved using the returned ID.
+//
+// Prepare is idempotent: preparing a form that is already prepared (or
+// rendered) returns the existing ID. Preparing a form that has already been
+// submitted returns a *StateError. A form with an unknown AcceptCharset is
+// not prepared, and a *CharsetError is returned. If the form cannot be
+// stored in the cache, the cache's error is returned, and the form is left
+// in the state it had, without a security field, so it can be prepared
+// again.
 func (f *FormHandler) Prepare(form *Form) (string, error) {
-	sf := SecurityField()
-	form.Fields = append(form.Fields, sf)
-	f.cache.Set(sf.Value, form, time.Now().Add(f.Expiration))
+	return f.prepare(form, nil)
+}
+
+// prepare prepares a form. If the cache fails to store it, and fallback is
+// not nil, the prepared form is passed to fallback instead; only if that
+// fails too is the form rolled back, and the cache's error returned.
+func (f *FormHandler) prepare(form *Form, fallback func(*Form) error) (string, error) {
+	switch form.State() {
+	case Prepared, Rendered:
+		return form.token, nil
+	}
+	if err := form.CheckAcceptCharset(); err != nil {
+		return "", err
+	}
+	tok, err := SecurityTokenFrom(f.rand())
+	if err != nil {
+		return "", err
+	}
+	prev := form.state
+	if err := form.Transition(Prepared); err != nil {
+		return "", err
+	}
+
+	f.setUp(form)
+	form.setToken(tok)
+	if err := f.cache.Set(f.key(tok), form, f.now().Add(f.expiration(form))); err != nil {
+		if fallback == nil || fallback(form) != nil {
+			form.unsetToken(prev)
+			return "", err
+		}
+	}
+	f.publish(context.Background(), EventPrepared, form, nil)
 
-	return sf.Value, nil
+	return tok, nil
+}
+
+// setUp readies the fields of a form that is being prepared.
+func (f *FormHandler) setUp(form *Form) {
+	form.Compute()
+	if form.AutoID {
+		form.AssignIDs()
+	}
+	form.ResolveLabels()
+	form.ResolveInheritance()
+	form.ApplyDefaults()
+	form.ApplyConstraints()
+	if p := f.policy(form); p != nil {
+		p.honeypot(form)
+	}
+	f.signVersion(form)
+}
+
+// expiration returns how long a prepared form is kept.
+func (f *FormHandler) expiration(form *Form) time.Duration {
+	if p := f.policy(form); p != nil && p.TokenTTL > 0 {
+		return p.TokenTTL
+	}
+	return f.Expiration
+}
+
+// setToken adds the security token to a form.
+func (f *Form) setToken(tok string) {
+	f.Fields = append(f.Fields, &Hidden{Name: SecureTokenName, Value: tok})
+	f.token = tok
+}
+
+// unsetToken undoes setToken, and returns the form to the state it had
+// before it was prepared.
+func (f *Form) unsetToken(prev State) {
+	var removed Field
+	f.Fields = removeField(f.Fields, SecureTokenName, &removed)
+	f.token = ""
+	f.state = prev
+}
+
+// Build builds a registered form, then prepares it.
+//
+// The form is built by the handler's Registry, or by DefaultRegistry. The
+// prepared form and its ID are returned.
+func (f *FormHandler) Build(ctx context.Context, name string) (*Form, string, error) {
+	r := f.Registry
+	if r == nil {
+		r = DefaultRegistry
+	}
+	form, err := r.Build(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+	id, err := f.Prepare(form)
+	if err != nil {
+		return nil, "", err
+	}
+	return form, id, nil
 }
 
 // Retrieve uses a request's key/value pairs to populate a cached form.
@@ -54,12 +229,21 @@ func (f *FormHandler) Prepare(form *Form) (string, error) {
 // value, that will remain in effect).
 //
 // Finally, Retrieve will remove the form from the cache, since a form
-// cannot be re-used.
+// cannot be re-used. The form is moved to the Submitted state; if it has
+// not been prepared, or has already been submitted, a *StateError is
+// returned.
 //
 // The implementing function must pass in the appropriate set of values.
 // The "net/http" library makes Get, Post, Put, and Patch variables all
 // available as *url.Values.
 func (f *FormHandler) Retrieve(data *url.Values) (*Form, error) {
+	fm, err := f.retrieveValues(data)
+	return f.published(context.Background(), fm, err)
+}
+
+// retrieveValues retrieves a form, as Retrieve does, without publishing
+// the result.
+func (f *FormHandler) retrieveValues(data *url.Values) (*Form, error) {
 	id := data.Get(SecureTokenName)
 	if id == "" {
 		return nil, ErrNoToken
@@ -69,6 +253,15 @@ func (f *FormHandler) Retrieve(data *url.Values) (*Form, error) {
 	if err != nil {
 		return nil, err
 	}
+	return f.retrieve(fm, id, data)
+}
+
+// retrieve reconciles the data into a cached form, and removes it from the
+// cache.
+func (f *FormHandler) retrieve(fm *Form, id string, data *url.Values) (*Form, error) {
+	if err := fm.Transition(Submitted); err != nil {
+		return nil, err
+	}
 
 	if err := Reconcile(fm, data); err != nil {
 		// Form might still be useful in this case.
@@ -80,24 +273,55 @@ func (f *FormHandler) Retrieve(data *url.Values) (*Form, error) {
 }
 
 func (f *FormHandler) Get(id string) (*Form, error) {
-	return f.cache.Get(id)
+	return f.cache.Get(f.key(id))
+}
+
+// prepared returns the cached form with the ID, if it has been prepared
+// and not yet submitted. Otherwise, ErrFormNotFound is returned. Handlers
+// that take a token without retrieving its form use this, so that only a
+// form waiting to be submitted can be reached with a token.
+func (f *FormHandler) prepared(id string) (*Form, error) {
+	form, err := f.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	switch form.State() {
+	case Prepared, Rendered:
+		return form, nil
+	}
+	return nil, ErrFormNotFound
 }
 
 func (f *FormHandler) Remove(id string) error {
-	return f.cache.Remove(id)
+	return f.cache.Remove(f.key(id))
 }
 
 // Reconcile modifies a form in place, merging the data into the form's Value fields.
 //
-// Validation is not handled by the reconciler.
+// If the form has a Prefix, the submitted names are expected to have it.
+//
+// Validation is not handled by the reconciler. Computed fields are
+// recalculated after the data is merged, so submitted values for those
+// fields are ignored. The values the form had before it was first
+// reconciled are kept, so that Changed can report what was changed.
 //
 // Normally, reconciliation will happen via the FormHandler's Retrieve method.
 func Reconcile(fm *Form, data *url.Values) error {
-	return reconcileFields(fm.Fields, data, fm)
+	if fm.initial == nil {
+		fm.initial = fm.values()
+	}
+	err := reconcileFields(fm.allFields(), fm.unprefixValues(data), fm)
+	fm.Compute()
+	return err
 }
 
 func reconcileFields(fields []Field, data *url.Values, fm *Form) error {
 	for _, field := range fields {
+		// Browsers never submit disabled fields, so a value for one was
+		// not entered by the user.
+		if isNil(field) || fm.echoed(field, data) || boolField(field, "Disabled") {
+			continue
+		}
 		// Because of the limitations on the type switch, we have to
 		// enumerate each type on its own line so that f is set correctly.
 		switch f := field.(type) {
@@ -105,6 +329,14 @@ func reconcileFields(fields []Field, data *url.Values, fm *Form) error {
 			reconcileFields(f.Fields, data, fm)
 		case *FieldSet:
 			reconcileFields(f.Fields, data, fm)
+		case *Lazy:
+			reconcileFields(f.Resolve(), data, fm)
+		case *Label:
+			reconcileFields(f.Fields, data, fm)
+		case *Table:
+			f.reconcile(data, fm)
+		case *Form:
+			Reconcile(f, data)
 		case *Select:
 			val := data.Get(f.Name)
 			for _, o := range f.Options {
@@ -141,6 +373,21 @@ func reconcileFields(fields []Field, data *url.Values, fm *Form) error {
 				}
 			}
 
+		case *Money:
+			if err := f.reconcile(data.Get(f.Name), data.Get(f.CurrencyName())); err != nil {
+				fm.Errors.Add(f.Name, err.Error())
+			}
+		case *Duration:
+			if err := f.reconcile(data.Get(f.Name), data.Get(f.UnitName())); err != nil {
+				fm.Errors.Add(f.Name, err.Error())
+			}
+		case *File:
+			// Only direct uploads submit a value: the key of the object.
+			if _, ok := f.Data["data-upload"]; ok {
+				if val := data.Get(f.Name); val != "" {
+					f.Value = val
+				}
+			}
 		case *Keygen:
 			if val := data.Get(f.Name); val != "" {
 				f.Value = val
@@ -149,25 +396,39 @@ func reconcileFields(fields []Field, data *url.Values, fm *Form) error {
 			if val := data.Get(f.Name); val != "" {
 				f.Value = val
 			}
+			reconcileDirname(f.Dirname, &f.HTML, data)
 		case *Text:
 			if val := data.Get(f.Name); val != "" {
 				f.Value = val
 			}
+			reconcileDirname(f.Dirname, &f.HTML, data)
 		case *Password:
 			if val := data.Get(f.Name); val != "" {
 				f.Value = val
 			}
 		case *Tel:
 			if val := data.Get(f.Name); val != "" {
-				f.Value = val
+				if tel, err := reconcileTel(f, val); err != nil {
+					fm.Errors.Add(f.Name, err.Error())
+				} else {
+					f.Value = tel
+				}
 			}
 		case *URL:
 			if val := data.Get(f.Name); val != "" {
-				f.Value = val
+				if u, err := NormalizeURL(val); err != nil {
+					fm.Errors.Add(f.Name, err.Error())
+				} else {
+					f.Value = u
+				}
 			}
 		case *Email:
 			if val := data.Get(f.Name); val != "" {
-				f.Value = val
+				if e, err := reconcileEmail(val, f.Multiple); err != nil {
+					fm.Errors.Add(f.Name, err.Error())
+				} else {
+					f.Value = e
+				}
 			}
 		case *Date:
 			if val := data.Get(f.Name); val != "" {
@@ -177,17 +438,50 @@ func reconcileFields(fields []Field, data *url.Values, fm *Form) error {
 			if val := data.Get(f.Name); val != "" {
 				f.Value = val
 			}
-		case *Number:
+		case *Month:
+			if val := data.Get(f.Name); val != "" {
+				f.Value = val
+			}
+		case *Week:
 			if val := data.Get(f.Name); val != "" {
 				f.Value = val
 			}
+		case *DatetimeLocal:
+			if val := data.Get(f.Name); val != "" {
+				f.Value = val
+			}
+		case *Search:
+			if val := data.Get(f.Name); val != "" {
+				f.Value = val
+			}
+			reconcileDirname(f.Dirname, &f.HTML, data)
+		case *Number:
+			if val := data.Get(f.Name); val != "" {
+				if n, err := reconcileNumber(f, val); err != nil {
+					fm.Errors.Add(f.Name, err.Error())
+				} else {
+					f.Value = n
+				}
+			}
 		case *Range:
 			if val := data.Get(f.Name); val != "" {
 				f.Value = val
 			}
 		case *Color:
 			if val := data.Get(f.Name); val != "" {
-				f.Value = val
+				if c, err := reconcileColor(val, false); err != nil {
+					fm.Errors.Add(f.Name, err.Error())
+				} else {
+					f.Value = c
+				}
+			}
+		case *AlphaColor:
+			if val := data.Get(f.Name); val != "" {
+				if c, err := reconcileColor(val, true); err != nil {
+					fm.Errors.Add(f.Name, err.Error())
+				} else {
+					f.Value = c
+				}
 			}
 		case *Hidden:
 			if val := data.Get(f.Name); val != "" {
@@ -206,12 +500,10 @@ func reconcileFields(fields []Field, data *url.Values, fm *Form) error {
 				f.Value = val
 			}
 		case *Image:
-			// TODO: User Agent should also pass .x and .y fields in
-			// the data.Values, where those indicate where on the image
-			// it was clicked.
 			if val := data.Get(f.Name); val != "" {
 				f.Value = val
 			}
+			reconcileCoords(f, data)
 		case *Input:
 			// Unlikely but possible case.
 			if val := data.Get(f.Name); val != "" {
@@ -227,6 +519,20 @@ func reconcileFields(fields []Field, data *url.Values, fm *Form) error {
 	return nil
 }
 
+// reconcileDirname sets the text direction submitted for a field's dirname.
+//
+// User agents submit the directionality of a field's text under the name
+// given in the field's dirname attribute.
+func reconcileDirname(dirname string, h *HTML, data *url.Values) {
+	if dirname == "" {
+		return
+	}
+	switch dir := data.Get(dirname); dir {
+	case LTR, RTL:
+		h.Dir = dir
+	}
+}
+
 func allFieldsNamed(name string, fm *Form) []Field {
 	return recursiveFieldsNamed(name, fm.Fields)
 }
@@ -249,3 +555,19 @@ func recursiveFieldsNamed(name string, ff []Field) []Field {
 	}
 	return found
 }
+
+type namespaceKey struct{}
+
+// WithNamespace returns a copy of the context carrying a cache namespace.
+//
+// Middleware that identifies the current site or tenant can use this, along
+// with FormHandler.WithContext, to keep each tenant's forms separate.
+func WithNamespace(ctx context.Context, ns string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, ns)
+}
+
+// Namespace returns the cache namespace carried by the context, or the empty string.
+func Namespace(ctx context.Context) string {
+	ns, _ := ctx.Value(namespaceKey{}).(string)
+	return ns
+}
@@ -1,8 +1,21 @@
 package form
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"golang.org/x/net/html"
 )
@@ -25,6 +38,262 @@ func TestForm(t *testing.T) {
 
 }
 
+func TestAcceptCharset(t *testing.T) {
+	f := New("test", "/test")
+	f.AcceptCharset = []string{CharsetUTF8, CharsetShiftJIS}
+	expectAttrs(t, f.Element(), map[string]string{"accept-charset": "UTF-8 Shift_JIS"})
+	if err := f.CheckAcceptCharset(); err != nil {
+		t.Errorf("Expected charsets to be valid: %s", err)
+	}
+
+	f.AcceptCharset = []string{"utf-8", "UTF8"}
+	if err, ok := f.CheckAcceptCharset().(*CharsetError); !ok || err.Charset != "UTF8" {
+		t.Errorf("Expected a CharsetError for UTF8, got %v", err)
+	}
+	if _, err := NewFormHandler(NewCache(), time.Minute).Prepare(f); err == nil {
+		t.Errorf("Expected Prepare to reject an unknown charset.")
+	}
+}
+
+func TestTarget(t *testing.T) {
+	f := New("test", "/test")
+	for _, bad := range []string{"", "_new", "_Blank_"} {
+		if err := f.SetTarget(bad); err != ErrInvalidTarget {
+			t.Errorf("Expected %q to be invalid, got %v", bad, err)
+		}
+	}
+	if err := f.SetTarget("preview"); err != nil || f.Target != "preview" {
+		t.Errorf("Expected a named target to be set, got %q, %v", f.Target, err)
+	}
+	if rel := f.Relationship(); rel != "" {
+		t.Errorf("Expected no rel, got %q", rel)
+	}
+
+	f.SetTarget(TargetBlank)
+	f.Rel = []string{"external"}
+	expectAttrs(t, f.Element(), map[string]string{"rel": "external noopener"})
+
+	f.Rel = []string{"opener"}
+	if rel := f.Relationship(); rel != "opener" {
+		t.Errorf("Expected opener to be kept, got %q", rel)
+	}
+}
+
+func TestAutocomplete(t *testing.T) {
+	f := New("signup", "/signup")
+	f.Autocomplete = AutocompleteOff
+	f.Novalidate = true
+	f.Add(&Password{Name: "pw", Autocomplete: AutocompleteNewPassword})
+
+	node := f.Element()
+	expectAttrs(t, node, map[string]string{
+		"autocomplete": "off",
+		"novalidate":   "novalidate",
+	})
+	expectAttrs(t, node.LastChild, map[string]string{
+		"name":         "pw",
+		"autocomplete": "new-password",
+	})
+}
+
+func TestOutput(t *testing.T) {
+	o := NewOutput("total", "price", "quantity")
+	o.Value = "42"
+
+	node := o.Element()
+	expectAttrs(t, node, map[string]string{
+		"name": "total",
+		"for":  "price quantity",
+	})
+	if node.FirstChild == nil || node.FirstChild.Data != "42" {
+		t.Errorf("Expected output value to be rendered as content.")
+	}
+}
+
+func TestLabel(t *testing.T) {
+	f := New("login", "/login")
+	f.Fields = []Field{
+		&Label{Field: "user", Text: "User ", Fields: []Field{String("*")}},
+		&Text{Name: "user"},
+	}
+	f.ResolveLabels()
+
+	l := f.Fields[0].(*Label)
+	if l.For != "user" {
+		t.Errorf("Expected label to resolve to 'user', got %q", l.For)
+	}
+	if id := f.Fields[1].(*Text).Id; id != "user" {
+		t.Errorf("Expected target field to get id 'user', got %q", id)
+	}
+
+	node := l.Element()
+	expectAttrs(t, node, map[string]string{"for": "user"})
+	if node.LastChild == nil || node.LastChild.Data != "*" {
+		t.Errorf("Expected label content to be rendered.")
+	}
+}
+
+func TestButton(t *testing.T) {
+	b := NewButton("go", "1")
+	b.Type = "RESET"
+	b.Fields = []Field{&Label{Text: "icon"}, String("Go")}
+
+	node := b.Element()
+	expectAttrs(t, node, map[string]string{
+		"type":  "reset",
+		"name":  "go",
+		"value": "1",
+	})
+	if node.FirstChild == nil || node.FirstChild.Data != "label" {
+		t.Errorf("Expected child element to be rendered.")
+	}
+
+	b.Type = "bogus"
+	expectAttrs(t, b.Element(), map[string]string{"type": "submit"})
+
+	b.Fields = nil
+	if node := b.Element(); node.FirstChild == nil || node.FirstChild.Data != "1" {
+		t.Errorf("Expected value to be used as button content.")
+	}
+}
+
+func TestFormNoValidate(t *testing.T) {
+	draft := &Submit{Name: "draft", Value: "Save draft", FormNoValidate: true}
+	expectAttrs(t, draft.Element(), map[string]string{
+		"type":           "submit",
+		"formnovalidate": "formnovalidate",
+	})
+
+	submit := &Submit{Name: "submit", Value: "Submit"}
+	for _, a := range submit.Element().Attr {
+		if a.Key == "formnovalidate" {
+			t.Errorf("Expected formnovalidate to be omitted.")
+		}
+	}
+
+	b := NewButton("draft", "Save draft")
+	b.FormNoValidate = true
+	expectAttrs(t, b.Element(), map[string]string{"formnovalidate": "formnovalidate"})
+}
+
+func TestReset(t *testing.T) {
+	expectAttrs(t, NewReset("Start over").Element(), map[string]string{
+		"type":  "reset",
+		"value": "Start over",
+	})
+
+	node := NewResetButton(String("Start "), String("over")).Element()
+	expectAttrs(t, node, map[string]string{"type": "reset"})
+	if node.FirstChild == nil || node.FirstChild.Data != "Start " {
+		t.Errorf("Expected reset button content to be rendered.")
+	}
+
+	f := New("test", "test")
+	f.Add(&Text{Name: "t", Value: "x"}, NewReset("Reset"), NewResetButton(String("Reset")))
+	if v := f.AsValues(); len(*v) != 1 {
+		t.Errorf("Expected reset fields to have no values, got %v", v)
+	}
+}
+
+func TestAccessKeys(t *testing.T) {
+	addr := New("addr", "")
+	addr.Add(&Text{HTML: HTML{AccessKey: "S"}, Name: "street", Label: "Street"})
+
+	f := New("test", "test")
+	f.Add(
+		&Text{HTML: HTML{AccessKey: "n"}, Name: "name", Label: "Name"},
+		&Div{Fields: []Field{NewButton("save", "Save")}},
+		&Submit{HTML: HTML{AccessKey: "s x"}, Name: "go", Value: "Go"},
+	)
+	f.Fields[1].(*Div).Fields[0].(*Button).AccessKey = "v"
+	f.Embed(addr)
+
+	keys := f.AccessKeys()
+	if len(keys) != 5 {
+		t.Fatalf("Expected 5 access keys, got %d", len(keys))
+	}
+	if keys[1].Key != "v" || keys[1].Description != "Save" {
+		t.Errorf("Unexpected nested key: %+v", keys[1])
+	}
+
+	conflicts := f.AccessKeyConflicts()
+	if len(conflicts) != 1 || len(conflicts["s"]) != 2 {
+		t.Errorf("Expected one conflict on s, got %v", conflicts)
+	}
+
+	var b bytes.Buffer
+	html.Render(&b, f.AccessKeyLegend())
+	if !strings.Contains(b.String(), `<dt><kbd>n</kbd></dt><dd>Name</dd>`) {
+		t.Errorf("Unexpected legend: %s", b.String())
+	}
+	if New("empty", "").AccessKeyLegend() != nil {
+		t.Errorf("Expected no legend without access keys.")
+	}
+}
+
+func TestAssignTabIndex(t *testing.T) {
+	f := New("test", "test")
+	f.AutoTabIndex = true
+	f.Add(
+		&Text{Name: "a"},
+		&Hidden{Name: "h"},
+		&Div{Fields: []Field{
+			&Text{HTML: HTML{TabIndex: "2"}, Name: "b"},
+			&Text{Name: "c", Disabled: true},
+			&Select{Name: "d"},
+		}},
+		&Text{HTML: HTML{Hidden: OTrue}, Name: "e"},
+		NewButton("f", "Go"),
+	)
+	f.Element()
+
+	expect := map[string]string{"a": "1", "h": "", "b": "2", "c": "", "d": "3", "e": "", "f": "4"}
+	for name, e := range expect {
+		if a := htmlOf(f.Field(name)).TabIndex; a != e {
+			t.Errorf("Expected tabindex %q for %s, got %q", e, name, a)
+		}
+	}
+	if next := New("other", "").Add(&Text{Name: "x"}).AssignTabIndex(5); next != 6 {
+		t.Errorf("Expected next index 6, got %d", next)
+	}
+}
+
+func TestAddExternal(t *testing.T) {
+	f := New("edit", "/edit")
+	f.Add(&Text{Name: "title", Value: "Hello"})
+	f.AddExternal(&Submit{Name: "save", Value: "Save"})
+
+	if form := f.External[0].(*Submit).Form; form != "edit" {
+		t.Errorf("Expected external field to reference form 'edit', got %q", form)
+	}
+	if v := f.AsValues().Get("save"); v != "Save" {
+		t.Errorf("Expected external field in values, got %q", v)
+	}
+}
+
+func TestSetNonce(t *testing.T) {
+	f := New("test", "/test")
+	f.Fields = []Field{
+		&Div{Fields: []Field{&Script{Content: "alert(1)"}}},
+		&Style{Nonce: "mine"},
+	}
+
+	ctx := WithNonce(context.Background(), "abc123")
+	f.SetNonce(Nonce(ctx))
+
+	s := f.Fields[0].(*Div).Fields[0].(*Script)
+	expectAttrs(t, s.Element(), map[string]string{"nonce": "abc123"})
+	if n := f.Fields[1].(*Style).Nonce; n != "abc123" {
+		t.Errorf("Expected existing nonce to be replaced, got %q", n)
+	}
+
+	f.SetNonce("def456")
+	expectAttrs(t, s.Element(), map[string]string{"nonce": "def456"})
+	if n := f.Fields[1].(*Style).Nonce; n != "def456" {
+		t.Errorf("Expected the next request's nonce, got %q", n)
+	}
+}
+
 func TestAsValues(t *testing.T) {
 	f := Form{
 		Name: "test",
@@ -64,7 +333,7 @@ func TestAsValues(t *testing.T) {
 		t.Errorf("expected two checked values.")
 	}
 }
-func ExampleAsValues() {
+func ExampleForm_AsValues() {
 
 	// A form with a group of checkboxes and a select list.
 	f := Form{
@@ -175,3 +444,1394 @@ func expectAttrs(t *testing.T, n *html.Node, e map[string]string) {
 		}
 	}
 }
+
+func TestMaskedValues(t *testing.T) {
+	addr := New("addr", "")
+	addr.Sensitive = []string{"street"}
+	addr.Add(&Text{Name: "street", Value: "1 Main St"}, &Text{Name: "city", Value: "Chicago"})
+
+	f := New("test", "test")
+	f.Sensitive = []string{"dob"}
+	f.Add(&Date{Name: "dob", Value: "2000-01-01"}, &Text{Name: "name", Value: "Matt"})
+	f.Embed(addr)
+
+	v := f.MaskedValues()
+	expect := map[string]string{
+		"dob":         Mask,
+		"name":        "Matt",
+		"addr_street": Mask,
+		"addr_city":   "Chicago",
+	}
+	for k, e := range expect {
+		if a := v.Get(k); a != e {
+			t.Errorf("Expected %s to be %q, got %q", k, e, a)
+		}
+	}
+	if !f.IsSensitive("addr_street") || f.IsSensitive("name") {
+		t.Errorf("Unexpected sensitivity for embedded fields.")
+	}
+	if f.AsValues().Get("dob") != "2000-01-01" {
+		t.Errorf("Expected AsValues to be unmasked.")
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	f := New("test", "test")
+	f.Prefix = "p_"
+	f.Sensitive = []string{"dob"}
+	f.Add(
+		&Date{Name: "dob", Value: "2000-01-01"},
+		&Password{Name: "pw", Value: "hunter2"},
+		&Text{Name: "Card_Number", Value: "4111"},
+		&Text{Name: "name", Value: "Matt"},
+	)
+
+	r := &Redactor{Patterns: []string{"*card*"}, Mask: "x"}
+	v := r.Values(f)
+	expect := map[string]string{"p_dob": "x", "p_pw": "x", "p_Card_Number": "x", "p_name": "Matt"}
+	for k, e := range expect {
+		if a := v.Get(k); a != e {
+			t.Errorf("Expected %s to be %q, got %q", k, e, a)
+		}
+	}
+
+	raw := r.RedactValues(&url.Values{"card": []string{"4111"}, "q": []string{"go"}})
+	if raw.Get("card") != "x" || raw.Get("q") != "go" {
+		t.Errorf("Unexpected redacted values: %v", raw)
+	}
+	if l := DefaultRedactor.Redact("user_password", "hunter2"); l != Mask {
+		t.Errorf("Expected default redactor to mask passwords, got %q", l)
+	}
+}
+
+type mapTranslator map[string]string
+
+func (m mapTranslator) Translate(locale, msg string) string {
+	if t, ok := m[locale+":"+msg]; ok {
+		return t
+	}
+	return msg
+}
+
+func TestSelectHelpers(t *testing.T) {
+	c := CountrySelect("country")
+	if len(c.Options) != len(Countries) {
+		t.Fatalf("Expected %d countries, got %d", len(Countries), len(c.Options))
+	}
+	if o := c.Options[0].(*Option); o.Value != "AF" {
+		t.Errorf("Expected countries ordered by name, got %q first", o.Value)
+	}
+
+	l := LanguageSelect("lang")
+	l.Localize(mapTranslator{"de:German": "Deutsch"}, "de")
+	found := false
+	for _, o := range l.Options {
+		if o := o.(*Option); o.Value == "de" {
+			found = o.Label == "Deutsch"
+		}
+	}
+	if !found {
+		t.Errorf("Expected German to be localized.")
+	}
+
+	tz := TimezoneSelect("tz")
+	g, ok := tz.Options[0].(*OptGroup)
+	if !ok || g.Label != "Africa" || g.Options[0].Value != "Africa/Abidjan" {
+		t.Errorf("Expected zones grouped by region, got %+v", tz.Options[0])
+	}
+	for _, o := range g.Options {
+		if o.Value == "Africa/Addis_Ababa" && o.Label != "Addis Ababa" {
+			t.Errorf("Expected a readable city name, got %q", o.Label)
+		}
+	}
+}
+
+func TestTextAreaCounter(t *testing.T) {
+	ta := &TextArea{Name: "bio", Rows: 4, Cols: 40, Wrap: WrapHard, MaxLength: 280, Counter: true}
+	expectAttrs(t, ta.Element(), map[string]string{
+		"rows":         "4",
+		"cols":         "40",
+		"wrap":         "hard",
+		"maxlength":    "280",
+		"data-counter": "280",
+	})
+
+	ta = &TextArea{Name: "notes", Counter: true}
+	expectAttrs(t, ta.Element(), map[string]string{"data-counter": "0"})
+}
+
+func TestRangeTicks(t *testing.T) {
+	r := &Range{Name: "rating", Min: "1", Max: "5"}
+	f := New("test", "test")
+	f.Prefix = "p_"
+	f.Add(r, r.Ticks(Tick("1", "Poor"), Tick("3", ""), Tick("5", "Great")))
+
+	if r.List != "rating-ticks" {
+		t.Errorf("Expected list rating-ticks, got %q", r.List)
+	}
+	node := f.Element()
+	expectAttrs(t, node.FirstChild, map[string]string{"list": "p_rating-ticks"})
+
+	dl := node.LastChild
+	expectAttrs(t, dl, map[string]string{"id": "p_rating-ticks"})
+	if dl.FirstChild == nil || dl.FirstChild.FirstChild.Data != "Poor" {
+		t.Errorf("Expected tick labels to be rendered.")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	city := &Text{Name: "city"}
+	email := &Email{Name: "email", HTML: HTML{Id: "contact"}}
+	shared := &DataList{Options: []*Option{{Value: "a@example.com"}}}
+	f := New("test", "test")
+	f.Prefix = "p_"
+	f.Add(city, Suggest(city, "Berlin", "Oslo"), email, shared.ListFor(email))
+
+	if city.List != "city-list" || email.List != "contact-list" {
+		t.Errorf("Expected lists city-list and contact-list, got %q and %q", city.List, email.List)
+	}
+	node := f.Element()
+	expectAttrs(t, node.FirstChild, map[string]string{"list": "p_city-list"})
+	dl := node.FirstChild.NextSibling
+	expectAttrs(t, dl, map[string]string{"id": "p_city-list"})
+	if dl.FirstChild == nil || dl.LastChild.FirstChild.Data != "Oslo" {
+		t.Errorf("Expected suggestions to be rendered.")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a field without a list to panic")
+		}
+	}()
+	Suggest(&TextArea{Name: "x"}, "y")
+}
+
+func TestHideAndRemove(t *testing.T) {
+	f := New("test", "test")
+	f.Add(
+		&Text{Name: "shown", Value: "a"},
+		&Div{Fields: []Field{&Text{Name: "hidden", Value: "b"}, &Text{Name: "gone", Value: "c"}}},
+		&Text{Name: "unset", HTML: HTML{Hidden: OFalse}},
+	)
+
+	if !f.Hide("hidden") || f.Hide("missing") {
+		t.Errorf("Unexpected result from Hide.")
+	}
+	if r := f.Remove("gone"); r == nil || r.(*Text).Value != "c" {
+		t.Errorf("Expected removed field to be returned, got %v", r)
+	}
+	if f.Remove("gone") != nil {
+		t.Errorf("Expected field to be removed only once.")
+	}
+
+	v := f.AsValues()
+	if v.Get("hidden") != "b" || v.Get("gone") != "" {
+		t.Errorf("Expected hidden field to remain and removed field to be gone, got %v", v)
+	}
+	expectAttrs(t, f.Field("hidden").(*Text).Element(), map[string]string{"hidden": "hidden"})
+	for _, a := range f.Field("unset").(*Text).Element().Attr {
+		if a.Key == "hidden" {
+			t.Errorf("Expected OFalse to omit the hidden attribute.")
+		}
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	f := New("test", "test")
+	f.Defaults = FieldDefaults{
+		Class: []string{"input"},
+		Data:  map[string]string{"data-theme": "dark"},
+	}
+	f.Add(
+		&Text{Name: "plain"},
+		&Div{Fields: []Field{
+			&Text{Name: "own", HTML: HTML{Class: []string{"wide", "input"}, Data: map[string]string{"data-theme": "light"}}},
+		}},
+	)
+	f.AddExternal(&Submit{Name: "go"})
+
+	f.Element()
+	f.ApplyDefaults()
+
+	plain := f.Field("plain").(*Text)
+	if len(plain.Class) != 1 || plain.Class[0] != "input" || plain.Data["data-theme"] != "dark" {
+		t.Errorf("Expected defaults on plain field, got %v %v", plain.Class, plain.Data)
+	}
+	own := f.Field("own").(*Text)
+	if strings.Join(own.Class, " ") != "wide input" || own.Data["data-theme"] != "light" {
+		t.Errorf("Expected field's own attributes to be kept, got %v %v", own.Class, own.Data)
+	}
+	if div := f.Fields[1].(*Div); len(div.Class) != 0 {
+		t.Errorf("Expected containers to be left alone, got %v", div.Class)
+	}
+	if ext := f.Field("go").(*Submit); len(ext.Class) != 1 {
+		t.Errorf("Expected defaults on external field, got %v", ext.Class)
+	}
+}
+
+func TestNamePolicy(t *testing.T) {
+	f := New("test", "test")
+	f.NamePolicy = RenameDuplicateNames
+	f.Add(&Text{Name: "email"}, &Radio{Name: "color", Value: "red"})
+	f.Add(&Div{Fields: []Field{&Text{Name: "email"}, &Text{Name: "email"}}})
+	f.Add(&Radio{Name: "color", Value: "blue"}, &Checkbox{Name: "color"})
+
+	names := []string{}
+	walkFields(f.Fields, func(field Field) {
+		if n := nameOf(field); n != "" {
+			names = append(names, n)
+		}
+	})
+	if e := "email color email-2 email-3 color color"; strings.Join(names, " ") != e {
+		t.Errorf("Expected names %q, got %q", e, strings.Join(names, " "))
+	}
+
+	f.NamePolicy = RejectDuplicateNames
+	func() {
+		defer func() {
+			if err, ok := recover().(*DuplicateNameError); !ok || err.Name != "email" {
+				t.Errorf("Expected a *DuplicateNameError for email, got %v", err)
+			}
+		}()
+		f.AddExternal(&Text{Name: "email"})
+	}()
+	if len(f.External) != 0 {
+		t.Errorf("Expected rejected field not to be added.")
+	}
+
+	f.NamePolicy = AllowDuplicateNames
+	f.Add(&Text{Name: "email"})
+	if len(f.Fields) != 6 {
+		t.Errorf("Expected duplicate to be allowed, got %d fields", len(f.Fields))
+	}
+}
+
+func TestUncheckedValue(t *testing.T) {
+	f := New("test", "test")
+	f.Add(
+		&Checkbox{Name: "agree", Value: "1"},
+		&FieldSet{Fields: []Field{
+			&Checkbox{Name: "tags", Value: "a", Checked: true},
+			&Checkbox{Name: "tags", Value: "b"},
+		}},
+	)
+	if v := f.AsValues(); len(*v) != 1 {
+		t.Errorf("Expected only checked boxes without UncheckedValue, got %v", v)
+	}
+
+	f.UncheckedValue = "0"
+	v := f.AsValues()
+	if v.Get("agree") != "0" {
+		t.Errorf("Expected sentinel for unchecked box, got %q", v.Get("agree"))
+	}
+	if tags := (*v)["tags"]; len(tags) != 1 || tags[0] != "a" {
+		t.Errorf("Expected no sentinel for a partly checked group, got %v", tags)
+	}
+}
+
+func TestEachValue(t *testing.T) {
+	f := New("test", "test")
+	f.Prefix = "p-"
+	f.UncheckedValue = "0"
+	f.Add(
+		&Text{Name: "title", Value: "Hello"},
+		&Checkbox{Name: "agree", Value: "1"},
+		&Select{Name: "pick", Multiple: true, Options: []OptionItem{
+			&Option{Value: "a", Selected: true}, &Option{Value: "b"}, &Option{Value: "c", Selected: true},
+		}},
+	)
+
+	vals := f.AppendValues(nil)
+	expect := []Value{{"p-title", "Hello"}, {"p-pick", "a"}, {"p-pick", "c"}, {"p-agree", "0"}}
+	if fmt.Sprint(vals) != fmt.Sprint(expect) {
+		t.Errorf("Expected %v, got %v", expect, vals)
+	}
+
+	// The pairs must agree with AsValues.
+	av := url.Values{}
+	for _, v := range vals {
+		av.Add(v.Name, v.Value)
+	}
+	if av.Encode() != f.AsValues().Encode() {
+		t.Errorf("Expected %s, got %s", f.AsValues().Encode(), av.Encode())
+	}
+
+	if again := f.AppendValues(vals[:0]); &again[0] != &vals[0] {
+		t.Error("Expected AppendValues to reuse storage")
+	}
+}
+
+func TestAsMultipart(t *testing.T) {
+	f := New("test", "test")
+	f.Prefix = "p-"
+	f.Add(
+		&Text{Name: "title", Value: "Hello"},
+		&File{Name: "photo"},
+		&File{Name: "resume"},
+	)
+	f.AttachFile("photo", "cat.png", []byte("meow"))
+	f.AttachFile("photo", "dog.txt", []byte("woof"))
+
+	body, ct, err := f.AsMultipart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, err := mime.ParseMediaType(ct)
+	if err != nil || !strings.HasPrefix(ct, "multipart/form-data") {
+		t.Fatalf("Unexpected content type %q: %v", ct, err)
+	}
+	m, err := multipart.NewReader(body, params["boundary"]).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := m.Value["p-title"]; len(v) != 1 || v[0] != "Hello" {
+		t.Errorf("Expected prefixed title, got %v", m.Value)
+	}
+	photos := m.File["p-photo"]
+	if len(photos) != 2 || photos[0].Filename != "cat.png" || photos[1].Filename != "dog.txt" {
+		t.Fatalf("Expected two photos, got %v", photos)
+	}
+	if ct := photos[0].Header.Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Expected image/png, got %q", ct)
+	}
+	r, _ := photos[1].Open()
+	if b, _ := ioutil.ReadAll(r); string(b) != "woof" {
+		t.Errorf("Expected attached content, got %q", b)
+	}
+	if _, ok := m.Value["p-resume"]; !ok {
+		t.Errorf("Expected an empty part for a field without attachments, got %v", m.Value)
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	f := New("test", "https://example.com/search?old=1")
+	f.Add(&Text{Name: "q", Value: "go forms"})
+
+	req, err := f.NewRequest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "GET" || req.URL.RawQuery != "q=go+forms" {
+		t.Errorf("Unexpected GET request %s %s", req.Method, req.URL)
+	}
+
+	f.Method = "post"
+	req, _ = f.NewRequest(context.Background())
+	if err := req.ParseForm(); err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "POST" || req.PostForm.Get("q") != "go forms" {
+		t.Errorf("Unexpected POST request %s %v", req.Method, req.PostForm)
+	}
+
+	f.Enctype = EnctypeMultipart
+	req, _ = f.NewRequest(context.Background())
+	if err := req.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+	if req.FormValue("q") != "go forms" {
+		t.Errorf("Unexpected multipart request %v", req.MultipartForm.Value)
+	}
+
+	f.Enctype = EnctypeText
+	req, _ = f.NewRequest(context.Background())
+	if b, _ := ioutil.ReadAll(req.Body); string(b) != "q=go forms\r\n" {
+		t.Errorf("Unexpected text/plain body %q", b)
+	}
+
+	f.Method = "dialog"
+	if _, err := f.NewRequest(context.Background()); err != ErrInvalidMethod {
+		t.Errorf("Expected ErrInvalidMethod, got %v", err)
+	}
+	if _, err := New("test", "").NewRequest(context.Background()); err != ErrNoAction {
+		t.Errorf("Expected ErrNoAction, got %v", err)
+	}
+}
+
+func TestElementNeverPanics(t *testing.T) {
+	fields := []Field{
+		&Form{}, String(""),
+		&Div{}, &FieldSet{}, &Label{}, &Button{}, &Keygen{}, &Output{},
+		&Computed{}, &Money{}, &Duration{}, &Progress{}, &Meter{}, &Select{}, &DataList{},
+		&OptGroup{}, &Option{}, &TextArea{}, &Script{}, &Style{},
+		&Input{}, &Password{}, &Text{}, &Submit{}, &Tel{}, &URL{}, &Email{},
+		&Date{}, &Time{}, &Number{}, &Range{}, &Color{}, &AlphaColor{}, &Checkbox{},
+		&Radio{}, &File{}, &Image{}, &Reset{}, &ButtonInput{}, &Hidden{},
+		&Month{}, &Week{}, &DatetimeLocal{}, &Search{},
+		nil,
+		&Div{Fields: []Field{nil, (*Text)(nil)}},
+		&FieldSet{Fields: []Field{nil}},
+		&Label{Fields: []Field{nil}},
+		&Button{Fields: []Field{nil}},
+		&Select{Options: []OptionItem{nil, (*Option)(nil), (*OptGroup)(nil), &OptGroup{Options: []*Option{nil}}}},
+		&DataList{Options: []*Option{nil}},
+		&OptGroup{Options: []*Option{nil}},
+		&Duration{Units: []time.Duration{0, -1}},
+		&Money{Currencies: []string{""}},
+		(*Text)(nil), (*Select)(nil), (*Div)(nil), (*Form)(nil),
+	}
+	for i, field := range fields {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("%d: %T panicked: %v", i, field, r)
+				}
+			}()
+			f := New("test", "/test")
+			f.Fields = []Field{field}
+			f.Element()
+			f.AsValues()
+			f.Lint()
+		}()
+	}
+}
+
+func TestLint(t *testing.T) {
+	f := New("test", "/test")
+	f.AcceptCharset = []string{"utf-9"}
+	f.Add(
+		&Text{Name: "a", HTML: HTML{Id: "dup"}},
+		&FieldSet{Name: "box", Fields: []Field{nil, &Text{Name: "b", HTML: HTML{Id: "dup"}}}},
+		&Label{Field: "missing", Text: "Missing"},
+		&Select{Name: "s", Options: []OptionItem{
+			(*Option)(nil),
+			Option{Value: "not a pointer"},
+			&OptGroup{Label: "g", Options: []*Option{nil}},
+		}},
+	)
+
+	expect := []string{
+		`Unknown charset "utf-9"`,
+		`box: field 0 is nil`,
+		`b: ID "dup" is used by more than one field`,
+		`label "Missing" refers to missing field "missing"`,
+		`s: option 0 is nil`,
+		`s: option 1 is a form.Option, not an *Option or *OptGroup`,
+		`s: option 0 of group "g" is nil`,
+	}
+	errs := f.Lint()
+	if len(errs) != len(expect) {
+		t.Fatalf("Expected %d problems, got %v", len(expect), errs)
+	}
+	for i, e := range expect {
+		if errs[i].Error() != e {
+			t.Errorf("Expected %q, got %q", e, errs[i])
+		}
+	}
+	if errs := New("ok", "/ok").Add(&Text{Name: "a"}).Lint(); len(errs) != 0 {
+		t.Errorf("Expected no problems, got %v", errs)
+	}
+}
+
+func TestAssignIDs(t *testing.T) {
+	f := New("sign up", "/signup")
+	f.AutoID = true
+	f.Errors.Add("plan", "is required")
+	f.Add(
+		&Text{Name: "email", Label: "Email"},
+		&FieldSet{Name: "address", Fields: []Field{
+			&Text{Name: "street"},
+			&Div{Fields: []Field{&Text{Name: "city", HTML: HTML{Id: "mine"}}}},
+		}},
+		&Radio{Name: "plan", Value: "free", Label: "Free"},
+		&Radio{Name: "plan", Value: "pro", Label: "Pro"},
+		&Div{},
+	)
+
+	var b bytes.Buffer
+	if err := Render(&b, f, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, e := range []string{
+		`<form action="/signup" name="sign up" id="sign-up">`,
+		`<label for="sign-up-email" id="sign-up-email-label">Email</label>`,
+		`id="sign-up-address"`,
+		`id="sign-up-address-street"`,
+		`id="sign-up-address-div"`,
+		`id="mine"`,
+		`<label id="sign-up-plan-label"><input type="radio" name="plan" value="free" id="sign-up-plan"/>`,
+		`id="sign-up-plan-1"`,
+		`id="sign-up-div"`,
+	} {
+		if !strings.Contains(out, e) {
+			t.Errorf("Expected %s in output:\n%s", e, out)
+		}
+	}
+
+	b.Reset()
+	f.RenderField(&b, "plan")
+	if !strings.Contains(b.String(), `<ul class="errors" id="sign-up-plan-errors">`) {
+		t.Errorf("Expected an ID on the error list, got:\n%s", b.String())
+	}
+}
+
+func TestPaths(t *testing.T) {
+	addr := New("address", "")
+	addr.Add(&Text{Name: "street"})
+
+	f := New("signup", "/signup")
+	f.Add(
+		String("Welcome"),
+		&Text{Name: "user.email"},
+		&FieldSet{Name: "prefs", Fields: []Field{
+			&Radio{Name: "plan", Value: "free"},
+			&Radio{Name: "plan", Value: "pro"},
+			&Div{},
+		}},
+	)
+	f.Embed(addr)
+	f.AddExternal(&Submit{Name: "go"})
+
+	paths := []string{}
+	f.Walk(func(p string, field Field) { paths = append(paths, p) })
+	expect := []string{
+		`signup.user\.email`,
+		`signup.prefs`,
+		`signup.prefs.plan`,
+		`signup.prefs.plan[1]`,
+		`signup.prefs.div`,
+		`signup.address`,
+		`signup.address.street`,
+		`signup.go`,
+	}
+	if strings.Join(paths, " ") != strings.Join(expect, " ") {
+		t.Errorf("Expected paths %v, got %v", expect, paths)
+	}
+
+	if r := f.FieldAt("signup.prefs.plan[1]"); r == nil || r.(*Radio).Value != "pro" {
+		t.Errorf("Expected the second radio button, got %v", r)
+	}
+	if r := f.FieldAt("signup.prefs.plan[0]"); r == nil || r.(*Radio).Value != "free" {
+		t.Errorf("Expected [0] to be the first radio button, got %v", r)
+	}
+	if f.FieldAt("signup.nope") != nil {
+		t.Errorf("Expected nil for a missing path.")
+	}
+	if p := f.PathOf(addr.Field("street")); p != "signup.address.street" {
+		t.Errorf("Unexpected path %q", p)
+	}
+
+	f.Errors.Add("plan", "is required")
+	f.Errors.Add("other", "is odd")
+	errs := f.ErrorPaths()
+	if len(errs["signup.prefs.plan"]) != 1 || len(errs["other"]) != 1 {
+		t.Errorf("Unexpected error paths %v", errs)
+	}
+
+	f.AssignIDs()
+	if id := f.Field("user.email").(*Text).Id; id != "signup-user.email" {
+		t.Errorf("Unexpected ID %q", id)
+	}
+	if id := f.FieldAt("signup.prefs.plan[1]").(*Radio).Id; id != "signup-prefs-plan-1" {
+		t.Errorf("Unexpected ID %q", id)
+	}
+}
+
+func TestEcho(t *testing.T) {
+	f := New("pay", "/pay")
+	f.Echo = map[string]EchoPolicy{"card": EchoMaskMiddle, "pin": EchoNever, "pw2": EchoNormal}
+	f.Add(
+		&Password{Name: "pw", Value: "hunter2"},
+		&Password{Name: "pw2", Value: "hunter2"},
+		&Text{Name: "card", Value: "4111111111111111"},
+		&TextArea{Name: "pin", Value: "1234"},
+		&Text{Name: "name", Value: "Matt"},
+	)
+
+	var b bytes.Buffer
+	Render(&b, f, RenderOptions{})
+	out := b.String()
+	for _, e := range []string{
+		`name="pw" value=""`,
+		`name="pw2" value="hunter2"`,
+		`name="card" value="4111••••••••1111"`,
+		`<textarea name="pin"></textarea>`,
+		`name="name" value="Matt"`,
+	} {
+		if !strings.Contains(out, e) {
+			t.Errorf("Expected %s in output:\n%s", e, out)
+		}
+	}
+	if f.Field("card").(*Text).Value != "4111111111111111" {
+		t.Errorf("Expected rendering not to change the value.")
+	}
+
+	Reconcile(f, &url.Values{"card": {"4111••••••••1111"}})
+	if v := f.Field("card").(*Text).Value; v != "4111111111111111" {
+		t.Errorf("Expected an unchanged masked value to be ignored, got %q", v)
+	}
+	Reconcile(f, &url.Values{"card": {"5500000000000004"}})
+	if v := f.Field("card").(*Text).Value; v != "5500000000000004" {
+		t.Errorf("Expected a new value to be reconciled, got %q", v)
+	}
+	if v := maskMiddle("12345678"); v != "••••••••" {
+		t.Errorf("Expected short values to be masked entirely, got %q", v)
+	}
+}
+
+func TestEnsureId(t *testing.T) {
+	if id := (HTML{Id: "given"}).EnsureId("seed"); id != "given" {
+		t.Errorf("Expected 'given', got %q", id)
+	}
+	if id := (HTML{}).EnsureId("seed"); id != "seed" {
+		t.Errorf("Expected 'seed', got %q", id)
+	}
+
+	defer func(r io.Reader) { Rand = r }(Rand)
+	Rand = bytes.NewReader([]byte{0xde, 0xad, 0xbe, 0xef, 0, 1, 2, 3})
+	if id := (HTML{}).EnsureId(""); id != "id-deadbeef00010203" {
+		t.Errorf("Expected 'id-deadbeef00010203', got %q", id)
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	decl := func() *Form {
+		f := New("signup", "/signup")
+		f.Prefix = "p-"
+		return f.Add(
+			&Text{Name: "user", Label: "User name"},
+			&Password{Name: "pass"},
+			&Select{Name: "pick", Multiple: true, Options: []OptionItem{&Option{Value: "a", Selected: true}, &Option{Value: "b", Selected: true}}},
+		)
+	}
+	var b bytes.Buffer
+	w := NewCSVWriter(&b, decl())
+	for _, user := range []string{"matt", "sam, jr"} {
+		f := decl()
+		Reconcile(f, &url.Values{"p-user": {user}, "p-pass": {"hunter2"}})
+		if err := w.Write(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := "User name,p-pass,p-pick\nmatt,********,a; b\n\"sam, jr\",********,a; b\n"
+	if b.String() != expect {
+		t.Errorf("Expected %q, got %q", expect, b.String())
+	}
+
+	b.Reset()
+	w = NewCSVWriter(&b, decl())
+	for _, user := range []string{"=1+2", "+1", "-1", "@SUM(A1)", "\tx", "\rx", "a=b"} {
+		f := decl()
+		Reconcile(f, &url.Values{"p-user": {user}})
+		if err := w.Write(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect = "User name,p-pass,p-pick\n'=1+2,********,a; b\n'+1,********,a; b\n'-1,********,a; b\n'@SUM(A1),********,a; b\n'\tx,********,a; b\n\"'\rx\",********,a; b\na=b,********,a; b\n"
+	if b.String() != expect {
+		t.Errorf("Expected formulas to be neutralised, got %q", b.String())
+	}
+}
+
+func TestBulkEdit(t *testing.T) {
+	type item struct {
+		ID     int
+		Title  string `form:"title"`
+		Price  float64
+		Due    time.Time
+		Active bool
+		Notes  string
+	}
+	proto := New("items", "/items").Add(
+		&Hidden{Name: "ID"},
+		&Text{Name: "title", Label: "Title"},
+		&Number{Name: "Price", Label: "Price", Step: "any"},
+		&Date{Name: "Due", Label: "Due"},
+		&Checkbox{Name: "Active", Value: "on", Label: "Active"},
+	)
+	due := time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)
+	items := []*item{
+		{ID: 7, Title: "Pen", Price: 1.5, Due: due, Active: true, Notes: "blue"},
+		{ID: 9, Title: "Ink", Price: 3},
+	}
+	if _, err := NewBulkEdit(proto, []string{"x"}); err != ErrBulkRecords {
+		t.Errorf("Expected ErrBulkRecords, got %v", err)
+	}
+	bulk := func() *Form {
+		f, err := NewBulkEdit(proto, items)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	f := bulk()
+
+	var b bytes.Buffer
+	if err := Render(&b, f, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, expect := range []string{
+		`<thead><tr><th scope="col">Title</th><th scope="col">Price</th><th scope="col">Due</th><th scope="col">Active</th></tr></thead>`,
+		`<tr><td><input type="hidden" name="items-0-ID" value="7"/><input type="text" name="items-0-title" value="Pen"/></td>`,
+		`name="items-0-Due" value="2016-03-01"`,
+		`name="items-0-Active" value="on" checked`,
+		`<input type="hidden" name="items-1-ID" value="9"/>`,
+	} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("Expected %s in %s", expect, out)
+		}
+	}
+	if strings.Contains(out, `name="items-1-Active" value="on" checked`) {
+		t.Errorf("Expected second row to be unchecked: %s", out)
+	}
+
+	sub := bulk()
+	Reconcile(sub, &url.Values{
+		"items-0-ID": {"7"}, "items-0-title": {"Pen"}, "items-0-Price": {"2.25"}, "items-0-Due": {"2016-04-01"},
+		"items-1-ID": {"9"}, "items-1-title": {"Ink"}, "items-1-Price": {"3"}, "items-1-Active": {"on"},
+	})
+	if err := DecodeBulk(sub, &items); err != nil {
+		t.Fatal(err)
+	}
+	if i := items[0]; i.Price != 2.25 || !i.Due.Equal(due.AddDate(0, 1, 0)) || i.Active || i.Notes != "blue" {
+		t.Errorf("Unexpected first item %+v", i)
+	}
+	if i := items[1]; i.ID != 9 || i.Title != "Ink" || !i.Active {
+		t.Errorf("Unexpected second item %+v", i)
+	}
+
+	sub = bulk()
+	Reconcile(sub, &url.Values{"items-0-ID": {"seven"}})
+	var out2 []item
+	if err := DecodeBulk(sub, &out2); err == nil {
+		t.Error("Expected a BindError")
+	} else if be, ok := err.(*BindError); !ok || be.Name != "ID" {
+		t.Errorf("Expected a BindError for ID, got %v", err)
+	}
+}
+
+func TestFilterForm(t *testing.T) {
+	type filters struct {
+		Query   string `form:"q"`
+		MinSize int    `form:"min"`
+		Open    bool   `form:"open"`
+	}
+	decl := func() *FilterForm {
+		f := NewFilterForm("search", "/issues?page=2")
+		f.Add(
+			&Text{Name: "q"},
+			&Number{Name: "min"},
+			&Checkbox{Name: "open", Value: "1"},
+		)
+		return f
+	}
+
+	f := decl()
+	r := &http.Request{URL: &url.URL{Path: "/issues", RawQuery: "utm=x&q=crash&open=1&min=3"}}
+	var got filters
+	canonical, err := f.Parse(r, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if canonical != "/issues?min=3&open=1&q=crash" {
+		t.Errorf("Unexpected canonical URL %q", canonical)
+	}
+	if got.Query != "crash" || got.MinSize != 3 || !got.Open {
+		t.Errorf("Unexpected filters %+v", got)
+	}
+
+	f = decl()
+	if err := f.FromQuery(url.Values{"q": {""}}); err != nil {
+		t.Fatal(err)
+	}
+	if u := f.URL(); u != "/issues" {
+		t.Errorf("Expected empty values to be omitted, got %q", u)
+	}
+	got = filters{Open: true}
+	if err := f.Decode(&got); err != nil || got.Open {
+		t.Errorf("Expected an unchecked box to decode as false, got %+v, %v", got, err)
+	}
+	if err := f.Decode(got); err != ErrNotStructPointer {
+		t.Errorf("Expected ErrNotStructPointer, got %v", err)
+	}
+
+	f = decl()
+	if err := f.FromQuery(url.Values{"min": {"many"}}); err != ErrInvalid {
+		t.Errorf("Expected ErrInvalid, got %v", err)
+	}
+	if f.Method != "get" {
+		t.Errorf("Expected method get, got %q", f.Method)
+	}
+}
+
+func TestCarryQuery(t *testing.T) {
+	f := New("archive", "/items/archive").Add(&Hidden{Name: "id", Value: "4"})
+	listing := url.Values{"page": {"3"}, "tag": {"a", "b"}, "utm_source": {"mail"}}
+	f.CarryQuery(listing, "page", "tag", "sort")
+	f.CarryQuery(listing, "page", "tag")
+	if n := len(f.Fields); n != 2 {
+		t.Errorf("Expected one carried field, got %d fields", n)
+	}
+
+	fh := NewFormHandler(NewCache(), time.Minute)
+	id, err := fh.Prepare(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := f.AsValues()
+	v.Set(SecureTokenName, id)
+	sub, err := fh.Retrieve(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := sub.CarriedQuery(); q.Get("page") != "3" || len(q["tag"]) != 2 || q.Get("utm_source") != "" {
+		t.Errorf("Unexpected carried query %v", q)
+	}
+	if u := sub.ReturnURL("/items?page=1&view=grid"); u != "/items?page=3&tag=a&tag=b&view=grid" {
+		t.Errorf("Unexpected return URL %q", u)
+	}
+	if u := New("x", "/").ReturnURL("/items"); u != "/items" {
+		t.Errorf("Expected the target unchanged, got %q", u)
+	}
+
+	// Parameters added by the user are dropped, even once the form has
+	// been serialized.
+	tampered, err := copyForm(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Reconcile(tampered, &url.Values{CarryName: {"page=4&next=https%3A%2F%2Fevil.example&utm_source=x"}})
+	if u := tampered.ReturnURL("/items"); u != "/items?page=4" {
+		t.Errorf("Expected only the named parameters, got %q", u)
+	}
+	undeclared := New("archive", "/items/archive").Add(&Hidden{Name: CarryName})
+	Reconcile(undeclared, &url.Values{CarryName: {"page=4"}})
+	if u := undeclared.ReturnURL("/items"); u != "/items" {
+		t.Errorf("Expected nothing carried without CarryQuery, got %q", u)
+	}
+}
+
+func TestBind(t *testing.T) {
+	type Audit struct {
+		Updated time.Time `form:"updated"`
+	}
+	type profile struct {
+		Audit
+		Name    string
+		Age     int     `form:"age"`
+		Height  float32 `form:"height"`
+		Admin   bool    `form:"admin"`
+		Born    time.Time
+		Tags    []string `form:"tag"`
+		Score   *uint8   `form:"score"`
+		Skipped string   `form:"-"`
+		secret  string
+	}
+	p := profile{Name: "keep", Admin: true, Skipped: "x", secret: "s"}
+	vals := url.Values{
+		"age": {"42"}, "height": {"1.8"}, "admin": {"0"}, "Born": {"1975-05-04"},
+		"updated": {"2016-01-02T03:04"}, "tag": {"a", "b"}, "score": {"7"},
+		"Skipped": {"y"}, "secret": {"z"},
+	}
+	if err := Bind(vals, &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "keep" || p.Age != 42 || p.Height != 1.8 || p.Admin || p.Skipped != "x" || p.secret != "s" {
+		t.Errorf("Unexpected fields %+v", p)
+	}
+	if p.Born != time.Date(1975, 5, 4, 0, 0, 0, 0, time.UTC) || p.Updated != time.Date(2016, 1, 2, 3, 4, 0, 0, time.UTC) {
+		t.Errorf("Unexpected times %v, %v", p.Born, p.Updated)
+	}
+	if len(p.Tags) != 2 || p.Score == nil || *p.Score != 7 {
+		t.Errorf("Unexpected slice or pointer %v, %v", p.Tags, p.Score)
+	}
+
+	f := New("p", "/p").Add(&Checkbox{Name: "admin", Value: "on"})
+	f.UncheckedValue = "0"
+	p.Admin = true
+	if err := Bind(*f.AsValues(), &p); err != nil || p.Admin {
+		t.Errorf("Expected an unchecked box to clear the bool, got %v, %v", p.Admin, err)
+	}
+
+	err := Bind(url.Values{"age": {"old"}}, &p)
+	if be, ok := err.(*BindError); !ok || be.Name != "age" || be.Value != "old" {
+		t.Errorf("Expected a BindError for age, got %v", err)
+	}
+	if err := Bind(url.Values{"score": {"300"}}, &p); err == nil {
+		t.Error("Expected an overflow to fail")
+	}
+	if err := Bind(vals, p); err != ErrNotStructPointer {
+		t.Errorf("Expected ErrNotStructPointer, got %v", err)
+	}
+}
+
+func TestFromStruct(t *testing.T) {
+	type Contact struct {
+		ID      int       `form:"id,widget=hidden"`
+		Email   string    `form:"email,label=Email address,required,widget=email,placeholder=you@example.com"`
+		Age     uint8     `form:"age"`
+		Weight  float64   `form:"weight"`
+		Born    time.Time `form:"born"`
+		Bio     string    `form:"bio,widget=textarea,label=About you"`
+		Plan    string    `form:"plan,widget=select,options=free|pro"`
+		Tags    []string  `form:"tags,options=a|b"`
+		Opt     bool      `form:"opt,label=Send news"`
+		Ignored string    `form:"-"`
+	}
+	c := Contact{ID: 3, Email: "matt@example.com", Age: 40, Born: time.Date(1976, 2, 3, 0, 0, 0, 0, time.UTC), Plan: "pro", Tags: []string{"b"}, Opt: true}
+	f, err := FromStruct(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "Contact" || len(f.Fields) != 10 {
+		t.Fatalf("Expected 10 fields in Contact, got %q with %d", f.Name, len(f.Fields))
+	}
+	email := f.Field("email").(*Email)
+	if email.Label != "Email address" || !email.Required || email.Placeholder != "you@example.com" || email.Value != "matt@example.com" {
+		t.Errorf("Unexpected email field %+v", email)
+	}
+	if n := f.Field("weight").(*Number); n.Step != "any" || f.Field("age").(*Number).Value != "40" {
+		t.Errorf("Unexpected number fields")
+	}
+	if d := f.Field("born").(*Date); d.Value != "1976-02-03" {
+		t.Errorf("Expected a date, got %q", d.Value)
+	}
+	if l, ok := f.Fields[5].(*Label); !ok || l.Text != "About you" || l.Field != "bio" {
+		t.Errorf("Expected a label for the text area, got %#v", f.Fields[5])
+	}
+	if s := f.Field("plan").(*Select); s.Multiple || !s.Options[1].(*Option).Selected {
+		t.Errorf("Expected pro to be selected")
+	}
+	if s := f.Field("tags").(*Select); !s.Multiple || !s.Options[1].(*Option).Selected || s.Options[0].(*Option).Selected {
+		t.Errorf("Expected a multiple select with b selected")
+	}
+	if cb := f.Field("opt").(*Checkbox); !cb.Checked || cb.Label != "Send news" {
+		t.Errorf("Expected a checked box, got %+v", cb)
+	}
+	if _, ok := f.Field("id").(*Hidden); !ok {
+		t.Errorf("Expected a hidden id")
+	}
+
+	var back Contact
+	if err := Bind(*f.AsValues(), &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.ID != 3 || back.Email != c.Email || !back.Born.Equal(c.Born) || back.Plan != "pro" || !back.Opt {
+		t.Errorf("Expected the form to bind back, got %+v", back)
+	}
+
+	type bad struct {
+		M map[string]string
+	}
+	if _, err := FromStruct(bad{}); err == nil {
+		t.Error("Expected a map to be unsupported")
+	}
+	type badTag struct {
+		N int `form:"n,widget=slider"`
+	}
+	if _, err := FromStruct(badTag{}); err == nil {
+		t.Error("Expected an unknown widget to fail")
+	}
+	type badOpt struct {
+		B bool `form:"b,placeholder=x,widget=select"`
+	}
+	if _, err := FromStruct(badOpt{}); err == nil {
+		t.Error("Expected a placeholder on a select to fail")
+	}
+	if _, err := FromStruct("x"); err != ErrNotStruct {
+		t.Errorf("Expected ErrNotStruct, got %v", err)
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	f := New("account", "/account").Add(
+		&Email{Name: "email", Value: "old@example.com"},
+		&Text{Name: "name", Value: "Matt"},
+		&Checkbox{Name: "news", Value: "yes"},
+	)
+	if c := f.Changed(); len(c) != 0 {
+		t.Errorf("Expected no changes before reconciling, got %v", c)
+	}
+	var calls []string
+	record := func(ctx context.Context, name string, old, new []string) error {
+		calls = append(calls, fmt.Sprintf("%s:%v>%v", name, old, new))
+		return nil
+	}
+	f.OnChange("email", record).OnChange("name", record).OnChange("news", record, func(ctx context.Context, name string, old, new []string) error {
+		return errors.New("news failed")
+	})
+
+	Reconcile(f, &url.Values{"email": {"new@example.com"}, "name": {"Matt"}, "news": {"yes"}})
+	if c := f.Changed(); strings.Join(c, ",") != "email,news" {
+		t.Errorf("Expected email and news to change, got %v", c)
+	}
+	err := f.DispatchChanges(context.Background())
+	if err == nil || err.Error() != "news failed" {
+		t.Errorf("Expected the news error, got %v", err)
+	}
+	expect := "email:[old@example.com]>[new@example.com] news:[]>[yes]"
+	if got := strings.Join(calls, " "); got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+
+	Reconcile(f, &url.Values{"email": {"old@example.com"}})
+	if c := f.Changed(); strings.Join(c, ",") != "news" {
+		t.Errorf("Expected changes to be relative to the first reconciliation, got %v", c)
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	f := New("order", "/order").Add(
+		&Text{Name: "code"},
+		&Number{Name: "qty"},
+		&Date{Name: "ship"},
+		&TextArea{Name: "notes"},
+	)
+	f.AddValidator("code", Required(), Pattern("[A-Z]{3}"), MaxLength(3))
+	f.AddValidator("qty", Min("1"), Max("10"), Step("2"))
+	f.AddValidator("ship", Min("2016-01-01"))
+	f.AddValidator("notes", MaxLength(140))
+	f.AddValidator("missing", Required())
+
+	var b bytes.Buffer
+	if err := Render(&b, f, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<input type="text" maxlength="3" name="code" pattern="[A-Z]{3}" required="required"/>`,
+		`max="10" min="1"`,
+		`step="2"`,
+		`min="2016-01-01"`,
+		`maxlength="140"`,
+	} {
+		if !strings.Contains(b.String(), s) {
+			t.Errorf("Expected %s in %s", s, b.String())
+		}
+	}
+
+	tests := []struct {
+		v     Validator
+		value string
+		ok    bool
+	}{
+		{Required(), " ", false},
+		{Required(), "x", true},
+		{Pattern("[A-Z]{3}"), "ABCD", false},
+		{Pattern("[A-Z]{3}"), "", true},
+		{MaxLength(3), "héé", true},
+		{MaxLength(3), "abcd", false},
+		{Min("1"), "0.5", false},
+		{Min("9"), "10", true},
+		{Max("2016-12-31"), "2017-01-01", false},
+		{Step("0.5"), "2.5", true},
+		{Step("2"), "3", false},
+		{Step("2"), "x", false},
+		{Step("any"), "3.14", true},
+	}
+	for _, tt := range tests {
+		if err := tt.v.Validate(context.Background(), tt.value); (err == nil) != tt.ok {
+			t.Errorf("Expected %q to be valid: %v, got %v", tt.value, tt.ok, err)
+		}
+	}
+}
+
+func TestSetValues(t *testing.T) {
+	decl := func() *Form {
+		addr := New("addr", "")
+		addr.Prefix = "a-"
+		addr.Add(&Text{Name: "city"})
+		f := New("profile", "/profile")
+		f.Prefix = "p-"
+		return f.Add(
+			&Div{Fields: []Field{&Text{Name: "name", Value: "default"}}},
+			&FieldSet{Fields: []Field{&Checkbox{Name: "news", Value: "yes", Checked: true}}},
+			&Radio{Name: "size", Value: "s"}, &Radio{Name: "size", Value: "l"},
+			&Select{Name: "tags", Multiple: true, Options: []OptionItem{
+				&Option{Value: "a", Selected: true},
+				&OptGroup{Options: []*Option{{Value: "b"}, {Value: "c"}}},
+			}},
+			&TextArea{Name: "bio"},
+			addr,
+		)
+	}
+
+	f := decl()
+	f.SetValues(url.Values{
+		"p-name": {""}, "p-size": {"l"}, "p-tags": {"b", "c"}, "p-bio": {"Hi"}, "p-a-city": {"Oslo"},
+	})
+	if v := f.AsValues().Encode(); v != "p-a-city=Oslo&p-bio=Hi&p-name=&p-size=l&p-tags=b&p-tags=c" {
+		t.Errorf("Unexpected values %s", v)
+	}
+
+	g := decl()
+	g.SetValues(*f.AsValues())
+	if f.AsValues().Encode() != g.AsValues().Encode() {
+		t.Errorf("Expected %s, got %s", f.AsValues().Encode(), g.AsValues().Encode())
+	}
+}
+
+func TestPopulateFromRequest(t *testing.T) {
+	decl := func(enctype string) *Form {
+		f := New("upload", "http://example.com/upload")
+		f.Method, f.Enctype, f.Prefix = "post", enctype, "u-"
+		return f.Add(&Text{Name: "title"}, &File{Name: "photo"})
+	}
+
+	src := decl(EnctypeMultipart)
+	src.Field("title").(*Text).Value = "Cat"
+	src.AttachFile("photo", "../cat.png", []byte("meow"))
+	r, err := src.NewRequest(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := decl(EnctypeMultipart)
+	if err := f.PopulateFromRequest(r); err != nil {
+		t.Fatalf("Failed to populate: %s", err)
+	}
+	if v := f.Field("title").(*Text).Value; v != "Cat" {
+		t.Errorf("Expected title Cat, got %q", v)
+	}
+	if a := f.Attachments("photo"); len(a) != 1 || a[0].Filename != "cat.png" || string(a[0].Content) != "meow" {
+		t.Errorf("Unexpected attachments %v", a)
+	}
+
+	// A multipart body is refused by a URL-encoded form.
+	r, _ = src.NewRequest(context.Background())
+	if err := decl("").PopulateFromRequest(r); err != ErrEnctype {
+		t.Errorf("Expected ErrEnctype, got %v", err)
+	}
+
+	src = decl("")
+	src.Field("title").(*Text).Value = strings.Repeat("x", 100)
+	r, _ = src.NewRequest(context.Background())
+	if err := decl("").PopulateFromRequestLimits(r, MultipartLimits{MaxMemory: 50}); err != ErrSubmissionTooLarge {
+		t.Errorf("Expected ErrSubmissionTooLarge, got %v", err)
+	}
+	r, _ = src.NewRequest(context.Background())
+	f = decl("")
+	if err := f.PopulateFromRequest(r); err != nil || len(f.Field("title").(*Text).Value) != 100 {
+		t.Errorf("Failed to populate from a URL-encoded body: %v", err)
+	}
+
+	src.Method = "get"
+	r, _ = src.NewRequest(context.Background())
+	f = decl(EnctypeMultipart)
+	if err := f.PopulateFromRequest(r); err != nil || len(f.Field("title").(*Text).Value) != 100 {
+		t.Errorf("Failed to populate from a query: %v", err)
+	}
+}
+
+// schemaDriver is a database/sql driver that answers every query with the
+// columns of a table, and records the query and its arguments.
+type schemaDriver struct {
+	rows  [][]driver.Value
+	query string
+	args  []driver.Value
+}
+
+func (d *schemaDriver) Open(name string) (driver.Conn, error) { return d, nil }
+func (d *schemaDriver) Prepare(query string) (driver.Stmt, error) {
+	d.query = query
+	return d, nil
+}
+func (d *schemaDriver) Close() error              { return nil }
+func (d *schemaDriver) Begin() (driver.Tx, error) { return nil, errors.New("Not supported") }
+func (d *schemaDriver) NumInput() int             { return -1 }
+func (d *schemaDriver) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("Not supported")
+}
+func (d *schemaDriver) Query(args []driver.Value) (driver.Rows, error) {
+	d.args = args
+	return &schemaRows{rows: d.rows}, nil
+}
+
+type schemaRows struct {
+	rows [][]driver.Value
+}
+
+func (r *schemaRows) Columns() []string {
+	return []string{"column_name", "data_type", "is_nullable", "character_maximum_length", "column_default"}
+}
+func (r *schemaRows) Close() error { return nil }
+func (r *schemaRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSchema(t *testing.T) {
+	d := &schemaDriver{rows: [][]driver.Value{
+		{"id", "integer", "NO", nil, "nextval('products_id_seq')"},
+		{"sku", "character varying", "NO", int64(12), nil},
+		{"description", "text", "YES", nil, nil},
+		{"unit_price", "numeric", "NO", nil, nil},
+		{"in_stock", "boolean", "NO", nil, nil},
+		{"released_on", "date", "YES", nil, nil},
+	}}
+	sql.Register("schematest", d)
+	db, err := sql.Open("schematest", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s := NewSchema(db)
+	s.Placeholder = DollarPlaceholder
+	s.Omit = []string{"id"}
+	f, err := s.Form(context.Background(), "shop.products")
+	if err != nil {
+		t.Fatalf("Failed to declare form: %s", err)
+	}
+	if !strings.Contains(d.query, "table_name = $1 AND table_schema = $2") || len(d.args) != 2 || d.args[0] != "products" || d.args[1] != "shop" {
+		t.Errorf("Unexpected query %q with %v", d.query, d.args)
+	}
+	if f.Name != "products" || f.Field("id") != nil {
+		t.Errorf("Unexpected form %q with id %v", f.Name, f.Field("id"))
+	}
+	if sku, ok := f.Field("sku").(*Text); !ok || sku.MaxLength != "12" || !sku.Required || sku.Label != "Sku" {
+		t.Errorf("Unexpected sku %#v", f.Field("sku"))
+	}
+	if desc, ok := f.Field("description").(*TextArea); !ok || desc.Required {
+		t.Errorf("Unexpected description %#v", f.Field("description"))
+	}
+	if price, ok := f.Field("unit_price").(*Number); !ok || price.Step != "any" || price.Label != "Unit price" {
+		t.Errorf("Unexpected unit price %#v", f.Field("unit_price"))
+	}
+	if stock, ok := f.Field("in_stock").(*Checkbox); !ok || stock.Required || stock.Value != "true" {
+		t.Errorf("Unexpected in stock %#v", f.Field("in_stock"))
+	}
+	if _, ok := f.Field("released_on").(*Date); !ok {
+		t.Errorf("Unexpected released on %#v", f.Field("released_on"))
+	}
+
+	d.rows = nil
+	if _, err := NewSchema(db).Form(context.Background(), "missing"); err != ErrNoTable {
+		t.Errorf("Expected ErrNoTable, got %v", err)
+	}
+	if !strings.Contains(d.query, "table_name = ? ORDER BY") {
+		t.Errorf("Unexpected query %q", d.query)
+	}
+}
+
+func TestSchemaStatements(t *testing.T) {
+	f := New("products", "/products").Add(
+		&Hidden{Name: "id", Value: "7"},
+		&Text{Name: "sku", Value: "A-1"},
+		&Number{Name: "unit_price"},
+		&Checkbox{Name: "in_stock", Value: "true"},
+		&Text{Name: "owner", Value: "matt"},
+	)
+	s := NewSchema(nil)
+
+	row, err := s.Values(f, "sku", "unit_price", "in_stock", "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(row) != 3 || row["sku"] != "A-1" || row["unit_price"] != nil || row["in_stock"] != false {
+		t.Errorf("Unexpected values %v", row)
+	}
+
+	q, args, err := s.Insert(f, "shop.products", "sku", "in_stock", "sku")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != "INSERT INTO shop.products (sku, in_stock) VALUES (?, ?)" || fmt.Sprint(args) != "[A-1 false]" {
+		t.Errorf("Unexpected insert %q %v", q, args)
+	}
+
+	s.Placeholder = DollarPlaceholder
+	q, args, err = s.Update(f, "products", "id", "id", "sku", "unit_price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q != "UPDATE products SET sku = $1, unit_price = $2 WHERE id = $3" || fmt.Sprint(args) != "[A-1 <nil> 7]" {
+		t.Errorf("Unexpected update %q %v", q, args)
+	}
+
+	if _, _, err := s.Insert(f, "products", "missing"); err != ErrNoColumns {
+		t.Errorf("Expected ErrNoColumns, got %v", err)
+	}
+	if _, _, err := s.Insert(f, "products", "sku; DROP TABLE products"); err == nil {
+		t.Error("Expected a column that is not an identifier to be refused")
+	}
+	f.Field("id").(*Hidden).Value = ""
+	if _, _, err := s.Update(f, "products", "id", "sku"); err == nil {
+		t.Error("Expected an update without a key to fail")
+	}
+
+	// A submission cannot change the row it updates.
+	fh := NewFormHandler(NewCache(), time.Minute)
+	edit := New("products", "/products").Add(&Hidden{Name: "id", Value: "7"}, &Text{Name: "sku"})
+	id, err := fh.Prepare(edit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit, err = fh.Retrieve(&url.Values{SecureTokenName: {id}, "id": {"8"}, "sku": {"B-2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Update(edit, "products", "id", "sku"); err == nil || !strings.Contains(err.Error(), "changed") {
+		t.Errorf("Expected a changed key to be refused, got %v", err)
+	}
+	edit = New("products", "/products").Add(&Hidden{Name: "id", Value: "7"}, &Text{Name: "sku"})
+	id, _ = fh.Prepare(edit)
+	edit, _ = fh.Retrieve(&url.Values{SecureTokenName: {id}, "id": {"7"}, "sku": {"B-2"}})
+	if _, args, err := s.Update(edit, "products", "id", "sku"); err != nil || fmt.Sprint(args) != "[B-2 7]" {
+		t.Errorf("Unexpected update %v: %v", args, err)
+	}
+}
+
+func TestDateTimeInputs(t *testing.T) {
+	f := New("trip", "/trip").Add(
+		&Month{Name: "month", Min: "2016-01"},
+		&Week{Name: "week"},
+		&DatetimeLocal{Name: "depart"},
+		&Search{Name: "q", Dirname: "q.dir"},
+	)
+	var b bytes.Buffer
+	if err := Render(&b, f, RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<input type="month" min="2016-01" name="month"/>`,
+		`<input type="week" name="week"/>`,
+		`<input type="datetime-local" name="depart"/>`,
+		`<input type="search" dirname="q.dir" name="q"/>`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Expected %s in %s", want, b.String())
+		}
+	}
+
+	Reconcile(f, &url.Values{
+		"month": {"2016-03"}, "week": {"2016-W09"}, "depart": {"2016-03-01T09:30"},
+		"q": {"tea"}, "q.dir": {"ltr"},
+	})
+	if v := f.AsValues().Encode(); v != "depart=2016-03-01T09%3A30&month=2016-03&q=tea&q.dir=ltr&week=2016-W09" {
+		t.Errorf("Unexpected values %s", v)
+	}
+
+	var trip struct {
+		Month  time.Time `form:"month"`
+		Week   string    `form:"week"`
+		Depart time.Time `form:"depart"`
+		Q      string    `form:"q"`
+	}
+	if err := Bind(*f.AsValues(), &trip); err != nil {
+		t.Fatal(err)
+	}
+	if trip.Month != time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC) || trip.Week != "2016-W09" ||
+		trip.Depart != time.Date(2016, 3, 1, 9, 30, 0, 0, time.UTC) || trip.Q != "tea" {
+		t.Errorf("Unexpected binding %+v", trip)
+	}
+
+	g, err := FromStruct(struct {
+		Month  time.Time `form:"month,widget=month"`
+		Depart time.Time `form:"depart,widget=datetime-local"`
+	}{trip.Month, trip.Depart})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, ok := g.Field("month").(*Month); !ok || m.Value != "2016-03" {
+		t.Errorf("Unexpected month %#v", g.Field("month"))
+	}
+	if d, ok := g.Field("depart").(*DatetimeLocal); !ok || d.Value != "2016-03-01T09:30" {
+		t.Errorf("Unexpected departure %#v", g.Field("depart"))
+	}
+}
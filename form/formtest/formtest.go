@@ -0,0 +1,419 @@
+// Package formtest checks that rendered forms behave as their models say.
+//
+// A form is rendered with the default renderer, and the markup is parsed
+// with an HTML5 parser, which builds the same document tree a browser
+// would. The tree is then compared with the form:
+//
+//   - Constraint attributes (required, min, max, step, pattern, and
+//     maxlength) must match the fields that declare them.
+//   - Every label must be associated with a labelable control.
+//   - The name/value pairs a browser would submit must match AsValues.
+//
+// Submit buttons, file inputs, and other fields that a browser only
+// submits in response to user action are not compared. External fields
+// are rendered with RenderField, after the form.
+//
+// This catches renderer regressions that are valid Go but not valid forms,
+// such as a label pointing at the wrong ID, or a checkbox rendered without
+// its checked attribute.
+package formtest
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/engine/form"
+	"golang.org/x/net/html"
+)
+
+// Check renders the form and reports each problem found as a test error.
+func Check(t testing.TB, f *form.Form) {
+	t.Helper()
+	problems, err := Problems(f)
+	if err != nil {
+		t.Fatalf("Could not render form %q: %s", f.Name, err)
+	}
+	for _, p := range problems {
+		t.Errorf("Form %q: %s", f.Name, p)
+	}
+}
+
+// Problems renders the form, and returns a description of each difference
+// between the markup and the form.
+//
+// An error is returned if the form cannot be rendered or parsed.
+func Problems(f *form.Form) ([]string, error) {
+	var buf bytes.Buffer
+	if err := form.Render(&buf, f, form.RenderOptions{}); err != nil {
+		return nil, err
+	}
+	for _, e := range f.External {
+		if name := nameOf(e); len(name) > 0 {
+			if err := f.RenderField(&buf, name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	doc, err := html.Parse(&buf)
+	if err != nil {
+		return nil, err
+	}
+	fn := find(doc, func(n *html.Node) bool { return n.Data == "form" })
+	if fn == nil {
+		return []string{"no form element was rendered"}, nil
+	}
+
+	controls := owned(doc, fn, attrOf(fn, "id"))
+	problems := checkConstraints(f, controls)
+	problems = append(problems, checkLabels(doc)...)
+	problems = append(problems, checkValues(f, controls)...)
+	return problems, nil
+}
+
+// constraints are the fields of an Input (and similar fields) that become
+// constraint attributes.
+var constraints = []string{"Max", "MaxLength", "Min", "Pattern", "Step"}
+
+// checkConstraints compares the constraint attributes of the controls with
+// the fields of the same name.
+func checkConstraints(f *form.Form, controls []*html.Node) []string {
+	byName := map[string][]*html.Node{}
+	for _, c := range controls {
+		if name := attrOf(c, "name"); len(name) > 0 {
+			byName[name] = append(byName[name], c)
+		}
+	}
+
+	problems := []string{}
+	seen := map[string]int{}
+	walk(f, func(field form.Field, name string) {
+		v := reflect.Indirect(reflect.ValueOf(field))
+		if v.Kind() != reflect.Struct {
+			return
+		}
+		i := seen[name]
+		seen[name]++
+		nodes := byName[name]
+		if i >= len(nodes) {
+			problems = append(problems, fmt.Sprintf("%q was not rendered as a control", name))
+			return
+		}
+		n := nodes[i]
+		if r := v.FieldByName("Required"); r.Kind() == reflect.Bool && r.Bool() != hasAttr(n, "required") {
+			problems = append(problems, fmt.Sprintf("%q: required is %t, but markup disagrees", name, r.Bool()))
+		}
+		for _, c := range constraints {
+			fv := v.FieldByName(c)
+			if fv.Kind() != reflect.String {
+				continue
+			}
+			key := strings.ToLower(c)
+			if got := attrOf(n, key); got != fv.String() {
+				problems = append(problems, fmt.Sprintf("%q: %s is %q, but markup has %q", name, key, fv.String(), got))
+			}
+		}
+	})
+	return problems
+}
+
+// labelable are the elements that a label can be associated with.
+var labelable = map[string]bool{
+	"button": true, "input": true, "meter": true, "output": true,
+	"progress": true, "select": true, "textarea": true, "keygen": true,
+}
+
+// checkLabels verifies that every label is associated with a control.
+func checkLabels(doc *html.Node) []string {
+	ids := map[string]*html.Node{}
+	each(doc, func(n *html.Node) {
+		if id := attrOf(n, "id"); len(id) > 0 {
+			if _, ok := ids[id]; !ok {
+				ids[id] = n
+			}
+		}
+	})
+
+	problems := []string{}
+	each(doc, func(n *html.Node) {
+		if n.Data != "label" {
+			return
+		}
+		if hasAttr(n, "for") {
+			id := attrOf(n, "for")
+			if target, ok := ids[id]; !ok {
+				problems = append(problems, fmt.Sprintf("label for %q: no element has that ID", id))
+			} else if !isLabelable(target) {
+				problems = append(problems, fmt.Sprintf("label for %q: a %s cannot be labeled", id, target.Data))
+			}
+			return
+		}
+		if find(n, func(c *html.Node) bool { return c != n && isLabelable(c) }) == nil {
+			problems = append(problems, fmt.Sprintf("label %q is not associated with a control", text(n)))
+		}
+	})
+	return problems
+}
+
+// checkValues compares what a browser would submit with AsValues.
+func checkValues(f *form.Form, controls []*html.Node) []string {
+	ignored := map[string]bool{}
+	walk(f, func(field form.Field, name string) {
+		switch field := field.(type) {
+		case *form.Submit, *form.Button, *form.ButtonInput, *form.Reset, *form.File, *form.Keygen:
+			ignored[name] = true
+		case *form.Image:
+			ignored[name], ignored[name+".x"], ignored[name+".y"] = true, true, true
+		case *form.Computed:
+			ignored[name] = !field.ReadOnly
+		}
+	})
+
+	want := map[string][]string{}
+	for k, v := range *f.AsValues() {
+		if !ignored[k] {
+			want[k] = v
+		}
+	}
+	got := map[string][]string{}
+	for _, c := range controls {
+		for _, nv := range submitted(c) {
+			if !ignored[nv[0]] {
+				got[nv[0]] = append(got[nv[0]], nv[1])
+			}
+		}
+	}
+
+	keys := []string{}
+	for k := range want {
+		keys = append(keys, k)
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	problems := []string{}
+	for _, k := range keys {
+		if !reflect.DeepEqual(want[k], got[k]) {
+			problems = append(problems, fmt.Sprintf("%q: model submits %q, but markup submits %q", k, want[k], got[k]))
+		}
+	}
+	return problems
+}
+
+// submitted returns the name/value pairs a browser submits for a control.
+//
+// No submitter is assumed, so buttons are never submitted.
+func submitted(n *html.Node) [][2]string {
+	name := attrOf(n, "name")
+	if len(name) == 0 || disabled(n) {
+		return nil
+	}
+	var pairs [][2]string
+	switch n.Data {
+	case "input":
+		switch strings.ToLower(attrOf(n, "type")) {
+		case "submit", "image", "reset", "button", "file":
+			return nil
+		case "checkbox", "radio":
+			if !hasAttr(n, "checked") {
+				return nil
+			}
+			v := attrOf(n, "value")
+			if !hasAttr(n, "value") {
+				v = "on"
+			}
+			pairs = append(pairs, [2]string{name, v})
+		default:
+			pairs = append(pairs, [2]string{name, attrOf(n, "value")})
+		}
+		if dn := attrOf(n, "dirname"); len(dn) > 0 && hasAttr(n, "dir") {
+			pairs = append(pairs, [2]string{dn, attrOf(n, "dir")})
+		}
+	case "textarea":
+		pairs = append(pairs, [2]string{name, text(n)})
+		if dn := attrOf(n, "dirname"); len(dn) > 0 && hasAttr(n, "dir") {
+			pairs = append(pairs, [2]string{dn, attrOf(n, "dir")})
+		}
+	case "select":
+		var first *html.Node
+		each(n, func(o *html.Node) {
+			if o.Data != "option" || disabled(o) {
+				return
+			}
+			if first == nil {
+				first = o
+			}
+			if hasAttr(o, "selected") {
+				pairs = append(pairs, [2]string{name, optionValue(o)})
+			}
+		})
+		// A single-line select always has a selection, the first option
+		// by default.
+		if len(pairs) == 0 && first != nil && !hasAttr(n, "multiple") && size(n) <= 1 {
+			pairs = append(pairs, [2]string{name, optionValue(first)})
+		}
+	}
+	return pairs
+}
+
+// owned returns the controls whose form owner is fn, in document order.
+func owned(doc, fn *html.Node, id string) []*html.Node {
+	controls := []*html.Node{}
+	each(doc, func(n *html.Node) {
+		switch n.Data {
+		case "input", "select", "textarea", "button", "output", "keygen":
+		default:
+			return
+		}
+		if hasAttr(n, "form") {
+			if len(id) > 0 && attrOf(n, "form") == id {
+				controls = append(controls, n)
+			}
+			return
+		}
+		for p := n.Parent; p != nil; p = p.Parent {
+			if p == fn {
+				controls = append(controls, n)
+				return
+			}
+		}
+	})
+	return controls
+}
+
+// walk calls fn with each named field of a form and its submitted name.
+func walk(f *form.Form, fn func(form.Field, string)) {
+	prefix := f.PrefixFunc
+	if prefix == nil {
+		prefix = form.DefaultPrefixFunc
+	}
+	var visit func([]form.Field)
+	visit = func(fields []form.Field) {
+		for _, field := range fields {
+			switch c := field.(type) {
+			case *form.Div:
+				visit(c.Fields)
+				continue
+			case *form.FieldSet:
+				visit(c.Fields)
+				continue
+			case *form.Label:
+				visit(c.Fields)
+				continue
+			case *form.Form:
+				walk(c, fn)
+				continue
+			}
+			if name := nameOf(field); len(name) > 0 {
+				if len(f.Prefix) > 0 && name != form.SecureTokenName && name != form.FormIDName {
+					name = prefix(f.Prefix, name)
+				}
+				fn(field, name)
+			}
+		}
+	}
+	visit(f.Fields)
+	visit(f.External)
+}
+
+func nameOf(f form.Field) string {
+	v := reflect.Indirect(reflect.ValueOf(f))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if n := v.FieldByName("Name"); n.Kind() == reflect.String {
+		return n.String()
+	}
+	return ""
+}
+
+func isLabelable(n *html.Node) bool {
+	if n.Type != html.ElementNode || !labelable[n.Data] {
+		return false
+	}
+	return n.Data != "input" || !strings.EqualFold(attrOf(n, "type"), "hidden")
+}
+
+// disabled returns true if the element or an enclosing fieldset is disabled.
+func disabled(n *html.Node) bool {
+	if hasAttr(n, "disabled") {
+		return true
+	}
+	for p := n.Parent; p != nil; p = p.Parent {
+		if (p.Data == "fieldset" || p.Data == "optgroup") && hasAttr(p, "disabled") {
+			return true
+		}
+	}
+	return false
+}
+
+// size returns the size of a select, which is 1 by default.
+func size(n *html.Node) int {
+	if s, err := strconv.Atoi(attrOf(n, "size")); err == nil {
+		return s
+	}
+	return 1
+}
+
+func optionValue(o *html.Node) string {
+	if hasAttr(o, "value") {
+		return attrOf(o, "value")
+	}
+	return strings.TrimSpace(text(o))
+}
+
+func attrOf(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// text returns the text content of a node.
+func text(n *html.Node) string {
+	var b bytes.Buffer
+	each(n, func(c *html.Node) {
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		}
+	})
+	return b.String()
+}
+
+// each calls fn for n and each of its descendants, in document order.
+func each(n *html.Node, fn func(*html.Node)) {
+	fn(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		each(c, fn)
+	}
+}
+
+// find returns the first node, in document order, that matches.
+func find(n *html.Node, match func(*html.Node) bool) *html.Node {
+	var found *html.Node
+	each(n, func(c *html.Node) {
+		if found == nil && c.Type == html.ElementNode && match(c) {
+			found = c
+		}
+	})
+	return found
+}
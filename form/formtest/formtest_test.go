@@ -0,0 +1,66 @@
+package formtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/engine/form"
+)
+
+func TestCheck(t *testing.T) {
+	f := form.New("signup", "/signup")
+	f.Prefix = "a-"
+	f.Add(
+		&form.Text{Name: "user", Value: "matt", Label: "User", Required: true, MaxLength: "20", Pattern: "[a-z]+"},
+		&form.Number{Name: "age", Value: "40", Min: "13", Max: "120"},
+		&form.Checkbox{Name: "news", Value: "yes", Checked: true, Label: "News"},
+		&form.Checkbox{Name: "news", Value: "no"},
+		&form.FieldSet{Fields: []form.Field{
+			&form.Radio{Name: "plan", Value: "free"},
+			&form.Radio{Name: "plan", Value: "pro", Checked: true},
+		}},
+		&form.Select{Name: "color", Options: []form.OptionItem{
+			&form.Option{Value: "red"},
+			&form.OptGroup{Label: "Cool", Options: []*form.Option{
+				{Value: "blue", Selected: true},
+			}},
+		}},
+		&form.TextArea{Name: "bio", Value: "Hi"},
+		&form.Label{Field: "age", Text: "Age"},
+		&form.Submit{Name: "go", Value: "Go"},
+	)
+	f.AddExternal(&form.Hidden{Name: "ref", Value: "home"})
+
+	Check(t, f)
+}
+
+func TestProblems(t *testing.T) {
+	f := form.New("broken", "/broken")
+	f.Add(
+		&form.Label{For: "nowhere", Text: "Missing"},
+		&form.Label{Text: "Empty"},
+		&form.Select{Name: "size", Options: []form.OptionItem{
+			&form.Option{Value: "small"},
+			&form.Option{Value: "large"},
+		}},
+	)
+
+	problems, err := Problems(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{
+		`label for "nowhere"`,
+		`label "Empty"`,
+		`"size": model submits []`,
+	}
+	all := strings.Join(problems, "\n")
+	if len(problems) != len(expect) {
+		t.Errorf("Expected %d problems, got %q", len(expect), problems)
+	}
+	for _, e := range expect {
+		if !strings.Contains(all, e) {
+			t.Errorf("Expected a problem containing %q, got %q", e, problems)
+		}
+	}
+}
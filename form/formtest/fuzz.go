@@ -0,0 +1,65 @@
+package formtest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+
+	"github.com/Masterminds/engine/form"
+)
+
+// Fuzzing helpers.
+//
+// Each of these feeds arbitrary input to one of the form package's
+// decoders, and returns an error if the decoder panics. Decoding errors are
+// expected, and are not returned. Applications can use these in their own
+// fuzz targets, with their own forms:
+//
+// 	func FuzzSignup(f *testing.F) {
+// 		f.Add("email=a%40example.com")
+// 		f.Fuzz(func(t *testing.T, q string) {
+// 			if err := formtest.FuzzReconcile(newSignupForm(), q); err != nil {
+// 				t.Fatal(err)
+// 			}
+// 		})
+// 	}
+
+// FuzzReconcile reconciles a URL-encoded submission with a form.
+//
+// The form is modified. Pairs that cannot be parsed are skipped, as they
+// are by net/http.
+func FuzzReconcile(f *form.Form, query string) (err error) {
+	defer recoverTo(&err)
+	vals, _ := url.ParseQuery(query)
+	form.Reconcile(f, &vals)
+	f.AsValues()
+	return nil
+}
+
+// FuzzDecode decodes an encoded form definition (see form.DecodeForm), and
+// renders the result.
+func FuzzDecode(data []byte) (err error) {
+	defer recoverTo(&err)
+	if f, derr := form.DecodeForm(data); derr == nil {
+		form.Render(ioutil.Discard, f, form.RenderOptions{})
+	}
+	return nil
+}
+
+// FuzzReformat parses markup as an HTML fragment and renders it in both
+// modes (see form.Reformat).
+func FuzzReformat(markup string) (err error) {
+	defer recoverTo(&err)
+	for _, mode := range []form.RenderMode{form.Compact, form.Pretty} {
+		form.Reformat(ioutil.Discard, bytes.NewBufferString(markup), form.RenderOptions{Mode: mode})
+	}
+	return nil
+}
+
+// recoverTo converts a panic into an error.
+func recoverTo(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("panic: %v", r)
+	}
+}
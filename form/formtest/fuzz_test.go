@@ -0,0 +1,81 @@
+package formtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Masterminds/engine/form"
+)
+
+// kitchenSink returns a form with one of each kind of field that decodes
+// submitted values.
+func kitchenSink() *form.Form {
+	f := form.New("sink", "/sink")
+	f.Add(
+		&form.Text{Name: "text", Dirname: "text.dir"},
+		&form.TextArea{Name: "area", Dirname: "area.dir"},
+		&form.Checkbox{Name: "check", Value: "on"},
+		&form.Radio{Name: "radio", Value: "a"},
+		&form.Select{Name: "select", Options: []form.OptionItem{
+			&form.Option{Value: "a"},
+			&form.OptGroup{Options: []*form.Option{{Value: "b"}}},
+		}},
+		&form.Email{Name: "email", Multiple: true},
+		&form.URL{Name: "url"},
+		&form.Tel{Name: "tel"},
+		form.NewInteger("int"),
+		form.NewDecimal("dec", 2),
+		&form.Color{Name: "color"},
+		&form.AlphaColor{Name: "alpha"},
+		form.NewMoney("price", "USD", "JPY", "BHD"),
+		&form.Duration{Name: "wait", Units: []time.Duration{time.Second, time.Hour}},
+		&form.Duration{Name: "shift"},
+		&form.Image{Name: "image"},
+		&form.Div{Fields: []form.Field{&form.Hidden{Name: "hidden"}}},
+	)
+	f.Embed(form.New("sub", ""))
+	return f
+}
+
+func FuzzReconcileSubmission(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"text=a&text.dir=rtl&check=on&radio=a&select=b",
+		"email=a%40example.com,b%40example.com&url=example.com&tel=%2B1%20555",
+		"int=-9223372036854775808&dec=1.005&color=%23fff&alpha=%23ff000080",
+		"price=19.99&price.currency=BHD&wait=1e309&wait.unit=3600000000000",
+		"shift=99999999999:99&image.x=-1&image.y=1e3",
+		"%zz=1&;&&=",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, q string) {
+		if err := FuzzReconcile(kitchenSink(), q); err != nil {
+			t.Fatalf("%q: %s", q, err)
+		}
+	})
+}
+
+func FuzzDecodeForm(f *testing.F) {
+	b, err := form.EncodeForm(kitchenSink())
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(b)
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := FuzzDecode(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func FuzzReformatMarkup(f *testing.F) {
+	f.Add(`<form><div><input name="a"></div><select><option>b</select></form>`)
+	f.Add(`<table><form><tr><td><textarea>`)
+	f.Fuzz(func(t *testing.T, markup string) {
+		if err := FuzzReformat(markup); err != nil {
+			t.Fatalf("%q: %s", markup, err)
+		}
+	})
+}
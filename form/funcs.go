@@ -0,0 +1,66 @@
+package form
+
+import (
+	"bytes"
+	"html/template"
+
+	"golang.org/x/net/html"
+)
+
+// FuncMap returns template functions for placing individual fields in a page.
+//
+// The functions are:
+//
+//   - field FORM NAME: returns the field with the given name, or nil
+//   - render FIELD: renders the field's widget (without a label)
+//   - label FIELD: renders a label element for the field's Label
+//   - errors FORM NAME: returns the error messages for the named field
+//
+// For example:
+//
+//	{{ $email := field .Form "email" }}
+//	<p>{{label $email}} {{render $email}}</p>
+//	{{range errors .Form "email"}}<span class="error">{{.}}</span>{{end}}
+//
+// These can be added to an engine's functions with NewEngine.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"field":  tplField,
+		"render": tplRender,
+		"label":  tplLabel,
+		"errors": tplErrors,
+	}
+}
+
+func tplField(f *Form, name string) Field {
+	if f == nil {
+		return nil
+	}
+	return f.Field(name)
+}
+
+func tplRender(f Field) (template.HTML, error) {
+	return renderHTML(elementOf(nil, f))
+}
+
+func tplLabel(f Field) (template.HTML, error) {
+	return renderHTML(labelNode(nil, f, labelOf(f)))
+}
+
+func tplErrors(f *Form, name string) []string {
+	if f == nil {
+		return nil
+	}
+	return f.Errors.Get(name)
+}
+
+func renderHTML(n *html.Node) (template.HTML, error) {
+	if n == nil {
+		return "", nil
+	}
+	var b bytes.Buffer
+	if err := html.Render(&b, n); err != nil {
+		return "", err
+	}
+	return template.HTML(b.String()), nil
+}
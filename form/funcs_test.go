@@ -0,0 +1,30 @@
+package form
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+)
+
+func TestFuncMap(t *testing.T) {
+	f := New("signup", "/signup")
+	f.Fields = []Field{
+		&FieldSet{Fields: []Field{
+			&Email{Name: "email", Label: "Email"},
+		}},
+	}
+	f.Errors.Add("email", "is required")
+
+	tpl := template.Must(template.New("page").Funcs(FuncMap()).Parse(
+		`{{ $e := field . "email" }}<p>{{label $e}}{{render $e}}</p>{{range errors . "email"}}<b>{{.}}</b>{{end}}`,
+	))
+
+	var b bytes.Buffer
+	if err := tpl.Execute(&b, f); err != nil {
+		t.Fatalf("Failed to execute template: %s", err)
+	}
+	expect := `<p><label for="email">Email</label><input type="email" name="email" id="email"/></p><b>is required</b>`
+	if b.String() != expect {
+		t.Errorf("Unexpected output:\n%s", b.String())
+	}
+}
@@ -0,0 +1,61 @@
+package form
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// Forms, fields, and edit locks are registered with encoding/gob so that
+// they can be stored by caches that serialize their values (see
+// cache.NewFile).
+func init() {
+	for _, f := range []interface{}{
+		&Form{}, String(""),
+		&Div{}, &FieldSet{}, &Table{}, &Lazy{}, &Label{}, &Button{}, &Keygen{}, &Output{},
+		&Computed{}, &Money{}, &Duration{}, &Progress{}, &Meter{}, &Select{}, &DataList{},
+		&OptGroup{}, &Option{}, &TextArea{}, &Script{}, &Style{},
+		&Input{}, &Password{}, &Text{}, &Submit{}, &Tel{}, &URL{}, &Email{},
+		&Date{}, &Time{}, &Month{}, &Week{}, &DatetimeLocal{}, &Search{}, &Number{}, &Range{}, &Color{}, &AlphaColor{}, &Checkbox{},
+		&Radio{}, &File{}, &Image{}, &Reset{}, &ButtonInput{}, &Hidden{},
+		&EditLock{},
+	} {
+		gob.Register(f)
+	}
+}
+
+// gobForm has the fields of a Form, but not its methods.
+type gobForm Form
+
+// gobEnvelope carries a form along with its unexported lifecycle data.
+type gobEnvelope struct {
+	Form          *gobForm
+	State         State
+	Token         string
+	UploadKeys    map[string][]string
+	VersionSigned bool
+	Carried       []string
+}
+
+// GobEncode implements gob.GobEncoder.
+//
+// This preserves the form's lifecycle state when it is serialized. Function
+// fields (such as Computed.Compute and PrefixFunc) are not encoded.
+func (f *Form) GobEncode() ([]byte, error) {
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(&gobEnvelope{(*gobForm)(f), f.state, f.token, f.uploadKeys, f.versionSigned, f.carried})
+	return b.Bytes(), err
+}
+
+// GobDecode implements gob.GobDecoder.
+func (f *Form) GobDecode(data []byte) error {
+	env := &gobEnvelope{Form: (*gobForm)(f)}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(env); err != nil {
+		return err
+	}
+	f.state = env.State
+	f.token = env.Token
+	f.uploadKeys = env.UploadKeys
+	f.versionSigned = env.VersionSigned
+	f.carried = env.Carried
+	return nil
+}
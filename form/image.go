@@ -0,0 +1,38 @@
+package form
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Point is a position on an image, in CSS pixels from its top left corner.
+type Point struct {
+	X, Y int
+}
+
+// coordNames returns the names under which an image input's coordinates are submitted.
+//
+// User agents submit name.x and name.y, or simply x and y if the image has
+// no name.
+func coordNames(name string) (string, string) {
+	if len(name) == 0 {
+		return "x", "y"
+	}
+	return name + ".x", name + ".y"
+}
+
+// reconcileCoords sets an Image's Coords from the submitted data.
+//
+// Coords is left alone if the image was not used to submit the form.
+func reconcileCoords(img *Image, data *url.Values) {
+	xn, yn := coordNames(img.Name)
+	x, err := strconv.Atoi(data.Get(xn))
+	if err != nil {
+		return
+	}
+	y, err := strconv.Atoi(data.Get(yn))
+	if err != nil {
+		return
+	}
+	img.Coords = &Point{X: x, Y: y}
+}
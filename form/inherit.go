@@ -0,0 +1,115 @@
+package form
+
+import (
+	"reflect"
+
+	"golang.org/x/net/html"
+)
+
+// Inherited returns the Dir, Lang, and Translate attributes that apply to a field.
+//
+// A field that does not set one of these attributes inherits it from the
+// nearest container (Div, FieldSet, Label, or embedded form) that does,
+// and finally from the form itself. Only those three attributes are set on
+// the returned HTML.
+//
+// User agents do this for fields rendered inside of the form element. This
+// is needed when a field is rendered elsewhere, as with RenderField and
+// external fields. If the field is not on the form, only its own
+// attributes are returned.
+func (f *Form) Inherited(field Field) HTML {
+	h := HTML{}
+	if own := htmlOf(field); own != nil {
+		h = inherit(h, *own)
+	}
+	for _, a := range f.ancestorsOf(field) {
+		if ah := htmlOf(a); ah != nil {
+			h = inherit(h, *ah)
+		}
+	}
+	return inherit(h, f.HTML)
+}
+
+// ResolveInheritance sets Dir, Lang, and Translate on external fields that
+// do not set them.
+//
+// External fields are rendered outside of the form element, so they do not
+// inherit these from the form in the document. This is called when the form
+// is prepared or rendered.
+func (f *Form) ResolveInheritance() {
+	for _, e := range f.External {
+		if h := htmlOf(e); h != nil {
+			*h = inherit(*h, f.HTML)
+		}
+	}
+}
+
+// ancestorsOf returns the containers of a field, nearest first.
+func (f *Form) ancestorsOf(field Field) []Field {
+	var find func(fields []Field, path []Field) []Field
+	find = func(fields []Field, path []Field) []Field {
+		for _, c := range fields {
+			if sameField(c, field) {
+				return path
+			}
+			var children []Field
+			switch c := c.(type) {
+			case *Div:
+				children = c.Fields
+			case *FieldSet:
+				children = c.Fields
+			case *Lazy:
+				// A Lazy is not a container of its own, so its fields
+				// inherit from the Lazy's ancestors.
+				if p := find(c.Resolve(), path); p != nil {
+					return p
+				}
+				continue
+			case *Label:
+				children = c.Fields
+			case *Table:
+				children = c.Fields
+			case *Form:
+				children = c.allFields()
+			default:
+				continue
+			}
+			if p := find(children, append([]Field{c}, path...)); p != nil {
+				return p
+			}
+		}
+		return nil
+	}
+	return find(f.allFields(), []Field{})
+}
+
+// sameField returns true if a and b are the same pointer.
+//
+// Fields that are not pointers are never the same, since they may not be
+// comparable.
+func sameField(a, b Field) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Kind() != reflect.Ptr || vb.Kind() != reflect.Ptr {
+		return false
+	}
+	return va.Type() == vb.Type() && va.Pointer() == vb.Pointer()
+}
+
+// inherit fills the inheritable attributes of h that are not set from parent.
+func inherit(h, parent HTML) HTML {
+	if len(h.Dir) == 0 {
+		h.Dir = parent.Dir
+	}
+	if len(h.Lang) == 0 {
+		h.Lang = parent.Lang
+	}
+	if len(h.Translate) == 0 {
+		h.Translate = parent.Translate
+	}
+	return h
+}
+
+// inheritedAttrs returns the inheritable attributes that are set on h.
+func inheritedAttrs(h HTML) []html.Attribute {
+	return structToAttrs(h, "Dir", "Lang", "Translate")
+}
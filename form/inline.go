@@ -0,0 +1,145 @@
+package form
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Fragment returns a form holding only the field at the path, for inline
+// ("click to edit") editing.
+//
+// The fragment has the form's Name, Action, Prefix, PrefixFunc, Defaults,
+// Echo, and Sensitive list, and the field's validators, so it is submitted
+// and checked like the whole form. It holds a copy of the field, so the
+// form is not changed by edits to the fragment. Prepare the fragment to
+// give it its own token, render it with RenderFragment, and handle its
+// submissions with FragmentHandler.
+//
+// The field must belong to the form itself, not to an embedded form. If
+// there is no such field, ErrFieldNotFound is returned.
+func (f *Form) Fragment(path string) (*Form, error) {
+	field := f.FieldAt(path)
+	own := false
+	walkFields(f.allFields(), func(ff Field) {
+		own = own || (field != nil && sameField(ff, field))
+	})
+	if !own {
+		return nil, ErrFieldNotFound
+	}
+	return f.fragment(field), nil
+}
+
+// fragment returns a form holding a copy of the field.
+func (f *Form) fragment(field Field) *Form {
+	frag := &Form{
+		Name:       f.Name,
+		Action:     f.Action,
+		Method:     "post",
+		Prefix:     f.Prefix,
+		PrefixFunc: f.PrefixFunc,
+		Defaults:   f.Defaults,
+		Echo:       f.Echo,
+		Sensitive:  f.Sensitive,
+		Security:   f.Security,
+		Fields:     []Field{copyField(field)},
+	}
+	if vv := f.validators[nameOf(field)]; len(vv) > 0 {
+		frag.AddValidator(nameOf(field), vv...)
+	}
+	return frag
+}
+
+// fragmentField returns the field of a fragment.
+func (f *Form) fragmentField() Field {
+	for _, field := range f.Fields {
+		if name := nameOf(field); name != SecureTokenName && name != FormIDName {
+			return field
+		}
+	}
+	return nil
+}
+
+// RenderFragment writes a prepared fragment (see Form.Fragment) to w as
+// HTML.
+//
+// The fragment is a form element with the class "fragment", holding the
+// field as RenderField writes it, with its label and errors, followed by
+// the security token. A response from FragmentHandler is a whole new
+// fragment, so client-side code replaces the form element with it.
+func RenderFragment(w io.Writer, frag *Form) error {
+	field := frag.fragmentField()
+	if field == nil {
+		return ErrFieldNotFound
+	}
+	var b bytes.Buffer
+	if err := frag.RenderField(&b, nameOf(field)); err != nil {
+		return err
+	}
+
+	n := &html.Node{Type: html.ElementNode, DataAtom: atom.Form, Data: "form"}
+	n.Attr = attr(n.Attr, "action", frag.Action)
+	n.Attr = attr(n.Attr, "method", "post")
+	n.Attr = attr(n.Attr, "class", "fragment")
+	n.AppendChild(&html.Node{Type: html.RawNode, Data: b.String()})
+	if len(frag.token) > 0 {
+		tok := Hidden{Name: SecureTokenName, Value: frag.token}
+		n.AppendChild(tok.Element())
+	}
+	return html.Render(w, n)
+}
+
+// FragmentHandler returns a handler for the submissions of fragments (see
+// Form.Fragment).
+//
+// A fragment is submitted with PATCH (or POST, for clients without
+// scripts), and retrieved with RetrieveRequest, so its token is used up.
+// Its validators are run, and if the value is valid, save is called with
+// the fragment to store it; an error from save is shown as an error of the
+// field. The response is a new fragment with its own token, holding the
+// value as submitted and any errors, rendered with RenderFragment. Its
+// status is 200 if the value was saved, and 422 otherwise.
+func (h *FormHandler) FragmentHandler(save func(ctx context.Context, frag *Form) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" && r.Method != "POST" {
+			w.Header().Set("Allow", "PATCH, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		handler := h.WithContext(r.Context())
+		frag, err := handler.RetrieveRequest(r)
+		if frag == nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		field := frag.fragmentField()
+		if field == nil {
+			http.Error(w, ErrFieldNotFound.Error(), http.StatusBadRequest)
+			return
+		}
+		if err == nil {
+			err = frag.Validate(r.Context())
+		}
+		if err == nil {
+			if err = save(r.Context(), frag); err != nil {
+				frag.Errors.Add(nameOf(field), err.Error())
+			}
+		}
+
+		next := frag.fragment(field)
+		next.Errors = frag.Errors
+		if _, perr := handler.Prepare(next); perr != nil {
+			http.Error(w, perr.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+		RenderFragment(w, next)
+	})
+}
@@ -1,8 +1,7 @@
 package form
 
 import (
-//"golang.org/x/net/html"
-//"golang.org/x/net/html/atom"
+	"golang.org/x/net/html"
 )
 
 // Password provides a field for obscured text.
@@ -30,6 +29,23 @@ type Date Input
 // Time provides a time form entry field.
 type Time Input
 
+// Month provides a month and year entry field. Its value is formatted as
+// "2006-01".
+type Month Input
+
+// Week provides a week and year entry field. Its value is an ISO 8601
+// week, formatted as "2006-W01". The time package cannot parse weeks, so
+// Bind stores a week in a string, not a time.Time.
+type Week Input
+
+// DatetimeLocal provides a date and time entry field, without a time zone.
+// Its value is formatted as "2006-01-02T15:04".
+type DatetimeLocal Input
+
+// Search provides a text field for search terms. User agents may style it
+// differently from a Text field, and offer to clear it.
+type Search Input
+
 // Number provides a numeric field.
 type Number Input
 
@@ -52,8 +68,16 @@ type File Input
 type Image Input
 
 // Reset provides a button that is pre-wired to reset the form.
+//
+// Its Value is the button's text. For a reset button with other content,
+// use NewResetButton.
 type Reset Input
 
+// NewReset creates a new Reset input with the given text.
+func NewReset(text string) *Reset {
+	return &Reset{Value: text}
+}
+
 // Button provides a generic button.
 // This is deprecated in favor of the Button type.
 type ButtonInput Input
@@ -71,10 +95,108 @@ type Input struct {
 	Autofocus, Checked, Disabled, Multiple, ReadOnly, Required                     bool
 	Height, Width, Size                                                            uint64
 
+	// FormNoValidate skips constraint validation when the form is submitted
+	// with this input. It applies to Submit and Image inputs, which allows
+	// a "Save draft" button to bypass validation while "Submit" enforces it.
+	FormNoValidate bool
+
+	// Capture asks a File input's user agent to take a new photo or video
+	// with a camera, rather than choose a file: CaptureUser for the camera
+	// that faces the user, or CaptureEnvironment for the one that faces
+	// away.
+	Capture string
+
 	// Technically, this is not an attribute of an Input field, but we put it here
 	// to simplify the process of labeling fields.
 	Label string
+
+	// Coords is also not an attribute. It is set on an Image after
+	// submission to the point where the image was clicked.
+	Coords *Point
 }
 
+// Values for Input.Capture.
+const (
+	CaptureUser        = "user"
+	CaptureEnvironment = "environment"
+)
+
 // Field describes any form element.
 type Field interface{}
+
+// inputElement creates an input html.Node of the given type.
+//
+// If typ is empty, no type attribute is set, and the user agent will
+// treat the input as text.
+func inputElement(typ string, in *Input) *html.Node {
+	return inputNode(nil, typ, in)
+}
+
+// Element retrieves the input as an untyped html.Node of type ElementNode.
+func (i *Input) Element() *html.Node { return inputElement("", i) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Password) Element() *html.Node { return inputElement("password", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Text) Element() *html.Node { return inputElement("text", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Submit) Element() *html.Node { return inputElement("submit", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Tel) Element() *html.Node { return inputElement("tel", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *URL) Element() *html.Node { return inputElement("url", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Email) Element() *html.Node { return inputElement("email", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Date) Element() *html.Node { return inputElement("date", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Time) Element() *html.Node { return inputElement("time", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Month) Element() *html.Node { return inputElement("month", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Week) Element() *html.Node { return inputElement("week", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *DatetimeLocal) Element() *html.Node { return inputElement("datetime-local", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Search) Element() *html.Node { return inputElement("search", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Number) Element() *html.Node { return inputElement("number", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Range) Element() *html.Node { return inputElement("range", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Color) Element() *html.Node { return inputElement("color", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Checkbox) Element() *html.Node { return inputElement("checkbox", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Radio) Element() *html.Node { return inputElement("radio", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *File) Element() *html.Node { return inputElement("file", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Image) Element() *html.Node { return inputElement("image", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Reset) Element() *html.Node { return inputElement("reset", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *ButtonInput) Element() *html.Node { return inputElement("button", (*Input)(i)) }
+
+// Element retrieves the field as an html.Node of type ElementNode.
+func (i *Hidden) Element() *html.Node { return inputElement("hidden", (*Input)(i)) }
@@ -1,5 +1,10 @@
 package form
 
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
 // Keygen describes the keygen form field type.
 type Keygen struct {
 	HTML
@@ -7,3 +12,16 @@ type Keygen struct {
 	Autofocus, Disabled            bool
 	Value                          string
 }
+
+// Element retrieves the keygen as an html.Node of type ElementNode.
+func (k *Keygen) Element() *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Keygen,
+		Data:     "keygen",
+	}
+	n.Attr = structToAttrs(k, "Challenge", "Form", "KeyType", "Name")
+	n.Attr = append(n.Attr, boolAttrs(k, "Autofocus", "Disabled")...)
+	k.HTML.Attach(n)
+	return n
+}
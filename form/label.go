@@ -1,8 +1,8 @@
 package form
 
 import (
-//"golang.org/x/net/html"
-//"golang.org/x/net/html/atom"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 )
 
 // Label describes a label for a field.
@@ -11,11 +11,21 @@ import (
 // the rules for attaching a label to a field vary by field type, more often
 // than not you should favor a field's Label property over adding a
 // Label element directly.
+//
+// A Label may wrap arbitrary content (icons, spans, even the field itself)
+// in Fields, which is rendered after Text.
+//
+// To reference a field by name instead of by ID, set Field to the field's
+// name and leave For empty. The name is resolved to the field's ID when the
+// form is rendered or prepared (see Form.ResolveLabels).
 type Label struct {
 	HTML
 	For, Form string
-	//Content   html.Node
-	Text string
+	Text      string
+	Fields    []Field
+
+	// Field is the name of the labeled field. This is not an attribute.
+	Field string
 }
 
 // NewLabel creates a new label.
@@ -25,3 +35,48 @@ func NewLabel(forName, text string) *Label {
 		Text: text,
 	}
 }
+
+// Element retrieves the label as an html.Node of type ElementNode.
+func (l *Label) Element() *html.Node {
+	return l.RenderElement(nil)
+}
+
+// RenderElement retrieves the label, passing the context to its content.
+func (l *Label) RenderElement(ctx *RenderContext) *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Label,
+		Data:     "label",
+	}
+	n.Attr = structToAttrs(l, "For", "Form")
+	l.HTML.Attach(n)
+
+	if len(l.Text) > 0 {
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: l.Text})
+	}
+	appendElements(ctx, n, l.Fields)
+	return n
+}
+
+// ResolveLabels sets the For attribute of each Label that references a field by name.
+//
+// If the named field does not yet have an ID, its name is used as the ID.
+// Labels that already have a For attribute, or that reference a field that
+// does not exist, are left unchanged.
+func (f *Form) ResolveLabels() {
+	walkFields(f.allFields(), func(field Field) {
+		l, ok := field.(*Label)
+		if !ok || len(l.Field) == 0 || len(l.For) > 0 {
+			return
+		}
+		walkFields(f.allFields(), func(target Field) {
+			if len(l.For) > 0 || nameOf(target) != l.Field {
+				return
+			}
+			if h := htmlOf(target); h != nil {
+				h.Id = h.EnsureId(l.Field)
+				l.For = h.Id
+			}
+		})
+	})
+}
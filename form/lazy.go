@@ -0,0 +1,87 @@
+package form
+
+import (
+	"golang.org/x/net/html"
+)
+
+// Lazy holds fields that are built only when they are needed.
+//
+// A Lazy can be placed in any list of fields. Its fields are treated as if
+// they were in that list: they are rendered, submitted, and validated in
+// place, and they have no container of their own.
+//
+// Build is called the first time the fields are rendered, reconciled, or
+// otherwise visited, and the result is kept in Fields. If When is set and
+// returns false, the fields are skipped entirely, and are not built. This
+// lets very large forms declare branches that are rarely shown without
+// paying to construct them on every request:
+//
+//	f.Add(&form.Lazy{
+//		When:  func() bool { return user.IsAdmin() },
+//		Build: adminFields,
+//	})
+//
+// Build and When are not serialized, so a Lazy that is cached by a
+// serializing cache keeps only the fields that had been built.
+type Lazy struct {
+	// Build constructs the fields. It is called at most once.
+	Build func() []Field
+	// When, if set, decides whether the fields are used at all.
+	When func() bool
+	// Fields holds the fields once they are built.
+	Fields []Field
+}
+
+// Resolve returns the fields, building them if necessary.
+//
+// If When returns false, this returns nil.
+func (l *Lazy) Resolve() []Field {
+	if l.When != nil && !l.When() {
+		return nil
+	}
+	if l.Fields == nil && l.Build != nil {
+		l.Fields = l.Build()
+		if l.Fields == nil {
+			l.Fields = []Field{}
+		}
+	}
+	return l.Fields
+}
+
+// Element renders the fields as the children of a document node.
+func (l *Lazy) Element() *html.Node {
+	return l.RenderElement(nil)
+}
+
+// RenderElement renders the fields, passing the context to them.
+//
+// A document node renders only its children, so the fields appear in place
+// of the Lazy. If there are no fields, this returns nil.
+func (l *Lazy) RenderElement(ctx *RenderContext) *html.Node {
+	fields := l.Resolve()
+	if len(fields) == 0 {
+		return nil
+	}
+	n := &html.Node{Type: html.DocumentNode}
+	appendElements(ctx, n, fields)
+	return n
+}
+
+// expandLazy replaces each Lazy in fields with its resolved fields.
+func expandLazy(fields []Field) []Field {
+	for i, f := range fields {
+		if _, ok := f.(*Lazy); !ok {
+			continue
+		}
+		out := append([]Field{}, fields[:i]...)
+		for _, f := range fields[i:] {
+			if l, ok := f.(*Lazy); ok && l != nil {
+				out = append(out, expandLazy(l.Resolve())...)
+			} else if !ok {
+				out = append(out, f)
+			}
+		}
+		return out
+	}
+	return fields
+}
@@ -0,0 +1,112 @@
+package form
+
+import "fmt"
+
+// LintError describes a problem with the way a form is constructed.
+type LintError struct {
+	// Field is the name of the field with the problem. It is empty if the
+	// field has no name, or the problem is with the form itself.
+	Field   string
+	Problem string
+}
+
+func (e *LintError) Error() string {
+	if len(e.Field) == 0 {
+		return e.Problem
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Problem)
+}
+
+// Lint checks a form for construction problems, and returns a *LintError
+// for each one.
+//
+// Rendering a form never panics on a nil field or option; these are simply
+// skipped. But they usually indicate a bug in the code that built the form,
+// as do the other problems Lint looks for:
+//
+//   - nil fields and options
+//   - select options that are neither an *Option nor an *OptGroup
+//   - IDs used by more than one field
+//   - labels referring to a field name that does not exist
+//   - unknown charsets (see CheckAcceptCharset)
+//
+// Lint is intended for tests and development builds. It does not modify
+// the form.
+func (f *Form) Lint() []error {
+	errs := []error{}
+	problem := func(name, format string, args ...interface{}) {
+		errs = append(errs, &LintError{Field: name, Problem: fmt.Sprintf(format, args...)})
+	}
+
+	if err := f.CheckAcceptCharset(); err != nil {
+		problem("", "%s", err)
+	}
+
+	ids := map[string]bool{}
+	var lint func(fields []Field, in string)
+	lint = func(fields []Field, in string) {
+		for i, field := range fields {
+			if isNil(field) {
+				problem(in, "field %d is nil", i)
+				continue
+			}
+			name := nameOf(field)
+			if h := htmlOf(field); h != nil && len(h.Id) > 0 {
+				if ids[h.Id] {
+					problem(name, "ID %q is used by more than one field", h.Id)
+				}
+				ids[h.Id] = true
+			}
+			switch field := field.(type) {
+			case *Div:
+				lint(field.Fields, name)
+			case *FieldSet:
+				lint(field.Fields, name)
+			case *Table:
+				lint(field.Fields, name)
+			case *Lazy:
+				lint(field.Resolve(), in)
+			case *Label:
+				if len(field.Field) > 0 && f.Field(field.Field) == nil {
+					problem("", "label %q refers to missing field %q", field.Text, field.Field)
+				}
+				lint(field.Fields, name)
+			case *Select:
+				lintOptions(field, problem)
+			case *DataList:
+				for j, o := range field.Options {
+					if o == nil {
+						problem(name, "option %d is nil", j)
+					}
+				}
+			}
+		}
+	}
+	lint(f.Fields, "")
+	lint(f.External, "")
+	return errs
+}
+
+// lintOptions checks the options of a select.
+func lintOptions(s *Select, problem func(name, format string, args ...interface{})) {
+	for i, o := range s.Options {
+		switch o := o.(type) {
+		case *Option:
+			if o == nil {
+				problem(s.Name, "option %d is nil", i)
+			}
+		case *OptGroup:
+			if o == nil {
+				problem(s.Name, "option group %d is nil", i)
+				continue
+			}
+			for j, oo := range o.Options {
+				if oo == nil {
+					problem(s.Name, "option %d of group %q is nil", j, o.Label)
+				}
+			}
+		default:
+			problem(s.Name, "option %d is a %T, not an *Option or *OptGroup", i, o)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// LiveEvent is a change to one field of a cached form.
+//
+// Events are pushed to the watchers of a form by a LiveHub. The Value is
+// redacted before it is published, so sensitive and password fields are
+// never sent to watchers.
+type LiveEvent struct {
+	// Path is the path of the field, as given to Form.FieldAt.
+	Path string `json:"path"`
+	// Name is the name of the field, without the form's Prefix.
+	Name string `json:"name"`
+	// Value is the new value of the field, redacted.
+	Value string `json:"value"`
+	// Source identifies the client that made the change, so that an editor
+	// can ignore its own changes. It is set by the client.
+	Source string `json:"source,omitempty"`
+	// Time is the time the change was published.
+	Time time.Time `json:"time"`
+}
+
+// LiveHub passes field-change events between the clients of a cached form.
+//
+// Events are keyed by the form's secure token. A hub does nothing unless its
+// handler is mounted with FormHandler.LiveHandler, so live collaboration is
+// entirely optional.
+type LiveHub struct {
+	// Buffer is the number of events queued for each watcher. Events sent to
+	// a watcher whose queue is full are dropped. If it is 0, 16 is used.
+	Buffer int
+	// Redactor redacts values before they are published. If it is nil,
+	// DefaultRedactor is used.
+	Redactor *Redactor
+
+	mx       sync.Mutex
+	watchers map[string]map[chan LiveEvent]bool
+}
+
+// NewLiveHub creates a new LiveHub.
+func NewLiveHub() *LiveHub {
+	return &LiveHub{watchers: map[string]map[chan LiveEvent]bool{}}
+}
+
+// Publish sends an event to every watcher of the form with the given id.
+//
+// Publish never blocks. The event is sent as given; it is the caller's job
+// to redact it.
+func (h *LiveHub) Publish(id string, e LiveEvent) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	for c := range h.watchers[id] {
+		select {
+		case c <- e:
+		default:
+		}
+	}
+}
+
+// Watch returns a channel of the events published for the form with the
+// given id. The returned function stops the watch and closes the channel.
+func (h *LiveHub) Watch(id string) (<-chan LiveEvent, func()) {
+	size := h.Buffer
+	if size == 0 {
+		size = 16
+	}
+	c := make(chan LiveEvent, size)
+
+	h.mx.Lock()
+	if h.watchers == nil {
+		h.watchers = map[string]map[chan LiveEvent]bool{}
+	}
+	if h.watchers[id] == nil {
+		h.watchers[id] = map[chan LiveEvent]bool{}
+	}
+	h.watchers[id][c] = true
+	h.mx.Unlock()
+
+	var once sync.Once
+	return c, func() {
+		once.Do(func() {
+			h.mx.Lock()
+			delete(h.watchers[id], c)
+			if len(h.watchers[id]) == 0 {
+				delete(h.watchers, id)
+			}
+			h.mx.Unlock()
+			close(c)
+		})
+	}
+}
+
+// Watchers returns the number of watchers of the form with the given id.
+func (h *LiveHub) Watchers(id string) int {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	return len(h.watchers[id])
+}
+
+func (h *LiveHub) redact(f *Form, name, value string) string {
+	r := h.Redactor
+	if r == nil {
+		r = DefaultRedactor
+	}
+	if f.IsSensitive(name) || r.Matches(f.prefixed(name)) {
+		return r.mask()
+	}
+	for _, n := range f.passwordNames() {
+		if n == name {
+			return r.mask()
+		}
+	}
+	return value
+}
+
+// LiveHandler returns a handler for the live channel of cached forms.
+//
+// Clients identify the form by its secure token, in SecureTokenName. The
+// form must be one prepared by this handler that has not yet been submitted
+// or expired; otherwise the request is forbidden.
+//
+// A GET request watches the form. Events are streamed as server-sent
+// events named "change", whose data is a LiveEvent encoded as JSON, until
+// the client goes away.
+//
+// A POST request publishes a change, given as "path", "value" and,
+// optionally, "source". The field is looked up in the cached form, and the
+// value is redacted before it is published. The cached form is not
+// modified; the change only reaches the server when the form is submitted.
+func (h *FormHandler) LiveHandler(hub *LiveHub) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id := r.Form.Get(SecureTokenName)
+		f, err := h.WithContext(r.Context()).prepared(id)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			watchLive(w, r, hub, id)
+		case "POST":
+			path := r.Form.Get("path")
+			field := f.FieldAt(path)
+			if field == nil {
+				http.Error(w, ErrFieldNotFound.Error(), http.StatusNotFound)
+				return
+			}
+			name := nameOf(field)
+			hub.Publish(id, LiveEvent{
+				Path:   path,
+				Name:   name,
+				Value:  hub.redact(f, name, r.Form.Get("value")),
+				Source: r.Form.Get("source"),
+				Time:   h.now(),
+			})
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func watchLive(w http.ResponseWriter, r *http.Request, hub *LiveHub, id string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	events, stop := hub.Watch(id)
+	defer stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-events:
+			data, err := json.Marshal(e)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: change\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package form
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Masterminds/engine/form/cache"
+)
+
+// ErrLockLost indicates that an edit lock is held by someone else, so it
+// cannot be renewed or released by its former owner.
+var ErrLockLost = errors.New("Edit lock is held by someone else")
+
+// EditLock records that someone is editing a record.
+type EditLock struct {
+	Record, Owner string
+	Since         time.Time
+	Expires       time.Time
+}
+
+// Locker keeps soft locks on the records being edited with forms, for
+// editorial workflows in which people should know that someone else is
+// editing the same record ("Matt is editing this page").
+//
+// The locks are advisory: a locked record can still be opened and
+// submitted, so the application decides whether to warn, to open the form
+// read-only, or to refuse. Combine them with versions (see
+// Form.SetVersion) to catch the edits that overlap anyway. Locks are held
+// in a cache, which can be shared by several servers, and expire unless
+// their owner's page renews them (see HeartbeatHandler). Since caches have
+// no atomic updates, two people who open a record at the same moment may
+// both be given the lock.
+type Locker struct {
+	// TTL is how long a lock lasts without a heartbeat. The default is 2
+	// minutes.
+	TTL time.Duration
+	// Clock, if set, is used in place of the system clock.
+	Clock cache.Clock
+
+	c cache.Cache
+}
+
+// NewLocker creates a Locker that keeps its locks in c.
+func NewLocker(c cache.Cache, ttl time.Duration) *Locker {
+	return &Locker{TTL: ttl, c: c}
+}
+
+func (l *Locker) now() time.Time {
+	if l.Clock != nil {
+		return l.Clock.Now()
+	}
+	return cache.SystemClock.Now()
+}
+
+func (l *Locker) ttl() time.Duration {
+	if l.TTL <= 0 {
+		return 2 * time.Minute
+	}
+	return l.TTL
+}
+
+func lockKey(record string) string {
+	return "lock:" + record
+}
+
+// Holder returns the lock on a record, or nil if it is not locked.
+func (l *Locker) Holder(record string) (*EditLock, error) {
+	v, err := l.c.Get(lockKey(record))
+	if err == cache.ErrNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	lock, ok := v.(*EditLock)
+	if !ok || !l.now().Before(lock.Expires) {
+		return nil, nil
+	}
+	cp := *lock
+	return &cp, nil
+}
+
+// Acquire locks a record for the owner, and returns the lock.
+//
+// If someone else holds the lock, it is returned, along with false. If the
+// owner already holds it, it is renewed.
+func (l *Locker) Acquire(record, owner string) (*EditLock, bool, error) {
+	held, err := l.Holder(record)
+	if err != nil {
+		return nil, false, err
+	}
+	if held != nil && held.Owner != owner {
+		return held, false, nil
+	}
+	now := l.now()
+	lock := &EditLock{Record: record, Owner: owner, Since: now, Expires: now.Add(l.ttl())}
+	if held != nil {
+		lock.Since = held.Since
+	}
+	if err := l.c.Set(lockKey(record), lock, lock.Expires); err != nil {
+		return nil, false, err
+	}
+	cp := *lock
+	return &cp, true, nil
+}
+
+// Heartbeat renews the owner's lock on a record. If the lock has expired,
+// it is acquired again, unless someone else has taken it, in which case
+// their lock is returned with ErrLockLost.
+func (l *Locker) Heartbeat(record, owner string) (*EditLock, error) {
+	lock, ok, err := l.Acquire(record, owner)
+	if err == nil && !ok {
+		err = ErrLockLost
+	}
+	return lock, err
+}
+
+// Release removes the owner's lock on a record, for example when the form
+// is submitted. If someone else holds the lock, ErrLockLost is returned.
+func (l *Locker) Release(record, owner string) error {
+	held, err := l.Holder(record)
+	if err != nil || held == nil {
+		return err
+	}
+	if held.Owner != owner {
+		return ErrLockLost
+	}
+	return l.c.Remove(lockKey(record))
+}
+
+// PrepareLocked prepares a form that edits a record, like Prepare, and
+// locks the record for the owner.
+//
+// If someone else is editing the record, the form is still prepared, and
+// their lock is returned, so that the page can say who it is. Otherwise,
+// the returned lock is nil.
+func (f *FormHandler) PrepareLocked(form *Form, l *Locker, record, owner string) (string, *EditLock, error) {
+	lock, ok, err := l.Acquire(record, owner)
+	if err != nil {
+		return "", nil, err
+	}
+	id, err := f.Prepare(form)
+	if ok {
+		lock = nil
+	}
+	return id, lock, err
+}
+
+// HeartbeatHandler returns an endpoint that the pages of forms post to
+// periodically (well within the TTL), to keep their locks.
+//
+// The record is the "record" value of the request, and its owner is
+// identified by owner, usually from the request's session; an empty owner
+// is forbidden. The response is the lock as JSON, with the status 200 if
+// the owner holds it, and 409 if someone else does.
+func (l *Locker) HeartbeatHandler(owner func(r *http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		who := owner(r)
+		record := r.FormValue("record")
+		if len(who) == 0 || len(record) == 0 {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		lock, err := l.Heartbeat(record, who)
+		if err != nil && err != ErrLockLost {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err == ErrLockLost {
+			w.WriteHeader(http.StatusConflict)
+		}
+		json.NewEncoder(w).Encode(lock)
+	})
+}
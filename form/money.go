@@ -0,0 +1,185 @@
+package form
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ErrInvalidAmount indicates a submitted amount that is not a valid number
+// for its currency.
+var ErrInvalidAmount = errors.New("Invalid amount")
+
+// CurrencyDigits maps ISO 4217 currency codes to the number of digits in
+// their minor unit. Currencies that are not listed have two.
+var CurrencyDigits = map[string]int{
+	"BHD": 3, "BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "IQD": 3, "ISK": 0,
+	"JOD": 3, "JPY": 0, "KMF": 0, "KRW": 0, "KWD": 3, "LYD": 3, "OMR": 3,
+	"PYG": 0, "RWF": 0, "TND": 3, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// MinorDigits returns the number of digits in a currency's minor unit.
+func MinorDigits(currency string) int {
+	if d, ok := CurrencyDigits[strings.ToUpper(currency)]; ok {
+		return d
+	}
+	return 2
+}
+
+// Money is a composite field for an amount of money in a currency.
+//
+// It is rendered as a number input (named Name) followed by a select of
+// Currencies (named by CurrencyName). If there is only one currency, the
+// select is omitted, and the currency is fixed.
+//
+// The amount is stored in the currency's minor unit (such as cents), so
+// that it is never subject to floating point rounding. A submitted amount
+// with more decimal places than the currency allows is rejected.
+type Money struct {
+	HTML
+	Name       string
+	Currencies []string
+	Disabled   bool
+	Required   bool
+
+	// Amount is in the minor unit of Currency.
+	Amount int64
+	// Currency is an ISO 4217 code, such as "USD".
+	Currency string
+
+	// Label is not an attribute of the field. It is used for a label element.
+	Label string
+}
+
+// NewMoney creates a new Money field accepting the given currencies.
+//
+// The first currency is selected.
+func NewMoney(name string, currencies ...string) *Money {
+	m := &Money{Name: name, Currencies: currencies}
+	if len(currencies) > 0 {
+		m.Currency = currencies[0]
+	}
+	return m
+}
+
+// CurrencyName returns the name of the currency select.
+func (m *Money) CurrencyName() string {
+	return m.Name + "_currency"
+}
+
+// Value returns the Amount formatted in major units, like "12.34".
+func (m *Money) Value() string {
+	return FormatMinorUnits(m.Amount, MinorDigits(m.Currency))
+}
+
+// Step returns the step attribute for the amount, like "0.01".
+func (m *Money) Step() string {
+	d := MinorDigits(m.Currency)
+	if d == 0 {
+		return "1"
+	}
+	return "0." + strings.Repeat("0", d-1) + "1"
+}
+
+// Element retrieves the field as an html.Node of type ElementNode.
+//
+// The field's HTML attributes are applied to a span containing the amount
+// input and the currency select.
+func (m *Money) Element() *html.Node {
+	n := &html.Node{Type: html.ElementNode, DataAtom: atom.Span, Data: "span"}
+	m.HTML.Attach(n)
+
+	amount := &Number{
+		Name:     m.Name,
+		Value:    m.Value(),
+		Step:     m.Step(),
+		Disabled: m.Disabled,
+		Required: m.Required,
+	}
+	n.AppendChild(amount.Element())
+
+	if len(m.Currencies) > 1 {
+		sel := &Select{Name: m.CurrencyName(), Disabled: m.Disabled, Required: m.Required}
+		for _, c := range m.Currencies {
+			sel.Options = append(sel.Options, &Option{Value: c, Label: c, Selected: c == m.Currency})
+		}
+		n.AppendChild(sel.Element())
+	}
+	return n
+}
+
+// reconcile sets the currency and amount from submitted data.
+func (m *Money) reconcile(amount, currency string) error {
+	for _, c := range m.Currencies {
+		if c == currency {
+			m.Currency = c
+		}
+	}
+	if len(amount) == 0 {
+		return nil
+	}
+	a, err := ParseMinorUnits(amount, MinorDigits(m.Currency))
+	if err != nil {
+		return err
+	}
+	m.Amount = a
+	return nil
+}
+
+// ParseMinorUnits parses a decimal amount, like "-12.3", into minor units.
+//
+// The amount may have at most digits decimal places. It is parsed exactly,
+// without floating point arithmetic.
+func ParseMinorUnits(s string, digits int) (int64, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	whole, frac := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if len(frac) > digits || len(whole)+len(frac) == 0 {
+		return 0, ErrInvalidAmount
+	}
+	if len(whole) == 0 {
+		whole = "0"
+	}
+	digitsOnly := whole + frac + strings.Repeat("0", digits-len(frac))
+	for _, r := range digitsOnly {
+		if r < '0' || r > '9' {
+			return 0, ErrInvalidAmount
+		}
+	}
+	v, err := strconv.ParseInt(digitsOnly, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+// FormatMinorUnits formats an amount in minor units as a decimal, like "-12.30".
+func FormatMinorUnits(v int64, digits int) string {
+	sign := ""
+	u := uint64(v)
+	if v < 0 {
+		sign = "-"
+		u = uint64(-v)
+	}
+	s := strconv.FormatUint(u, 10)
+	if digits <= 0 {
+		return sign + s
+	}
+	if len(s) <= digits {
+		s = strings.Repeat("0", digits-len(s)+1) + s
+	}
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
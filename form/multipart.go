@@ -0,0 +1,128 @@
+package form
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Attachment is a file attached to a form, either with AttachFile or by a
+// user who submitted it (see RetrieveMultipart).
+//
+// Filename is safe to use in a path or a Content-Disposition header (see
+// FilenamePolicy); Original is the name the user gave, and should only be
+// displayed, escaped. Files attached with AttachFile have the same name in
+// both.
+type Attachment struct {
+	Filename string
+	Content  []byte
+	Original string
+}
+
+// AttachFile attaches a file to the named file field, as if a user had
+// chosen it.
+//
+// Attached files are used by AsMultipart; several files may be attached
+// to a field that allows multiple files. Attachments are not kept when a
+// form is cached.
+func (f *Form) AttachFile(name, filename string, content []byte) {
+	if f.files == nil {
+		f.files = map[string][]Attachment{}
+	}
+	f.files[name] = append(f.files[name], Attachment{Filename: filename, Content: content, Original: filename})
+}
+
+// Attachments returns the files attached to the named field.
+func (f *Form) Attachments(name string) []Attachment {
+	return f.files[name]
+}
+
+// AsMultipart encodes the form as a multipart/form-data submission.
+//
+// The body contains the form's values (see AsValues) followed by its
+// attached files (see AttachFile). A file field with no attachments is
+// encoded as an empty file, as a user agent would. The returned content
+// type includes the boundary, and can be used as the Content-Type header of
+// a request.
+//
+// This is useful for tests, and for replaying a form submission to another
+// server.
+func (f *Form) AsMultipart() (io.Reader, string, error) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	vals := *f.AsValues()
+	keys := make([]string, 0, len(vals))
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range vals[k] {
+			if err := w.WriteField(k, v); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	// File fields come first, in order, followed by any other attachments.
+	names := []string{}
+	seen := map[string]bool{}
+	walkFields(f.allFields(), func(field Field) {
+		if file, ok := field.(*File); ok && len(file.Name) > 0 && !seen[file.Name] {
+			names = append(names, file.Name)
+			seen[file.Name] = true
+		}
+	})
+	extra := []string{}
+	for name := range f.files {
+		if !seen[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+
+	for _, name := range append(names, extra...) {
+		files := f.files[name]
+		if len(files) == 0 {
+			files = []Attachment{{}}
+		}
+		for _, a := range files {
+			if err := writeFile(w, f.prefixed(name), a); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return body, w.FormDataContentType(), nil
+}
+
+// writeFile writes an attachment as a file part.
+func writeFile(w *multipart.Writer, name string, a Attachment) error {
+	ct := mime.TypeByExtension(filepath.Ext(a.Filename))
+	if len(ct) == 0 {
+		ct = "application/octet-stream"
+	}
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(name), quoteEscaper.Replace(a.Filename)))
+	h.Set("Content-Type", ct)
+	p, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	_, err = p.Write(a.Content)
+	return err
+}
+
+// quoteEscaper escapes the quoted strings of a Content-Disposition header.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
@@ -0,0 +1,94 @@
+package form
+
+import (
+	"net/http"
+	"sync"
+)
+
+// FormIDName is the name of the hidden field that identifies a form among
+// the forms of a FormMux. Like the security token, it is never prefixed.
+var FormIDName = "__form__"
+
+// SubmitFunc handles the submission of a form.
+//
+// The form is the one retrieved with FormHandler.RetrieveRequest, and err
+// is the error it returned; a form may come with an error, such as a
+// reconciliation error.
+type SubmitFunc func(w http.ResponseWriter, r *http.Request, f *Form, err error)
+
+// FormMux handles the submissions of several forms on one URL.
+//
+// Dashboards and other pages with several small forms can post them all to
+// one handler. Each form is prepared with the mux's Prepare, which adds a
+// hidden field holding the form's Name, and submissions are passed to the
+// SubmitFunc registered for that name. The form's security token must
+// belong to a form of the same name, so a submission cannot be passed to
+// the wrong handler.
+type FormMux struct {
+	h        *FormHandler
+	mx       sync.RWMutex
+	handlers map[string]SubmitFunc
+}
+
+// NewFormMux creates a FormMux that retrieves forms with h.
+func NewFormMux(h *FormHandler) *FormMux {
+	return &FormMux{h: h, handlers: map[string]SubmitFunc{}}
+}
+
+// Handle registers the handler of the forms with the given Name.
+//
+// Like http.ServeMux, Handle panics if a handler is already registered for
+// the name.
+func (m *FormMux) Handle(name string, fn SubmitFunc) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	if len(name) == 0 || fn == nil {
+		panic("form: FormMux needs a name and a handler")
+	}
+	if _, ok := m.handlers[name]; ok {
+		panic("form: multiple handlers for form " + name)
+	}
+	m.handlers[name] = fn
+}
+
+// Prepare adds the form's FormIDName field, and prepares it with the mux's
+// FormHandler.
+func (m *FormMux) Prepare(f *Form) (string, error) {
+	if f.State() == Built && f.Field(FormIDName) == nil {
+		f.Fields = append(f.Fields, &Hidden{Name: FormIDName, Value: f.Name})
+	}
+	return m.h.Prepare(f)
+}
+
+// ServeHTTP passes a submission to the handler of its form.
+//
+// Submissions of forms that have no handler are not found. A submission
+// whose token belongs to a form of another name is a bad request; its form
+// is looked up before it is retrieved, so the token is not used up and the
+// form can still be submitted.
+func (m *FormMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := r.Form.Get(FormIDName)
+	m.mx.RLock()
+	fn, ok := m.handlers[name]
+	m.mx.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	cached, err := m.h.WithContext(r.Context()).Get(r.Form.Get(SecureTokenName))
+	if err == nil && cached.Name != name {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	f, err := m.h.RetrieveRequest(r)
+	if f != nil && f.Name != name {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	fn(w, r, f, err)
+}
@@ -0,0 +1,88 @@
+package form
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// NamePolicy decides what Add does with a field whose name is already in use.
+//
+// It is given the duplicated name and a function that reports whether a
+// name is taken. It returns the name the field should have, or an error if
+// the field must not be added.
+//
+// Radio buttons and checkboxes are grouped by name, so they are never
+// checked against the policy.
+type NamePolicy func(name string, taken func(string) bool) (string, error)
+
+// DuplicateNameError indicates that a field's name is already in use.
+type DuplicateNameError struct {
+	Name string
+}
+
+func (e *DuplicateNameError) Error() string {
+	return fmt.Sprintf("Duplicate field name %q", e.Name)
+}
+
+// AllowDuplicateNames is a NamePolicy that keeps duplicate names.
+//
+// This is the behavior of forms without a NamePolicy. Note that only one
+// value is kept for most duplicated names in AsValues.
+func AllowDuplicateNames(name string, taken func(string) bool) (string, error) {
+	return name, nil
+}
+
+// RejectDuplicateNames is a NamePolicy that rejects duplicate names.
+func RejectDuplicateNames(name string, taken func(string) bool) (string, error) {
+	return "", &DuplicateNameError{Name: name}
+}
+
+// RenameDuplicateNames is a NamePolicy that adds a number to duplicate names.
+//
+// The second field named "email" becomes "email-2", the third "email-3", and
+// so on.
+func RenameDuplicateNames(name string, taken func(string) bool) (string, error) {
+	for i := 2; ; i++ {
+		if n := name + "-" + strconv.Itoa(i); !taken(n) {
+			return n, nil
+		}
+	}
+}
+
+// checkNames applies the form's NamePolicy to fields that are being added.
+//
+// Fields nested in containers are checked as well. Names may be changed in
+// place. The first error from the policy is returned.
+func (f *Form) checkNames(fields []Field) error {
+	if f.NamePolicy == nil {
+		return nil
+	}
+	names := map[string]bool{}
+	walkFields(f.allFields(), func(field Field) {
+		names[nameOf(field)] = true
+	})
+	taken := func(n string) bool { return names[n] }
+
+	var err error
+	walkFields(fields, func(field Field) {
+		name := nameOf(field)
+		if err != nil || len(name) == 0 {
+			return
+		}
+		switch field.(type) {
+		case *Radio, *Checkbox, *Form:
+			names[name] = true
+			return
+		}
+		if names[name] {
+			var n string
+			if n, err = f.NamePolicy(name, taken); err != nil {
+				return
+			}
+			setStringField(field, "Name", n)
+			name = n
+		}
+		names[name] = true
+	})
+	return err
+}
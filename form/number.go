@@ -0,0 +1,115 @@
+package form
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidNumber indicates a submitted value that is not a number.
+	ErrInvalidNumber = errors.New("Invalid number")
+	// ErrNumberScale indicates a number with more decimal places than its Step allows.
+	ErrNumberScale = errors.New("Too many decimal places")
+	// ErrNumberRange indicates a number outside of its Min and Max.
+	ErrNumberRange = errors.New("Number out of range")
+)
+
+// NewInteger creates a Number that accepts whole numbers.
+func NewInteger(name string) *Number {
+	return &Number{Name: name, Step: "1"}
+}
+
+// NewDecimal creates a Number that accepts up to scale decimal places.
+func NewDecimal(name string, scale int) *Number {
+	n := &Number{Name: name, Step: "1"}
+	if scale > 0 {
+		n.Step = "0." + strings.Repeat("0", scale-1) + "1"
+	}
+	return n
+}
+
+// Scale returns the number of decimal places the field accepts.
+//
+// This is the number of decimal places in the Step. A Number without a Step
+// accepts integers, as user agents do. If the Step is "any", -1 is
+// returned, and any number of decimal places is accepted.
+func (n *Number) Scale() int {
+	switch {
+	case len(n.Step) == 0:
+		return 0
+	case strings.EqualFold(n.Step, "any"):
+		return -1
+	}
+	if i := strings.Index(n.Step, "."); i >= 0 {
+		return len(strings.TrimRight(n.Step[i+1:], "0"))
+	}
+	return 0
+}
+
+// Int returns the Value as an integer.
+func (n *Number) Int() (int64, error) {
+	v, err := strconv.ParseInt(strings.TrimSpace(n.Value), 10, 64)
+	if err != nil {
+		return 0, ErrInvalidNumber
+	}
+	return v, nil
+}
+
+// Float returns the Value as a float64.
+//
+// Decimal values should use Scaled instead, to avoid rounding errors.
+func (n *Number) Float() (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(n.Value), 64)
+	if err != nil {
+		return 0, ErrInvalidNumber
+	}
+	return v, nil
+}
+
+// Scaled returns the Value multiplied by 10^Scale, as an integer.
+//
+// For example, with a Step of "0.01", a Value of "12.5" returns 1250. The
+// value is converted exactly. If the Step is "any", ErrNumberScale is
+// returned.
+func (n *Number) Scaled() (int64, error) {
+	s := n.Scale()
+	if s < 0 {
+		return 0, ErrNumberScale
+	}
+	v, err := ParseMinorUnits(n.Value, s)
+	if err != nil {
+		return 0, n.checkScale(n.Value)
+	}
+	return v, nil
+}
+
+// checkScale returns ErrNumberScale if val is a number with too many
+// decimal places, and ErrInvalidNumber otherwise.
+func (n *Number) checkScale(val string) error {
+	if _, err := strconv.ParseFloat(val, 64); err != nil {
+		return ErrInvalidNumber
+	}
+	return ErrNumberScale
+}
+
+// reconcileNumber validates a submitted number against the field's Step, Min, and Max.
+func reconcileNumber(n *Number, val string) (string, error) {
+	val = strings.TrimSpace(val)
+	v, err := strconv.ParseFloat(val, 64)
+	if err != nil || strings.ContainsAny(val, "eEnN") {
+		return "", ErrInvalidNumber
+	}
+	if s := n.Scale(); s >= 0 {
+		if _, err := ParseMinorUnits(val, s); err != nil {
+			return "", ErrNumberScale
+		}
+	}
+	if min, err := strconv.ParseFloat(n.Min, 64); err == nil && v < min {
+		return "", ErrNumberRange
+	}
+	if max, err := strconv.ParseFloat(n.Max, 64); err == nil && v > max {
+		return "", ErrNumberRange
+	}
+	return val, nil
+}
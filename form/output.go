@@ -1,7 +1,47 @@
 package form
 
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
 // Output describes an output form field.
+//
+// The For field is a space-separated list of the IDs of the elements that
+// contributed to the output's value. Value is rendered as the content of
+// the element, and may be updated at any time before rendering (for
+// example, by a computed field).
 type Output struct {
 	HTML
 	For, Form, Name string
+	Value           string
+}
+
+// NewOutput creates a new Output with the given name, associated with the
+// elements whose IDs are passed in forIds.
+func NewOutput(name string, forIds ...string) *Output {
+	return &Output{
+		Name: name,
+		For:  strings.Join(forIds, " "),
+	}
+}
+
+// Element retrieves the output as an html.Node of type ElementNode.
+//
+// The Value is attached as a text node child of the output.
+func (o *Output) Element() *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Output,
+		Data:     "output",
+	}
+	n.Attr = structToAttrs(o, "For", "Form", "Name")
+	o.HTML.Attach(n)
+
+	if len(o.Value) > 0 {
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: o.Value})
+	}
+	return n
 }
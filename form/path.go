@@ -0,0 +1,145 @@
+package form
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Walk calls fn with each of the form's fields and its path, in rendering
+// order. Containers are passed to fn before their children. External
+// fields follow the form's other fields.
+//
+// A path identifies a field by its position in a form. It is the form's
+// name, followed by a segment for each container of the field, and finally
+// a segment for the field, separated by dots:
+//
+//	signup.address.street
+//
+// A segment is the field's name, or the lower-case name of its type if it
+// has no name (e.g. "div"). Where several fields in the same container have
+// the same segment, the second and later ones are followed by their index
+// among them, in brackets:
+//
+//	signup.plan      (the first radio button named "plan")
+//	signup.plan[1]   (the second)
+//
+// An index of [0] may be given, but is never generated. Dots, brackets, and
+// backslashes in names are escaped with a backslash. Fields of embedded
+// forms have the embedded form's name as a segment. String fields do not
+// have paths.
+//
+// FieldAt, PathOf, ErrorPaths, and AssignIDs all use paths of this form,
+// so scripts and tests can refer to fields the same way.
+func (f *Form) Walk(fn func(path string, field Field)) {
+	walkPaths(f.allFields(), pathSegment(f.Name), fn)
+}
+
+// FieldAt returns the field at the given path, or nil if there is none.
+func (f *Form) FieldAt(path string) Field {
+	want := normalizePath(path)
+	var found Field
+	f.Walk(func(p string, field Field) {
+		if found == nil && p == want {
+			found = field
+		}
+	})
+	return found
+}
+
+// PathOf returns the path of a field of the form, or the empty string if
+// the field is not on the form.
+func (f *Form) PathOf(field Field) string {
+	var path string
+	f.Walk(func(p string, ff Field) {
+		if len(path) == 0 && sameField(ff, field) {
+			path = p
+		}
+	})
+	return path
+}
+
+// ErrorPaths returns the form's errors keyed by path instead of by name.
+//
+// Errors for a name that several fields share (such as a group of radio
+// buttons) belong to the first of the fields. Errors for names that are not
+// on the form are keyed by name.
+func (f *Form) ErrorPaths() map[string][]string {
+	paths := make(map[string][]string, len(f.Errors))
+	for name, msgs := range f.Errors {
+		key := name
+		if field := f.Field(name); field != nil {
+			if p := f.PathOf(field); len(p) > 0 {
+				key = p
+			}
+		}
+		paths[key] = append(paths[key], msgs...)
+	}
+	return paths
+}
+
+// walkPaths calls fn for each field below the given path.
+func walkPaths(fields []Field, path string, fn func(string, Field)) {
+	seen := map[string]int{}
+	// The fields of a Lazy are part of the enclosing list, so they share
+	// its path and its repeat counts.
+	for _, field := range expandLazy(fields) {
+		if _, ok := field.(String); ok || isNil(field) {
+			continue
+		}
+		seg := pathSegment(nameOf(field))
+		if len(seg) == 0 {
+			seg = strings.ToLower(reflect.Indirect(reflect.ValueOf(field)).Type().Name())
+		}
+		if n := seen[seg]; n > 0 {
+			seen[seg]++
+			seg += "[" + strconv.Itoa(n) + "]"
+		} else {
+			seen[seg] = 1
+		}
+
+		here := seg
+		if len(path) > 0 {
+			here = path + "." + seg
+		}
+		fn(here, field)
+		switch c := field.(type) {
+		case *Div:
+			walkPaths(c.Fields, here, fn)
+		case *FieldSet:
+			walkPaths(c.Fields, here, fn)
+		case *Label:
+			walkPaths(c.Fields, here, fn)
+		case *Table:
+			walkPaths(c.Fields, here, fn)
+		case *Form:
+			walkPaths(c.allFields(), here, fn)
+		}
+	}
+}
+
+// pathEscaper escapes the characters of a name that are special in paths.
+var pathEscaper = strings.NewReplacer(`\`, `\\`, `.`, `\.`, `[`, `\[`, `]`, `\]`)
+
+// pathSegment converts a name to a path segment.
+func pathSegment(name string) string {
+	return pathEscaper.Replace(name)
+}
+
+// normalizePath removes [0] indexes from a path.
+func normalizePath(path string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path):
+			b.WriteString(path[i : i+2])
+			i++
+		case strings.HasPrefix(path[i:], "[0]"):
+			i += 2
+		default:
+			b.WriteByte(path[i])
+		}
+	}
+	return b.String()
+}
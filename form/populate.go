@@ -0,0 +1,107 @@
+package form
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrEnctype indicates that a submission's body is not encoded as its
+// form's Enctype says it should be.
+var ErrEnctype = errors.New("Submission is not encoded as the form's enctype")
+
+// PopulateFromRequest sets the form's values from a submitted request, with
+// the limits of DefaultMultipartLimits. See PopulateFromRequestLimits.
+func (f *Form) PopulateFromRequest(r *http.Request) error {
+	return f.PopulateFromRequestLimits(r, MultipartLimits{})
+}
+
+// PopulateFromRequestLimits sets the form's values from a submitted
+// request, reading its body within the limits.
+//
+// This is for forms that are declared for each request rather than cached;
+// cached forms are retrieved with FormHandler.Retrieve or
+// RetrieveMultipart, which also check the security token.
+//
+// A GET or HEAD request's values are read from its query. Otherwise, the
+// body must be encoded as the form's Enctype says (the default is
+// EnctypeURLEncoded), or ErrEnctype is returned; text/plain bodies cannot
+// be decoded reliably, so they are always refused. A URL-encoded body may
+// be no larger than the limits' MaxMemory, and multipart bodies are read
+// part by part as RetrieveMultipart reads them, returning the same errors
+// when a limit is exceeded. Parts that are not named for the form's fields
+// are discarded. Files are attached to the form's file fields (see
+// Attachments), with names made safe by DefaultFilenamePolicy; a file it
+// rejects is added to the form's Errors.
+//
+// The values are reconciled with the form (see Reconcile), but not
+// validated.
+func (f *Form) PopulateFromRequestLimits(r *http.Request, limits MultipartLimits) error {
+	limits = limits.withDefaults()
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		q := r.URL.Query()
+		return Reconcile(f, &q)
+	}
+
+	enctype := strings.ToLower(f.Enctype)
+	if len(enctype) == 0 {
+		enctype = EnctypeURLEncoded
+	}
+	ct, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || ct != enctype || ct == EnctypeText {
+		return ErrEnctype
+	}
+
+	if ct == EnctypeURLEncoded {
+		b, err := ioutil.ReadAll(io.LimitReader(r.Body, limits.MaxMemory+1))
+		if err != nil {
+			return err
+		}
+		if int64(len(b)) > limits.MaxMemory {
+			return ErrSubmissionTooLarge
+		}
+		data, err := url.ParseQuery(string(b))
+		if err != nil {
+			return err
+		}
+		return Reconcile(f, &data)
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return err
+	}
+	names := f.submittedNames()
+	data := url.Values{}
+	keep := func(name string) bool {
+		_, ok := names[name]
+		return ok
+	}
+	err = readParts(mr, limits, keep, func(p *multipart.Part, b []byte) error {
+		name := names[p.FormName()]
+		if len(p.FileName()) == 0 {
+			data.Add(p.FormName(), string(b))
+			return nil
+		}
+		a := Attachment{Content: b, Original: originalFilename(p)}
+		var err error
+		if a.Filename, err = DefaultFilenamePolicy.SafeName(a.Original); err != nil {
+			f.Errors.Add(name, err.Error())
+			return nil
+		}
+		if f.files == nil {
+			f.files = map[string][]Attachment{}
+		}
+		f.files[name] = append(f.files[name], a)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return Reconcile(f, &data)
+}
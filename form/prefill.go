@@ -0,0 +1,78 @@
+package form
+
+import (
+	"time"
+
+	"github.com/Masterminds/engine/form/cache"
+)
+
+// lastKey returns the cache key of a user's last submission of a form.
+//
+// The keys of prepared forms begin with an escaped namespace, which never
+// has a colon, so no token can be looked up as the key of a submission.
+func (f *FormHandler) lastKey(name, userKey string) string {
+	return "last:" + cache.NamespaceKey(f.Namespace, name+":"+userKey)
+}
+
+// Remember keeps a submitted form as the user's last submission of it, so
+// that the form can be prefilled with PrefillFromLast the next time the
+// user fills it in, as on recurring order and booking forms.
+//
+// userKey identifies the user, such as an account ID; forms are told apart
+// by their Names. Only the last submission is kept, so this is not a store
+// of submissions that can be queried. It is kept in the handler's cache for
+// the given duration, or for 90 days if it is not positive, so a cache that
+// sweeps or evicts its records may forget it sooner. It is kept apart from
+// prepared forms, so its key cannot be submitted as a token.
+func (f *FormHandler) Remember(form *Form, userKey string, keep time.Duration) error {
+	if keep <= 0 {
+		keep = 90 * 24 * time.Hour
+	}
+	last, err := copyForm(form)
+	if err != nil {
+		return err
+	}
+	return f.cache.Set(f.lastKey(form.Name, userKey), last, f.now().Add(keep))
+}
+
+// PrefillFromLast sets the form's values from the user's last submission
+// of it (see Remember), and reports whether there was one.
+//
+// Values are set as SetValues sets them, so the form should be prefilled
+// after it is declared and before it is prepared. Hidden, password, and
+// file fields are left as they are, as are the security token, form ID,
+// and record version, since those belong to the last submission rather
+// than the user: a hidden record ID, for example, must not be carried into
+// a new record.
+func (f *FormHandler) PrefillFromLast(form *Form, userKey string) (bool, error) {
+	last, err := f.cache.Get(f.lastKey(form.Name, userKey))
+	if err == ErrFormNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	vals := *last.values()
+	private := map[string]bool{SecureTokenName: true, FormIDName: true, VersionName: true, ConfirmName: true}
+	last.privateNames(private, func(name string) string { return name })
+	for name := range vals {
+		if private[name] {
+			delete(vals, name)
+		}
+	}
+	form.SetValues(*form.prefixValues(&vals))
+	return true, nil
+}
+
+// privateNames adds the names of the form's hidden, password, and file
+// fields to names, as prefix gives them.
+func (f *Form) privateNames(names map[string]bool, prefix func(string) string) {
+	walkFields(f.allFields(), func(field Field) {
+		switch field := field.(type) {
+		case *Form:
+			field.privateNames(names, func(name string) string { return prefix(field.prefixed(name)) })
+		case *Hidden, *Password, *File:
+			names[prefix(nameOf(field))] = true
+		}
+	})
+}
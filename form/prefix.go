@@ -0,0 +1,98 @@
+package form
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// PrefixFunc joins a prefix and a field name or ID.
+type PrefixFunc func(prefix, name string) string
+
+// DefaultPrefixFunc is used by forms that do not have a PrefixFunc.
+//
+// It simply concatenates the prefix and the name.
+var DefaultPrefixFunc PrefixFunc = func(prefix, name string) string {
+	return prefix + name
+}
+
+// prefixAttrs are the attributes that contain a name or ID.
+var prefixAttrs = map[string]bool{
+	"name": true,
+	"id":   true,
+	"for":  true,
+	"list": true,
+	"form": true,
+}
+
+// prefixed returns the name with the form's Prefix applied.
+//
+// The security token and FormIDName are never prefixed, since they are used
+// to look up the form before the form (and thus its prefix) is known.
+func (f *Form) prefixed(name string) string {
+	if len(f.Prefix) == 0 || len(name) == 0 || name == SecureTokenName || name == FormIDName {
+		return name
+	}
+	fn := f.PrefixFunc
+	if fn == nil {
+		fn = DefaultPrefixFunc
+	}
+	return fn(f.Prefix, name)
+}
+
+// prefixNode applies the form's Prefix to the names and IDs in a node tree.
+func (f *Form) prefixNode(n *html.Node) {
+	for i, a := range n.Attr {
+		if !prefixAttrs[a.Key] {
+			continue
+		}
+		if a.Key == "for" {
+			// The for attribute of an output is a list of IDs.
+			ids := strings.Fields(a.Val)
+			for j, id := range ids {
+				ids[j] = f.prefixed(id)
+			}
+			n.Attr[i].Val = strings.Join(ids, " ")
+			continue
+		}
+		n.Attr[i].Val = f.prefixed(a.Val)
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		f.prefixNode(c)
+	}
+}
+
+// prefixValues applies the form's Prefix to the keys of v.
+func (f *Form) prefixValues(v *url.Values) *url.Values {
+	if len(f.Prefix) == 0 {
+		return v
+	}
+	p := &url.Values{}
+	for k, vv := range *v {
+		(*p)[f.prefixed(k)] = vv
+	}
+	return p
+}
+
+// unprefixValues returns the values submitted for the form's fields, with
+// the form's Prefix removed from the keys.
+//
+// Values that do not belong to a field on the form are discarded, except
+// for the security token.
+func (f *Form) unprefixValues(data *url.Values) *url.Values {
+	if len(f.Prefix) == 0 {
+		return data
+	}
+	v := &url.Values{}
+	add := func(name string) {
+		if vv, ok := (*data)[f.prefixed(name)]; ok && len(name) > 0 {
+			(*v)[name] = vv
+		}
+	}
+	add(SecureTokenName)
+	for _, n := range f.names() {
+		add(n)
+	}
+	return v
+}
@@ -1,5 +1,10 @@
 package form
 
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
 // Progress defines a progress meter form element type.
 type Progress struct {
 	HTML
@@ -11,3 +16,29 @@ type Meter struct {
 	HTML
 	Value, Min, Max, Low, High, Optimum float64
 }
+
+// Element retrieves the progress meter as an html.Node of type ElementNode.
+//
+// A zero Value is omitted, which renders an indeterminate progress bar.
+func (p *Progress) Element() *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Progress,
+		Data:     "progress",
+	}
+	n.Attr = nonZeroAttrs(p, "Value", "Max")
+	p.HTML.Attach(n)
+	return n
+}
+
+// Element retrieves the meter as an html.Node of type ElementNode.
+func (m *Meter) Element() *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Meter,
+		Data:     "meter",
+	}
+	n.Attr = nonZeroAttrs(m, "Value", "Min", "Max", "Low", "High", "Optimum")
+	m.HTML.Attach(n)
+	return n
+}
@@ -0,0 +1,24 @@
+package form
+
+// Tick creates an option for a tick mark on a Range.
+//
+// The label is optional. User agents that support tick labels display it
+// beside the mark.
+func Tick(value, label string) *Option {
+	return &Option{Value: value, Label: label}
+}
+
+// Ticks attaches tick marks to the range, and returns the DataList that
+// holds them.
+//
+// The range's List is set to the ID of the DataList, which is derived from
+// the range's ID (or Name). The DataList must be added to the same form,
+// usually right after the range:
+//
+//	rating := &form.Range{Name: "rating", Min: "1", Max: "5"}
+//	f.Add(rating, rating.Ticks(form.Tick("1", "Poor"), form.Tick("5", "Great")))
+func (r *Range) Ticks(ticks ...*Option) *DataList {
+	id := r.EnsureId(r.Name) + "-ticks"
+	r.List = id
+	return &DataList{HTML: HTML{Id: id}, Options: ticks}
+}
@@ -0,0 +1,110 @@
+package form
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Redactor removes user data from values before they leave the application.
+//
+// Values should pass through a Redactor before they are written to logs,
+// audit records, webhooks, or metrics labels. A value is redacted if its
+// field is listed in the form's Sensitive list, if the field is a Password,
+// or if its name matches one of the Patterns.
+type Redactor struct {
+	// Patterns match field names, using the syntax of path.Match. Names are
+	// matched in lower case.
+	Patterns []string
+	// Mask replaces redacted values. If it is empty, the package Mask is used.
+	Mask string
+}
+
+// DefaultRedactor is used by Form.MaskedValues.
+var DefaultRedactor = &Redactor{
+	Patterns: []string{"*password*", "*passwd*", "*secret*", "*token*", "*ssn*", "*card*"},
+}
+
+// Matches returns true if a field name matches one of the Patterns.
+func (r *Redactor) Matches(name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range r.Patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns the value, or the mask if the named field matches a pattern.
+//
+// This is suitable for single values, such as metrics labels. Use Values
+// when a form is available, so that its Sensitive fields are redacted too.
+func (r *Redactor) Redact(name, value string) string {
+	if r.Matches(name) {
+		return r.mask()
+	}
+	return value
+}
+
+// RedactValues returns a copy of v with values redacted by name.
+//
+// This is intended for raw submission data that has not been reconciled
+// into a form.
+func (r *Redactor) RedactValues(v *url.Values) *url.Values {
+	out := &url.Values{}
+	for k, vv := range *v {
+		for _, val := range vv {
+			out.Add(k, r.Redact(k, val))
+		}
+	}
+	return out
+}
+
+// Values returns the form's values, redacted.
+//
+// Like AsValues, the form's Prefix is applied to the names. Patterns are
+// matched against the prefixed names.
+func (r *Redactor) Values(f *Form) *url.Values {
+	redacted := map[string]bool{}
+	for _, n := range f.sensitiveNames() {
+		redacted[f.prefixed(n)] = true
+	}
+	for _, n := range f.passwordNames() {
+		redacted[f.prefixed(n)] = true
+	}
+
+	v := f.AsValues()
+	for k, vv := range *v {
+		if redacted[k] || r.Matches(k) {
+			for i := range vv {
+				vv[i] = r.mask()
+			}
+		}
+	}
+	return v
+}
+
+func (r *Redactor) mask() string {
+	if len(r.Mask) > 0 {
+		return r.Mask
+	}
+	return Mask
+}
+
+// passwordNames returns the names of the form's password fields, without
+// the form's Prefix.
+func (f *Form) passwordNames() []string {
+	names := []string{}
+	walkFields(f.allFields(), func(field Field) {
+		switch field := field.(type) {
+		case *Password:
+			names = append(names, field.Name)
+		case *Form:
+			for _, n := range field.passwordNames() {
+				names = append(names, field.prefixed(n))
+			}
+		}
+	})
+	return names
+}
@@ -0,0 +1,128 @@
+package form
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// ErrFormNotRegistered indicates that no builder is registered for a form name.
+var ErrFormNotRegistered = errors.New("Form not registered")
+
+// BuildFunc builds a new instance of a form.
+type BuildFunc func(ctx context.Context) (*Form, error)
+
+// AlterFunc modifies a form after it has been built.
+//
+// Alter hooks let one part of an application change forms declared by
+// another, for example to add a field to the login form, without changing
+// the code that declares it.
+type AlterFunc func(ctx context.Context, f *Form) error
+
+// DefaultRegistry is the registry used by Register, Alter, and FormHandlers
+// that do not have their own.
+var DefaultRegistry = NewRegistry()
+
+// Registry maps form names to the functions that build them.
+//
+// Forms are built on demand, each time they are requested, so a form that
+// is never used is never built. A Registry is safe for concurrent use.
+type Registry struct {
+	mx       sync.RWMutex
+	builders map[string]BuildFunc
+	alters   map[string][]AlterFunc
+}
+
+// NewRegistry creates a new, empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		builders: map[string]BuildFunc{},
+		alters:   map[string][]AlterFunc{},
+	}
+}
+
+// Register registers a builder in the DefaultRegistry.
+func Register(name string, fn BuildFunc) {
+	DefaultRegistry.Register(name, fn)
+}
+
+// Alter adds an alter hook to the DefaultRegistry.
+func Alter(name string, fn AlterFunc) {
+	DefaultRegistry.Alter(name, fn)
+}
+
+// Register sets the builder for the named form.
+//
+// Like database/sql.Register, this panics if fn is nil or if a builder is
+// already registered under the name, since either is a programming error.
+func (r *Registry) Register(name string, fn BuildFunc) {
+	if fn == nil {
+		panic("form: Register builder is nil for " + name)
+	}
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	if _, ok := r.builders[name]; ok {
+		panic("form: Register called twice for " + name)
+	}
+	r.builders[name] = fn
+}
+
+// Alter adds a hook that is run on the named form each time it is built.
+//
+// If name is empty, the hook is run on every form. Hooks for all forms run
+// before hooks for the named form, and each group runs in the order it was
+// added. A hook may be added before the form is registered.
+func (r *Registry) Alter(name string, fn AlterFunc) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.alters[name] = append(r.alters[name], fn)
+}
+
+// Build builds the named form and runs its alter hooks.
+//
+// If the form is not registered, ErrFormNotRegistered is returned. If a
+// hook fails, the error is returned along with the partly altered form.
+func (r *Registry) Build(ctx context.Context, name string) (*Form, error) {
+	r.mx.RLock()
+	fn, ok := r.builders[name]
+	hooks := append(append([]AlterFunc{}, r.alters[""]...), r.alters[name]...)
+	r.mx.RUnlock()
+	if !ok {
+		return nil, ErrFormNotRegistered
+	}
+
+	f, err := fn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range hooks {
+		if err := h(ctx, f); err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+// Names returns the names of all registered forms, sorted.
+func (r *Registry) Names() []string {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	names := make([]string, 0, len(r.builders))
+	for n := range r.builders {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Loader returns a Loader that builds forms from the registry.
+//
+// This allows registered forms to be cached with Definitions. Note that
+// Definitions caches the altered form, so hooks are not run again for
+// each copy.
+func (r *Registry) Loader() Loader {
+	return func(name string) (*Form, error) {
+		return r.Build(context.Background(), name)
+	}
+}
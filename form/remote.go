@@ -0,0 +1,103 @@
+package form
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Masterminds/engine/form/cache"
+)
+
+// ErrValidationTimeout indicates that a remote validator did not finish in time.
+var ErrValidationTimeout = errors.New("Could not be checked in time")
+
+// RemoteValidator wraps a Validator that performs I/O, such as checking a
+// database for a username that is already taken, or calling an external
+// API.
+//
+// Each check is limited by Timeout, and results may be cached, so that a
+// value checked by a ValidationHandler as the user types is not checked
+// again when the form is submitted. Since Form.Validate runs validators
+// concurrently, slow validators do not delay each other.
+type RemoteValidator struct {
+	Validator Validator
+
+	// Timeout limits each check. If the check does not finish in time,
+	// ErrValidationTimeout is returned. If Timeout is zero, only the
+	// context's deadline applies.
+	Timeout time.Duration
+
+	// Cache, if set, stores results for TTL, keyed by value. Key qualifies
+	// the values, so that several validators can share a cache. Timeouts
+	// and cancellations are not cached.
+	Cache cache.Cache
+	TTL   time.Duration
+	Key   string
+
+	// Clock, if set, is used in place of the system clock to compute
+	// expiration times of cached results.
+	Clock cache.Clock
+}
+
+// NewRemoteValidator creates a RemoteValidator with the given timeout.
+//
+// If ttl is greater than zero, results are cached in memory for that long.
+func NewRemoteValidator(v Validator, timeout, ttl time.Duration) *RemoteValidator {
+	r := &RemoteValidator{Validator: v, Timeout: timeout, TTL: ttl}
+	if ttl > 0 {
+		r.Cache = cache.NewMemory(ttl)
+	}
+	return r
+}
+
+// Validate checks the value, or returns the cached result of a previous check.
+func (r *RemoteValidator) Validate(ctx context.Context, value string) error {
+	key := cache.NamespaceKey(r.Key, value)
+	if r.Cache != nil {
+		if v, err := r.Cache.Get(key); err == nil {
+			if msg, _ := v.(string); len(msg) > 0 {
+				return errors.New(msg)
+			}
+			return nil
+		}
+	}
+
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Validator.Validate(ctx, value)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+	}
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return ErrValidationTimeout
+	case context.Canceled:
+		return ctx.Err()
+	}
+
+	if r.Cache != nil && r.TTL > 0 {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		r.Cache.Set(key, msg, r.now().Add(r.TTL))
+	}
+	return err
+}
+
+// now returns the time of the validator's clock.
+func (r *RemoteValidator) now() time.Time {
+	if r.Clock != nil {
+		return r.Clock.Now()
+	}
+	return cache.SystemClock.Now()
+}
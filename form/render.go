@@ -0,0 +1,286 @@
+package form
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// RenderMode describes how whitespace is handled when rendering HTML.
+type RenderMode uint8
+
+const (
+	// Compact renders without any insignificant whitespace.
+	Compact RenderMode = iota
+	// Pretty renders indented, human-readable HTML.
+	Pretty
+)
+
+// DefaultIndent is the indentation used by Pretty when none is given.
+var DefaultIndent = "  "
+
+// RenderOptions control how a form is rendered.
+type RenderOptions struct {
+	Mode RenderMode
+	// Indent is used for each level of nesting in Pretty mode.
+	Indent string
+	// Context is passed to every renderer. It may be nil.
+	Context *RenderContext
+	// Direct writes the markup of plain inputs and their labels straight
+	// to a buffer, rather than building their attributes. The markup is
+	// the same. It is not allocation-free: each input and label still
+	// needs a raw node and a string, and containers and other fields are
+	// built as usual. For the benchmarks' large form it saves about an
+	// eighth of the allocations. Custom renderers still take precedence.
+	// It has no effect on forms with a Prefix, AutoID, or Echo policies.
+	Direct bool
+	// Slab allocates the nodes of inputs, labels, and containers from a
+	// few large blocks that are reused by later renders, rather than
+	// allocating each node on its own.
+	Slab bool
+}
+
+// Render writes a form to w as HTML.
+//
+// This is the default renderer. It builds the form's html.Node tree (see
+// Form.Element), and then renders it according to the options. A prepared
+// form is marked as Rendered.
+func Render(w io.Writer, f *Form, opts RenderOptions) error {
+	ctx := opts.Context
+	if opts.Direct && f.direct() {
+		ctx = ctx.withDirect(true)
+	}
+	if opts.Slab {
+		// The nodes are not used once the form is written, so the slab
+		// can be reused as soon as Render returns.
+		s := slabs.Get().(*nodeSlab)
+		defer func() {
+			s.reset()
+			slabs.Put(s)
+		}()
+		ctx = ctx.withSlab(s)
+	}
+	if err := renderNode(w, f.RenderElement(ctx), opts); err != nil {
+		return err
+	}
+	f.rendered()
+	return nil
+}
+
+// Reformat parses an HTML fragment and writes it to w according to the options.
+//
+// This allows markup produced by other means, such as theme templates, to
+// be rendered in the same mode as the default renderer. Whitespace-only
+// text inside of container elements is discarded before formatting.
+func Reformat(w io.Writer, r io.Reader, opts RenderOptions) error {
+	body := &html.Node{Type: html.ElementNode, DataAtom: atom.Body, Data: "body"}
+	nodes, err := html.ParseFragment(r, body)
+	if err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		trimWhitespace(n)
+		if n.Type == html.TextNode && len(strings.TrimSpace(n.Data)) == 0 {
+			continue
+		}
+		if err := renderNode(w, n, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderNode(w io.Writer, n *html.Node, opts RenderOptions) error {
+	if opts.Mode == Pretty {
+		indent := opts.Indent
+		if len(indent) == 0 {
+			indent = DefaultIndent
+		}
+		indentNode(n, indent, 0)
+		if err := html.Render(w, n); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	}
+	return html.Render(w, n)
+}
+
+// blockElements are the elements whose children are placed on their own
+// lines in Pretty mode. Whitespace between the children of these elements
+// is insignificant (or nearly so).
+var blockElements = map[atom.Atom]bool{
+	atom.Form:     true,
+	atom.Fieldset: true,
+	atom.Div:      true,
+	atom.Select:   true,
+	atom.Optgroup: true,
+	atom.Datalist: true,
+	atom.Table:    true,
+	atom.Thead:    true,
+	atom.Tbody:    true,
+	atom.Tr:       true,
+}
+
+// indentNode inserts whitespace between the children of block elements.
+func indentNode(n *html.Node, indent string, depth int) {
+	if n.Type != html.ElementNode || !blockElements[n.DataAtom] || n.FirstChild == nil {
+		return
+	}
+	inner := "\n" + strings.Repeat(indent, depth+1)
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		indentNode(c, indent, depth+1)
+		n.InsertBefore(&html.Node{Type: html.TextNode, Data: inner}, c)
+		c = next
+	}
+	n.AppendChild(&html.Node{Type: html.TextNode, Data: "\n" + strings.Repeat(indent, depth)})
+}
+
+// trimWhitespace removes whitespace-only text from block elements.
+func trimWhitespace(n *html.Node) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.TextNode && blockElements[n.DataAtom] && len(strings.TrimSpace(c.Data)) == 0 {
+			n.RemoveChild(c)
+		} else {
+			trimWhitespace(c)
+		}
+		c = next
+	}
+}
+
+// ErrFieldNotFound indicates that a form has no field with the given name.
+var ErrFieldNotFound = errors.New("Field not found")
+
+// RenderField writes a single field, with its label and errors, to w as HTML.
+//
+// The field is wrapped in a div with the class "field" and an ID derived
+// from the field's ID (e.g. "email-wrapper"), so that client-side code can
+// replace the markup of just this field. Error messages are rendered in a
+// list with the class "errors". Since the field is rendered outside of its
+// form, the wrapper has the dir, lang, and translate attributes that the
+// field inherits (see Inherited). The form's Defaults are applied first, and
+// its Prefix is applied to the names and IDs, as it is by Render. If the
+// form has AutoID set, the label and error list are given IDs as well.
+//
+// If there is no field with the given name, ErrFieldNotFound is returned.
+func (f *Form) RenderField(w io.Writer, name string) error {
+	field := f.Field(name)
+	if field == nil {
+		return ErrFieldNotFound
+	}
+	f.ApplyDefaults()
+	if f.AutoID {
+		f.AssignIDs()
+	}
+
+	wrap := &html.Node{Type: html.ElementNode, DataAtom: atom.Div, Data: "div"}
+	for _, n := range fieldNodes(nil, field) {
+		wrap.AppendChild(n)
+	}
+	if h := htmlOf(field); h != nil {
+		h.Id = h.EnsureId(name)
+		wrap.Attr = attr(wrap.Attr, "id", h.Id+"-wrapper")
+	}
+	wrap.Attr = attr(wrap.Attr, "class", "field")
+	wrap.Attr = append(wrap.Attr, inheritedAttrs(f.Inherited(field))...)
+
+	if msgs := f.Errors.Get(name); len(msgs) > 0 {
+		ul := &html.Node{Type: html.ElementNode, DataAtom: atom.Ul, Data: "ul"}
+		ul.Attr = attr(ul.Attr, "class", "errors")
+		if h := htmlOf(field); f.AutoID && h != nil {
+			ul.Attr = attr(ul.Attr, "id", h.Id+"-errors")
+		}
+		for _, m := range msgs {
+			li := &html.Node{Type: html.ElementNode, DataAtom: atom.Li, Data: "li"}
+			li.AppendChild(&html.Node{Type: html.TextNode, Data: m})
+			ul.AppendChild(li)
+		}
+		wrap.AppendChild(ul)
+	}
+	f.applyEcho(wrap)
+	if f.AutoID {
+		labelIDs(wrap)
+	}
+	if len(f.Prefix) > 0 {
+		f.prefixNode(wrap)
+	}
+	return html.Render(w, wrap)
+}
+
+// fieldNodes returns the nodes for a field, including its label.
+//
+// Fields with a Label property get a label element. Checkboxes and radio
+// buttons are wrapped by their label, while other fields are preceded by it.
+func fieldNodes(ctx *RenderContext, f Field) []*html.Node {
+	text := labelOf(f)
+	if ctx.direct() {
+		if nodes := directNodes(ctx, f, text); nodes != nil {
+			return nodes
+		}
+	}
+	s := ctx.nodes()
+	switch f.(type) {
+	case *Checkbox, *Radio:
+		n := elementOf(ctx, f)
+		if n == nil || len(text) == 0 {
+			return nodeList(n)
+		}
+		l := s.node(html.ElementNode, atom.Label, "label")
+		l.AppendChild(n)
+		l.AppendChild(s.node(html.TextNode, 0, text))
+		return []*html.Node{l}
+	}
+
+	l := labelNode(s, f, text)
+	n := elementOf(ctx, f)
+	if n == nil {
+		return nil
+	}
+	if l == nil {
+		return []*html.Node{n}
+	}
+	return []*html.Node{l, n}
+}
+
+// labelNode creates a label element for a field.
+//
+// The field is given an ID (based on its name) if it does not have one. If
+// text is empty, this returns nil.
+func labelNode(s *nodeSlab, f Field, text string) *html.Node {
+	if len(text) == 0 {
+		return nil
+	}
+	l := s.node(html.ElementNode, atom.Label, "label")
+	if h := htmlOf(f); h != nil {
+		h.Id = h.EnsureId(nameOf(f))
+		if len(h.Id) > 0 {
+			l.Attr = attr(l.Attr, "for", h.Id)
+		}
+	}
+	l.AppendChild(s.node(html.TextNode, 0, text))
+	return l
+}
+
+func nodeList(n *html.Node) []*html.Node {
+	if n == nil {
+		return nil
+	}
+	return []*html.Node{n}
+}
+
+// labelOf returns the value of a field's Label property, or the empty string.
+//
+// Label elements do not have a Label property, so they return the empty
+// string.
+func labelOf(f Field) string {
+	switch f.(type) {
+	case *Label, *Option, *OptGroup:
+		return ""
+	}
+	return stringField(f, "Label")
+}
@@ -0,0 +1,365 @@
+package form
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestRender(t *testing.T) {
+	f := New("login", "/login")
+	f.Fields = []Field{
+		&Text{Name: "user", Label: "User"},
+		&Checkbox{Name: "remember", Value: "1", Label: "Remember me", Checked: true},
+		&Div{Fields: []Field{&Submit{Name: "go", Value: "Go"}}},
+	}
+
+	var b bytes.Buffer
+	if err := Render(&b, f, RenderOptions{}); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	expect := `<form action="/login" name="login" id="login">` +
+		`<label for="user">User</label><input type="text" name="user" id="user"/>` +
+		`<label><input type="checkbox" name="remember" value="1" checked="checked"/>Remember me</label>` +
+		`<div><input type="submit" name="go" value="Go"/></div></form>`
+	if b.String() != expect {
+		t.Errorf("Unexpected compact output:\n%s", b.String())
+	}
+
+	f = New("search", "/search")
+	f.Fields = []Field{
+		&Div{Fields: []Field{&Text{Name: "q"}}},
+	}
+
+	b.Reset()
+	if err := Render(&b, f, RenderOptions{Mode: Pretty, Indent: "\t"}); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	expect = "<form action=\"/search\" name=\"search\" id=\"search\">\n" +
+		"\t<div>\n" +
+		"\t\t<input type=\"text\" name=\"q\"/>\n" +
+		"\t</div>\n" +
+		"</form>\n"
+	if b.String() != expect {
+		t.Errorf("Unexpected pretty output:\n%s", b.String())
+	}
+}
+
+func TestRenderers(t *testing.T) {
+	r := DefaultRenderers
+	defer func() { DefaultRenderers = r }()
+	DefaultRenderers = NewRenderers()
+
+	DefaultRenderers.Register((*Hidden)(nil), func(f Field, ctx *RenderContext) *html.Node {
+		return &html.Node{Type: html.CommentNode, Data: "hidden"}
+	})
+	DefaultRenderers.RegisterName((*Text)(nil), "special", func(f Field, ctx *RenderContext) *html.Node {
+		return &html.Node{Type: html.TextNode, Data: "special"}
+	})
+
+	f := New("test", "/test")
+	f.Fields = []Field{
+		&Hidden{Name: "h"},
+		&Text{Name: "special"},
+		&Text{Name: "plain"},
+	}
+
+	var b bytes.Buffer
+	Render(&b, f, RenderOptions{})
+	expect := `<form action="/test" name="test" id="test"><!--hidden-->special<input type="text" name="plain"/></form>`
+	if b.String() != expect {
+		t.Errorf("Unexpected output:\n%s", b.String())
+	}
+}
+
+func TestRenderFieldInherited(t *testing.T) {
+	f := New("test", "test")
+	f.Dir = RTL
+	f.Lang = "ar"
+	f.Add(&FieldSet{HTML: HTML{Lang: "fa"}, Fields: []Field{&Text{Name: "t"}}})
+	f.AddExternal(&Submit{Name: "go"})
+	f.Element()
+
+	var b bytes.Buffer
+	if err := f.RenderField(&b, "t"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `class="field" dir="rtl" lang="fa"`) {
+		t.Errorf("Expected inherited attributes on wrapper, got %s", b.String())
+	}
+
+	if h := f.Inherited(f.Field("t")); h.Dir != RTL || h.Lang != "fa" {
+		t.Errorf("Unexpected inherited attributes: %+v", h)
+	}
+	if s := f.External[0].(*Submit); s.Dir != RTL || s.Lang != "ar" {
+		t.Errorf("Expected external field to inherit from the form, got %q, %q", s.Dir, s.Lang)
+	}
+	if h := f.Inherited(&Text{HTML: HTML{Lang: "en"}}); h.Dir != RTL || h.Lang != "en" {
+		t.Errorf("Expected a field's own attributes to win, got %+v", h)
+	}
+}
+
+func TestRenderField(t *testing.T) {
+	f := New("signup", "/signup")
+	f.Fields = []Field{
+		&Div{Fields: []Field{
+			&Text{Name: "user", Label: "User", Value: "matt"},
+		}},
+	}
+	f.Errors.Add("user", "is taken")
+
+	var b bytes.Buffer
+	if err := f.RenderField(&b, "user"); err != nil {
+		t.Fatalf("Failed to render field: %s", err)
+	}
+	expect := `<div id="user-wrapper" class="field"><label for="user">User</label>` +
+		`<input type="text" name="user" value="matt" id="user"/>` +
+		`<ul class="errors"><li>is taken</li></ul></div>`
+	if b.String() != expect {
+		t.Errorf("Unexpected output:\n%s", b.String())
+	}
+
+	if err := f.RenderField(&b, "nope"); err != ErrFieldNotFound {
+		t.Errorf("Expected ErrFieldNotFound, got %v", err)
+	}
+
+	b.Reset()
+	f.Prefix = "a-"
+	f.RenderField(&b, "user")
+	if !strings.Contains(b.String(), `name="a-user"`) || !strings.Contains(b.String(), `id="a-user-wrapper"`) {
+		t.Errorf("Expected prefixed field, got:\n%s", b.String())
+	}
+}
+
+func TestRenderContext(t *testing.T) {
+	f := New("test", "/test")
+	f.Fields = []Field{
+		&Div{Fields: []Field{&Script{Src: "/a.js"}}},
+	}
+
+	for _, nonce := range []string{"one", "two"} {
+		ctx := NewRenderContext(WithNonce(context.Background(), nonce))
+		var b bytes.Buffer
+		Render(&b, f, RenderOptions{Context: ctx})
+		expect := `<form action="/test" name="test" id="test"><div><script src="/a.js" nonce="` + nonce + `"></script></div></form>`
+		if b.String() != expect {
+			t.Errorf("Unexpected output:\n%s", b.String())
+		}
+	}
+	if n := f.Fields[0].(*Div).Fields[0].(*Script).Nonce; n != "" {
+		t.Errorf("Expected the form to be unmodified, got nonce %q", n)
+	}
+
+	// The context's nonce replaces a stale one set on the form, and its
+	// locale is the language of a form that has none.
+	f.SetNonce("stale")
+	var out bytes.Buffer
+	Render(&out, f, RenderOptions{Context: &RenderContext{Nonce: "fresh", Locale: "de-CH"}})
+	expect := `<form action="/test" name="test" id="test" lang="de-CH"><div><script src="/a.js" nonce="fresh"></script></div></form>`
+	if out.String() != expect {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+	f.Fields[0].(*Div).Fields[0].(*Script).Nonce = ""
+
+	ctx := &RenderContext{Renderers: NewRenderers()}
+	ctx.Renderers.Register((*Script)(nil), func(f Field, ctx *RenderContext) *html.Node {
+		return &html.Node{Type: html.TextNode, Data: "script"}
+	})
+	var b bytes.Buffer
+	Render(&b, f, RenderOptions{Context: ctx})
+	if expect := `<form action="/test" name="test" id="test"><div>script</div></form>`; b.String() != expect {
+		t.Errorf("Unexpected output:\n%s", b.String())
+	}
+}
+
+func TestAssetHandler(t *testing.T) {
+	srv := httptest.NewServer(http.StripPrefix("/form/", AssetHandler()))
+	defer srv.Close()
+
+	for file, expect := range map[string]string{
+		"form.js":  EnhancementScript,
+		"form.css": Stylesheet,
+	} {
+		res, err := http.Get(srv.URL + "/form/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		if res.StatusCode != 200 || string(b) != expect || len(b) == 0 {
+			t.Errorf("Unexpected response for %s: %d", file, res.StatusCode)
+		}
+	}
+	if res, err := http.Get(srv.URL + "/form/nope.js"); err != nil || res.StatusCode != 404 {
+		t.Errorf("Expected a 404 for a missing asset.")
+	}
+}
+
+func TestRenderLazy(t *testing.T) {
+	built := 0
+	admin := false
+	f := New("profile", "/profile")
+	f.Fields = []Field{
+		&Lazy{Build: func() []Field {
+			built++
+			return []Field{&Text{Name: "nick"}, &Text{Name: "bio"}}
+		}},
+		&FieldSet{Name: "admin", Fields: []Field{&Lazy{
+			When: func() bool { return admin },
+			Build: func() []Field {
+				t.Error("Expected hidden branch not to be built")
+				return nil
+			},
+		}}},
+	}
+
+	for _, mode := range []RenderMode{Compact, Pretty} {
+		var b bytes.Buffer
+		if err := Render(&b, f, RenderOptions{Mode: mode}); err != nil {
+			t.Fatalf("Failed to render: %s", err)
+		}
+		out := strings.Join(strings.Fields(b.String()), "")
+		expect := `<formaction="/profile"name="profile"id="profile">` +
+			`<inputtype="text"name="nick"/><inputtype="text"name="bio"/>` +
+			`<fieldsetname="admin"></fieldset></form>`
+		if out != expect {
+			t.Errorf("Unexpected output:\n%s", b.String())
+		}
+	}
+	if built != 1 {
+		t.Errorf("Expected fields to be built once, got %d", built)
+	}
+
+	Reconcile(f, &url.Values{"bio": []string{"Hello"}})
+	if v := f.Field("bio").(*Text).Value; v != "Hello" {
+		t.Errorf("Expected 'Hello', got %q", v)
+	}
+	if p := f.PathOf(f.Field("bio")); p != "profile.bio" {
+		t.Errorf("Expected path 'profile.bio', got %q", p)
+	}
+}
+
+// largeForm creates a form with sets fieldsets of ten fields each.
+func largeForm(sets int) *Form {
+	f := New("large", "/large")
+	for i := 0; i < sets; i++ {
+		fs := &FieldSet{Name: fmt.Sprintf("set%d", i), Legend: fmt.Sprintf("Set %d", i)}
+		for j := 0; j < 10; j++ {
+			name := fmt.Sprintf("f%d-%d", i, j)
+			switch j % 5 {
+			case 0:
+				fs.Fields = append(fs.Fields, &Text{Name: name, Label: "Text", Value: "value"})
+			case 1:
+				fs.Fields = append(fs.Fields, &Checkbox{Name: name, Label: "Check", Value: "1"})
+			case 2:
+				fs.Fields = append(fs.Fields, &Select{Name: name, Label: "Pick", Options: []OptionItem{
+					&Option{Value: "a", Label: "A"}, &Option{Value: "b", Label: "B", Selected: true},
+				}})
+			case 3:
+				fs.Fields = append(fs.Fields, &TextArea{Name: name, Value: "Lorem ipsum"})
+			default:
+				fs.Fields = append(fs.Fields, &Number{Name: name, Label: "Count", Value: "3"})
+			}
+		}
+		f.Fields = append(f.Fields, fs)
+	}
+	f.Fields = append(f.Fields, &Submit{Name: "go", Value: "Go"})
+	return f
+}
+
+func BenchmarkRender(b *testing.B) {
+	f := largeForm(60)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Render(ioutil.Discard, f, RenderOptions{})
+	}
+}
+
+func TestRenderDirect(t *testing.T) {
+	newForm := func() *Form {
+		f := largeForm(2)
+		in := Input{
+			HTML: HTML{
+				Class: []string{"a", `b"c`}, AccessKey: "k", Dir: RTL, Lang: "en", Style: "color: red",
+				TabIndex: "2", Title: `Tom's <"field"> & more`, Translate: "no",
+				ContentEditable: OFalse, Hidden: OTrue, Spellcheck: OTrue,
+				Data: map[string]string{"data-x": "1\r2"},
+			},
+			Accept: "image/*", Alt: "alt", Autocomplete: "off", Dirname: "d.dir", List: "l",
+			InputMode: "numeric", Max: "9", Min: "1", MaxLength: "3", Name: "everything",
+			Pattern: "[0-9]+", Placeholder: "<n>", Step: "1", Value: "a&b",
+			Height: 10, Width: 20, Size: 3, Autofocus: true, Checked: true, Disabled: true,
+			FormNoValidate: true, Multiple: true, ReadOnly: true, Required: true,
+		}
+		text, number, check, hidden, input := Text(in), Number(in), Checkbox(in), Hidden(in), in
+		f.Fields = append(f.Fields, &text, &number, &check, &hidden, &input,
+			&Password{Name: "secret", Value: "hunter2"}, &Radio{Name: "r", Value: "1", Label: "One"},
+			&Reset{Name: "reset"}, &ButtonInput{Name: "b"}, &Tel{Name: "tel"}, &URL{Name: "url"},
+			&Email{Name: "email"}, &Date{Name: "date"}, &Time{Name: "time"}, &Range{Name: "range"},
+			&Color{Name: "color"}, &Month{Name: "month"}, &Week{Name: "week"},
+			&DatetimeLocal{Name: "when"}, &Search{Name: "q", Dirname: "q.dir"},
+			&Text{Name: "labeled", Label: `Tom's <"label"> & more`},
+			&Checkbox{Name: "agree", Label: "I <agree>", HTML: HTML{Id: "agree-1"}})
+		return f
+	}
+
+	for _, mode := range []RenderMode{Compact, Pretty} {
+		var nodes, direct bytes.Buffer
+		if err := Render(&nodes, newForm(), RenderOptions{Mode: mode}); err != nil {
+			t.Fatalf("Failed to render: %s", err)
+		}
+		if err := Render(&direct, newForm(), RenderOptions{Mode: mode, Direct: true}); err != nil {
+			t.Fatalf("Failed to render directly: %s", err)
+		}
+		if nodes.String() != direct.String() {
+			t.Errorf("Direct output differs:\n%s\n%s", nodes.String(), direct.String())
+		}
+	}
+
+	// Custom renderers take precedence over writing directly.
+	r := NewRenderers()
+	r.Register((*Text)(nil), func(f Field, ctx *RenderContext) *html.Node {
+		return &html.Node{Type: html.TextNode, Data: "custom"}
+	})
+	f := New("custom", "/")
+	f.Fields = []Field{&Text{Name: "t"}}
+	var b bytes.Buffer
+	Render(&b, f, RenderOptions{Direct: true, Context: &RenderContext{Renderers: r}})
+	if !strings.Contains(b.String(), ">custom<") {
+		t.Errorf("Expected custom renderer to be used, got %s", b.String())
+	}
+}
+
+func TestRenderSlab(t *testing.T) {
+	newForm := func() *Form {
+		f := largeForm(40)
+		f.Prefix = "p-"
+		f.AutoID = true
+		f.Fields = append(f.Fields, String("Note"), &Password{Name: "secret", Value: "hunter2", Label: "Secret"},
+			&Div{Fields: []Field{&Radio{Name: "r", Value: "1", Label: "One"}}})
+		return f
+	}
+
+	var nodes bytes.Buffer
+	if err := Render(&nodes, newForm(), RenderOptions{}); err != nil {
+		t.Fatalf("Failed to render: %s", err)
+	}
+	// Render more than once, so that the reused slab is checked too.
+	for i := 0; i < 3; i++ {
+		var slab bytes.Buffer
+		if err := Render(&slab, newForm(), RenderOptions{Slab: true}); err != nil {
+			t.Fatalf("Failed to render from slab: %s", err)
+		}
+		if nodes.String() != slab.String() {
+			t.Fatalf("Slab output differs:\n%s\n%s", nodes.String(), slab.String())
+		}
+	}
+}
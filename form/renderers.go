@@ -0,0 +1,115 @@
+package form
+
+import (
+	"bytes"
+	"html/template"
+	"reflect"
+	"sync"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// RenderFunc renders a field as an html.Node.
+//
+// The context may be nil. Returning nil indicates that the field should be
+// rendered by its default renderer instead.
+type RenderFunc func(Field, *RenderContext) *html.Node
+
+// DefaultRenderers is the registry used when a RenderContext does not have one.
+var DefaultRenderers = NewRenderers()
+
+// Renderers is a registry of custom renderers for field types.
+//
+// A renderer may be registered for every field of a type, or for only the
+// fields of a type with a particular name. When a field is rendered, a
+// renderer registered for its type and name is used first, then one
+// registered for its type, and finally the field's own Element method.
+//
+// A Renderers is safe for concurrent use.
+type Renderers struct {
+	mx     sync.RWMutex
+	byType map[reflect.Type]RenderFunc
+	byName map[reflect.Type]map[string]RenderFunc
+}
+
+// NewRenderers creates a new, empty registry.
+func NewRenderers() *Renderers {
+	return &Renderers{
+		byType: map[reflect.Type]RenderFunc{},
+		byName: map[reflect.Type]map[string]RenderFunc{},
+	}
+}
+
+// Register sets the renderer for all fields of the same type as field.
+//
+// The field is used only for its type, so a nil pointer (e.g. (*Text)(nil))
+// is fine.
+func (r *Renderers) Register(field Field, fn RenderFunc) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.byType[reflect.TypeOf(field)] = fn
+}
+
+// RegisterName sets the renderer for fields of the same type as field with the given name.
+func (r *Renderers) RegisterName(field Field, name string, fn RenderFunc) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	t := reflect.TypeOf(field)
+	if _, ok := r.byName[t]; !ok {
+		r.byName[t] = map[string]RenderFunc{}
+	}
+	r.byName[t][name] = fn
+}
+
+// RegisterTemplate sets a template as the renderer for fields like field.
+//
+// If name is not empty, the template is only used for fields with that name.
+// The template is executed with the field as its data.
+func (r *Renderers) RegisterTemplate(field Field, name string, t *template.Template) {
+	if len(name) > 0 {
+		r.RegisterName(field, name, TemplateRenderer(t))
+		return
+	}
+	r.Register(field, TemplateRenderer(t))
+}
+
+// Lookup returns the renderer for a field, or nil if there is none.
+func (r *Renderers) Lookup(f Field) RenderFunc {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	t := reflect.TypeOf(f)
+	if names, ok := r.byName[t]; ok {
+		if fn, ok := names[nameOf(f)]; ok {
+			return fn
+		}
+	}
+	return r.byType[t]
+}
+
+// TemplateRenderer creates a RenderFunc that executes a template.
+//
+// The template's output is parsed as an HTML fragment. If the template
+// fails to execute or produces unparseable output, nil is returned so that
+// the default renderer is used.
+func TemplateRenderer(t *template.Template) RenderFunc {
+	return func(f Field, ctx *RenderContext) *html.Node {
+		var b bytes.Buffer
+		if err := t.Execute(&b, f); err != nil {
+			return nil
+		}
+		body := &html.Node{Type: html.ElementNode, DataAtom: atom.Body, Data: "body"}
+		nodes, err := html.ParseFragment(&b, body)
+		if err != nil {
+			return nil
+		}
+
+		// A document node renders only its children, so it can hold a
+		// fragment made of several nodes.
+		n := &html.Node{Type: html.DocumentNode}
+		for _, c := range nodes {
+			n.AppendChild(c)
+		}
+		return n
+	}
+}
@@ -0,0 +1,375 @@
+package form
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/engine/form/cache"
+)
+
+// ErrUploadNotFound indicates that a directly uploaded object does not
+// exist in the store.
+var ErrUploadNotFound = errors.New("Uploaded file not found")
+
+// UploadError indicates that a directly uploaded object is not acceptable.
+type UploadError struct {
+	Key, Reason string
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("Uploaded file %q %s", e.Key, e.Reason)
+}
+
+// Presigner creates URLs that allow one request on one object of a store,
+// for a limited time, without further credentials.
+type Presigner interface {
+	PresignURL(method, key string, expires time.Duration) (string, error)
+}
+
+// S3Presigner presigns URLs for objects in an S3 bucket, with AWS
+// Signature Version 4.
+//
+// Services that are compatible with S3, such as MinIO, can be used by
+// setting Endpoint.
+type S3Presigner struct {
+	Bucket, Region string
+	// AccessKey and SecretKey are the credentials that sign the URLs.
+	// SessionToken is needed for temporary credentials.
+	AccessKey, SecretKey, SessionToken string
+	// Endpoint is the URL of the bucket. If it is empty, the bucket's
+	// virtual-hosted AWS endpoint is used. For a path-style endpoint,
+	// include the bucket in the path, such as "http://localhost:9000/uploads".
+	Endpoint string
+	// Clock, if set, is used in place of the system clock.
+	Clock cache.Clock
+}
+
+// PresignURL returns a URL for the request, valid for the given duration.
+func (s *S3Presigner) PresignURL(method, key string, expires time.Duration) (string, error) {
+	endpoint := s.Endpoint
+	if len(endpoint) == 0 {
+		endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", s.Bucket, s.Region)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	if s.Clock != nil {
+		now = s.Clock.Now()
+	}
+	now = now.UTC()
+	date := now.Format("20060102")
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	q := url.Values{}
+	q.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	q.Set("X-Amz-Credential", s.AccessKey+"/"+scope)
+	q.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	q.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+	q.Set("X-Amz-SignedHeaders", "host")
+	if len(s.SessionToken) > 0 {
+		q.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	query := awsQuery(q)
+	path := awsEscape(u.Path, false)
+
+	canonical := strings.Join([]string{method, path, query, "host:" + u.Host, "", "host", "UNSIGNED-PAYLOAD"}, "\n")
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + q.Get("X-Amz-Date") + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	k := awsHMAC([]byte("AWS4"+s.SecretKey), date)
+	for _, part := range []string{s.Region, "s3", "aws4_request"} {
+		k = awsHMAC(k, part)
+	}
+	sig := hex.EncodeToString(awsHMAC(k, toSign))
+
+	return u.Scheme + "://" + u.Host + path + "?" + query + "&X-Amz-Signature=" + sig, nil
+}
+
+func awsHMAC(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsQuery encodes a query in canonical form: sorted, with every byte but
+// the unreserved characters escaped.
+func awsQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, awsEscape(k, true)+"="+awsEscape(q.Get(k), true))
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape escapes a string as AWS signatures require. Slashes are kept
+// unless slash is true.
+func awsEscape(s string, slash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/' && !slash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// DirectUpload lets File fields upload straight to a store such as S3, so
+// that large files never pass through the application's servers.
+//
+// A File field is put in direct-upload mode with File.UploadTo. When a
+// file is chosen, EnhancementScript asks the Handler for a presigned URL,
+// uploads the file to it, and replaces the file input with the key of the
+// uploaded object. That key is the field's Value after the form is
+// submitted; pass the form to Verify before using the object.
+type DirectUpload struct {
+	Presigner Presigner
+	// Prefix is prepended to the keys of uploaded objects, such as
+	// "uploads/".
+	Prefix string
+	// Expires is how long a presigned URL is valid. If it is 0, 15 minutes
+	// is used.
+	Expires time.Duration
+	// MaxSize limits the size of uploaded files. If it is 0, the MaxFileSize
+	// of DefaultMultipartLimits is used.
+	MaxSize int64
+	// Types lists the allowed content types. If it is empty, any type is
+	// allowed.
+	Types []string
+	// Client performs the requests of Verify. If it is nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+
+	// mx serializes the recording of issued keys on cached forms.
+	mx sync.Mutex
+}
+
+// PresignedUpload is the response of DirectUpload.Handler.
+type PresignedUpload struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Key    string `json:"key"`
+}
+
+// UploadedObject describes an object that passed Verify.
+type UploadedObject struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+func (d *DirectUpload) expires() time.Duration {
+	if d.Expires > 0 {
+		return d.Expires
+	}
+	return 15 * time.Minute
+}
+
+func (d *DirectUpload) maxSize() int64 {
+	if d.MaxSize > 0 {
+		return d.MaxSize
+	}
+	return DefaultMultipartLimits.MaxFileSize
+}
+
+func (d *DirectUpload) allowed(ct string) bool {
+	if len(d.Types) == 0 {
+		return true
+	}
+	ct = strings.TrimSpace(strings.SplitN(ct, ";", 2)[0])
+	for _, t := range d.Types {
+		if strings.EqualFold(t, ct) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler returns a handler that issues presigned upload URLs.
+//
+// The request gives the form's secure token, the "name" of a File field,
+// and the "filename", "type" and "size" of the chosen file. The form must
+// be one prepared by h that has not yet been submitted or expired. The
+// filename is made safe with the handler's FilenamePolicy, and the size and
+// type are checked against the limits, so that most bad files are refused
+// before they are uploaded; Verify checks them again afterwards. The
+// response is a PresignedUpload encoded as JSON.
+//
+// The key is recorded on the cached form, for the field, so that Verify
+// accepts it only from that form's submission. Recording it caches the
+// form again, which restarts its expiration.
+func (d *DirectUpload) Handler(h *FormHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		handler := h.WithContext(r.Context())
+		tok := r.Form.Get(SecureTokenName)
+		f, err := handler.prepared(tok)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		field := r.Form.Get("name")
+		if _, ok := f.Field(field).(*File); !ok {
+			http.Error(w, ErrFieldNotFound.Error(), http.StatusNotFound)
+			return
+		}
+
+		policy := h.Filenames
+		if policy == nil {
+			policy = DefaultFilenamePolicy
+		}
+		name, err := policy.SafeName(r.Form.Get("filename"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		if size, err := strconv.ParseInt(r.Form.Get("size"), 10, 64); err != nil || size > d.maxSize() {
+			http.Error(w, (&PartTooLargeError{Name: name, Limit: d.maxSize()}).Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if !d.allowed(r.Form.Get("type")) {
+			http.Error(w, (&UploadError{Key: name, Reason: "has a type that is not allowed"}).Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		id, err := RandomId(handler.rand())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		key := d.Prefix + strings.TrimPrefix(id, "id-") + "/" + name
+		u, err := d.Presigner.PresignURL("PUT", key, d.expires())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := d.issue(handler, tok, field, key); err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PresignedUpload{Method: "PUT", URL: u, Key: key})
+	})
+}
+
+// issue records a key issued for a field on the cached form with the
+// token.
+func (d *DirectUpload) issue(h *FormHandler, tok, field, key string) error {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+	f, err := h.prepared(tok)
+	if err != nil {
+		return err
+	}
+	if f.uploadKeys == nil {
+		f.uploadKeys = map[string][]string{}
+	}
+	f.uploadKeys[field] = append(f.uploadKeys[field], key)
+	return h.cache.Set(h.key(tok), f, h.now().Add(h.expiration(f)))
+}
+
+// issued reports whether a DirectUpload issued the key for the named field.
+func (f *Form) issued(name, key string) bool {
+	for _, k := range f.uploadKeys[name] {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify checks that the object uploaded for the named File field of a
+// submitted form exists, and that its size and type are within the limits.
+//
+// The field's Value, the object's key, comes from the submission, so it is
+// accepted only if the Handler issued it for that field of that form; a
+// user cannot claim an object that was uploaded for another form. It is
+// checked against the Prefix too. ErrUploadNotFound is returned if there is
+// no such object, and an *UploadError if the object is not acceptable.
+func (d *DirectUpload) Verify(ctx context.Context, f *Form, name string) (*UploadedObject, error) {
+	file, ok := f.Field(name).(*File)
+	if !ok {
+		return nil, ErrFieldNotFound
+	}
+	key := file.Value
+	if !strings.HasPrefix(key, d.Prefix) || strings.Contains(key, "..") || !f.issued(name, key) {
+		return nil, &UploadError{Key: key, Reason: "is not an upload"}
+	}
+	u, err := d.Presigner.PresignURL("HEAD", key, time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("HEAD", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return nil, ErrUploadNotFound
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("Cannot verify upload %q: %s", key, res.Status)
+	}
+
+	obj := &UploadedObject{Key: key, Size: res.ContentLength, ContentType: res.Header.Get("Content-Type")}
+	if obj.Size > d.maxSize() {
+		return obj, &UploadError{Key: key, Reason: fmt.Sprintf("is larger than %d bytes", d.maxSize())}
+	}
+	if !d.allowed(obj.ContentType) {
+		return obj, &UploadError{Key: key, Reason: "has a type that is not allowed"}
+	}
+	return obj, nil
+}
+
+// UploadTo puts the field in direct-upload mode: EnhancementScript uploads
+// the chosen file with the DirectUpload handler at endpoint, and submits
+// the key of the uploaded object as the field's value.
+//
+// Direct uploads are of one file; Multiple is ignored.
+func (i *File) UploadTo(endpoint string) *File {
+	if i.Data == nil {
+		i.Data = map[string]string{}
+	}
+	i.Data["data-upload"] = endpoint
+	return i
+}
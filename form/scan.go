@@ -0,0 +1,133 @@
+package form
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Scanner checks uploaded files, for example for viruses, before they are
+// attached to a form.
+//
+// Scan returns a *RejectedFileError if the file must not be accepted. Any
+// other error means that the file could not be scanned, and fails the
+// submission.
+type Scanner interface {
+	Scan(ctx context.Context, filename string, r io.Reader) error
+}
+
+// ScannerFunc adapts a function to the Scanner interface.
+type ScannerFunc func(ctx context.Context, filename string, r io.Reader) error
+
+// Scan calls the function.
+func (s ScannerFunc) Scan(ctx context.Context, filename string, r io.Reader) error {
+	return s(ctx, filename, r)
+}
+
+// RejectedFileError indicates that a Scanner rejected an uploaded file.
+//
+// When RetrieveMultipart is given a rejected file, the file is not
+// attached, and the error is recorded in the form's Errors for the field.
+type RejectedFileError struct {
+	Filename string
+	// Reason is given by the scanner, such as the name of a virus.
+	Reason string
+}
+
+func (e *RejectedFileError) Error() string {
+	return fmt.Sprintf("File %q was rejected: %s", e.Filename, e.Reason)
+}
+
+// scanFile runs the scanner over a file. A rejection is returned as a
+// *RejectedFileError; any other error is returned as the second value.
+func scanFile(ctx context.Context, s Scanner, a Attachment) (*RejectedFileError, error) {
+	if s == nil {
+		return nil, nil
+	}
+	err := s.Scan(ctx, a.Filename, bytes.NewReader(a.Content))
+	if r, ok := err.(*RejectedFileError); ok {
+		return r, nil
+	}
+	return nil, err
+}
+
+// ClamdScanner is a Scanner that sends files to a ClamAV daemon.
+//
+// Files are streamed with the INSTREAM command, so the daemon does not
+// need access to the application's file system. The daemon's
+// StreamMaxLength must be at least as large as the largest file allowed.
+type ClamdScanner struct {
+	// Network and Address of the daemon, such as "tcp" and
+	// "localhost:3310", or "unix" and "/var/run/clamav/clamd.ctl".
+	Network, Address string
+	// Timeout limits each scan. If it is 0, a scan is limited only by the
+	// context.
+	Timeout time.Duration
+}
+
+// clamdChunk is the size of the chunks sent to clamd.
+const clamdChunk = 32 << 10
+
+// Scan sends the file to clamd, and returns a *RejectedFileError naming
+// the signature that was found, if any.
+func (c *ClamdScanner) Scan(ctx context.Context, filename string, r io.Reader) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, c.Network, c.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if dl, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(dl)
+	}
+	if c.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.Timeout))
+	}
+
+	w := bufio.NewWriter(conn)
+	if _, err := w.WriteString("zINSTREAM\x00"); err != nil {
+		return err
+	}
+	buf := make([]byte, clamdChunk)
+	size := make([]byte, 4)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			w.Write(size)
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	w.Write(size)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &RejectedFileError{Filename: filename, Reason: strings.TrimSuffix(reply, " FOUND")}
+	}
+	return fmt.Errorf("Unexpected reply from clamd: %q", reply)
+}
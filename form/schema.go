@@ -0,0 +1,173 @@
+package form
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrNoTable indicates that a table has no columns in the database's
+// information_schema, usually because it does not exist.
+var ErrNoTable = errors.New("Table not found")
+
+// Querier runs SQL queries. *sql.DB, *sql.Tx, and *sql.Conn are Queriers.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Schema declares forms from the tables of a database, and writes their
+// submissions back with parameterized statements, to bootstrap the create
+// and edit pages of an admin without an ORM.
+//
+// Tables are read from the standard information_schema.columns view, which
+// PostgreSQL, MySQL, MariaDB, and SQL Server provide; SQLite does not.
+type Schema struct {
+	// Placeholder returns the placeholder of the query's nth argument,
+	// counting from 1. The default is "?", as MySQL uses; for PostgreSQL,
+	// use DollarPlaceholder.
+	Placeholder func(n int) string
+	// Omit lists columns that are not given fields, such as generated keys
+	// and timestamps.
+	Omit []string
+
+	db Querier
+}
+
+// NewSchema creates a Schema that reads tables from db.
+func NewSchema(db Querier) *Schema {
+	return &Schema{db: db}
+}
+
+// DollarPlaceholder returns the placeholder "$n", as PostgreSQL uses.
+func DollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (s *Schema) placeholder(n int) string {
+	if s.Placeholder == nil {
+		return "?"
+	}
+	return s.Placeholder(n)
+}
+
+// column is a row of information_schema.columns.
+type column struct {
+	name, dataType string
+	nullable       bool
+	maxLength      sql.NullInt64
+	def            sql.NullString
+}
+
+// Form declares a form with a field for each column of a table.
+//
+// The table's name may be qualified by its schema, as "schema.table". The
+// form's Name is the table's; its Action is left for the caller to set.
+// Fields are named for their columns, in the columns' order, and labeled
+// with the names in words ("created_at" is labeled "Created at").
+//
+// Character columns are Text fields with the columns' lengths as their
+// MaxLength, and unbounded text columns are TextAreas. Integer columns are
+// Numbers, and other numeric columns are Numbers with the step "any".
+// Boolean columns are Checkboxes with the value "true"; date and time
+// columns are Dates and Times, and timestamps without time zones are
+// DatetimeLocals. Other columns, including timestamps with time zones, are
+// Text fields. A column that is not nullable and has no default is
+// Required, except a boolean, whose unchecked box is false. Fields that
+// need another type can be replaced, and validators added, once the form
+// is declared.
+//
+// If the table has no columns, ErrNoTable is returned.
+func (s *Schema) Form(ctx context.Context, table string) (*Form, error) {
+	cols, err := s.columns(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) == 0 {
+		return nil, ErrNoTable
+	}
+
+	omit := map[string]bool{}
+	for _, name := range s.Omit {
+		omit[name] = true
+	}
+	f := &Form{Name: table[strings.LastIndex(table, ".")+1:]}
+	for _, col := range cols {
+		if !omit[col.name] {
+			f.Fields = append(f.Fields, col.fields()...)
+		}
+	}
+	return f, nil
+}
+
+// columns reads the columns of a table, in order.
+func (s *Schema) columns(ctx context.Context, table string) ([]column, error) {
+	q := "SELECT column_name, data_type, is_nullable, character_maximum_length, column_default " +
+		"FROM information_schema.columns WHERE table_name = " + s.placeholder(1)
+	args := []interface{}{table}
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		q += " AND table_schema = " + s.placeholder(2)
+		args = []interface{}{table[i+1:], table[:i]}
+	}
+	q += " ORDER BY ordinal_position"
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols := []column{}
+	for rows.Next() {
+		var col column
+		var nullable string
+		if err := rows.Scan(&col.name, &col.dataType, &nullable, &col.maxLength, &col.def); err != nil {
+			return nil, err
+		}
+		col.dataType = strings.ToLower(col.dataType)
+		col.nullable = strings.EqualFold(nullable, "YES")
+		cols = append(cols, col)
+	}
+	return cols, rows.Err()
+}
+
+// fields declares the form fields of a column: the field, and a Label for
+// fields that have no Label of their own.
+func (c column) fields() []Field {
+	label := strings.Replace(c.name, "_", " ", -1)
+	if len(label) > 0 {
+		label = strings.ToUpper(label[:1]) + label[1:]
+	}
+	required := !c.nullable && !c.def.Valid
+	in := Input{Name: c.name, Label: label, Required: required}
+
+	switch c.dataType {
+	case "char", "character", "nchar", "varchar", "character varying", "nvarchar":
+		if c.maxLength.Valid && c.maxLength.Int64 > 0 {
+			in.MaxLength = strconv.FormatInt(c.maxLength.Int64, 10)
+		}
+		return []Field{(*Text)(&in)}
+	case "text", "tinytext", "mediumtext", "longtext", "ntext", "clob":
+		return []Field{
+			&Label{Field: c.name, Text: label},
+			&TextArea{Name: c.name, Required: required},
+		}
+	case "smallint", "integer", "int", "bigint", "tinyint", "mediumint",
+		"serial", "smallserial", "bigserial":
+		return []Field{(*Number)(&in)}
+	case "numeric", "decimal", "real", "float", "double", "double precision", "money":
+		in.Step = "any"
+		return []Field{(*Number)(&in)}
+	case "boolean", "bool", "bit":
+		in.Required = false
+		in.Value = "true"
+		return []Field{(*Checkbox)(&in)}
+	case "date":
+		return []Field{(*Date)(&in)}
+	case "time", "time without time zone":
+		return []Field{(*Time)(&in)}
+	case "timestamp", "timestamp without time zone", "datetime", "datetime2", "smalldatetime":
+		return []Field{(*DatetimeLocal)(&in)}
+	}
+	return []Field{(*Text)(&in)}
+}
@@ -0,0 +1,128 @@
+package form
+
+import (
+	"context"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Script describes a script element embedded in a form.
+//
+// Field types and themes that need client-side behavior (captchas, date
+// pickers, and so on) can add a Script to a form. If a page is served with a
+// Content-Security-Policy that disallows inline scripts, pass the nonce
+// issued for the current request in a RenderContext, or set Nonce (or use
+// Form.SetNonce). The context's nonce takes precedence, so a form that is
+// shared between requests never renders a stale one.
+type Script struct {
+	HTML
+	Src, Type, Nonce string
+	Async, Defer     bool
+
+	// Content is the inline script. It is not escaped.
+	Content string
+}
+
+// Element retrieves the script as an html.Node of type ElementNode.
+func (s *Script) Element() *html.Node {
+	return s.RenderElement(nil)
+}
+
+// RenderElement retrieves the script, using the context's nonce, or the
+// script's own if the context has none.
+func (s *Script) RenderElement(ctx *RenderContext) *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Script,
+		Data:     "script",
+	}
+	n.Attr = structToAttrs(s, "Src", "Type")
+	n.Attr = append(n.Attr, nonceAttr(s.Nonce, ctx)...)
+	n.Attr = append(n.Attr, boolAttrs(s, "Async", "Defer")...)
+	s.HTML.Attach(n)
+
+	if len(s.Content) > 0 {
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: s.Content})
+	}
+	return n
+}
+
+// Style describes an inline style element embedded in a form.
+//
+// As with Script, Nonce should be set when a Content-Security-Policy
+// restricts inline styles.
+type Style struct {
+	HTML
+	Media, Nonce string
+
+	// Content is the inline stylesheet. It is not escaped.
+	Content string
+}
+
+// Element retrieves the style as an html.Node of type ElementNode.
+func (s *Style) Element() *html.Node {
+	return s.RenderElement(nil)
+}
+
+// RenderElement retrieves the style, using the context's nonce, or the
+// style's own if the context has none.
+func (s *Style) RenderElement(ctx *RenderContext) *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Style,
+		Data:     "style",
+	}
+	n.Attr = structToAttrs(s, "Media")
+	n.Attr = append(n.Attr, nonceAttr(s.Nonce, ctx)...)
+	s.HTML.Attach(n)
+
+	if len(s.Content) > 0 {
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: s.Content})
+	}
+	return n
+}
+
+// SetNonce sets the CSP nonce on every Script and Style in the form.
+//
+// Nonces that were set before are replaced, so a form can be given the
+// nonce of each request it is rendered for. Since nonces are issued per
+// request, this should be called on the copy of the form that is about to
+// be rendered. When rendering a shared form, pass the nonce in a
+// RenderContext instead.
+func (f *Form) SetNonce(nonce string) {
+	walkFields(f.allFields(), func(field Field) {
+		switch field := field.(type) {
+		case *Script:
+			field.Nonce = nonce
+		case *Style:
+			field.Nonce = nonce
+		}
+	})
+}
+
+func nonceAttr(nonce string, ctx *RenderContext) []html.Attribute {
+	if n := ctx.nonce(); len(n) > 0 {
+		nonce = n
+	}
+	if len(nonce) == 0 {
+		return nil
+	}
+	return []html.Attribute{{Key: "nonce", Val: nonce}}
+}
+
+type nonceKey struct{}
+
+// WithNonce returns a copy of the context carrying a CSP nonce.
+//
+// Middleware that issues a Content-Security-Policy header can use this to
+// pass the nonce along to form rendering.
+func WithNonce(ctx context.Context, nonce string) context.Context {
+	return context.WithValue(ctx, nonceKey{}, nonce)
+}
+
+// Nonce returns the CSP nonce carried by the context, or the empty string.
+func Nonce(ctx context.Context) string {
+	n, _ := ctx.Value(nonceKey{}).(string)
+	return n
+}
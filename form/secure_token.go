@@ -1,7 +1,9 @@
 package form
 
 import (
-	"github.com/aokoli/goutils"
+	"crypto/rand"
+	"errors"
+	"io"
 )
 
 // The name of the token that is automatically placed into a form.
@@ -12,20 +14,57 @@ var SecureTokenName = "__token__"
 // The length of the security token.
 var SecurityTokenLength = 32
 
+// Rand is the default source of randomness for tokens and encryption.
+//
+// It is crypto/rand.Reader unless replaced, for example with a reader
+// backed by a validated module where FIPS policies require one. A
+// FormHandler may use its own source (see FormHandler.Rand).
+var Rand io.Reader = rand.Reader
+
+// ErrTokenLength indicates that SecurityTokenLength is not positive.
+var ErrTokenLength = errors.New("Security token length must be greater than zero")
+
+const tokenChars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
 // Generate a security token.
 //
-// This uses SecurityTokenLength to determine the appropriate length. If
-// that value is <= 0, this will panic.
+// This uses SecurityTokenLength to determine the appropriate length, and
+// Rand as the source of randomness. If the length is <= 0, or Rand fails,
+// this will panic.
 func SecurityToken() string {
-	tok, err := goutils.RandomAlphaNumeric(SecurityTokenLength)
+	tok, err := SecurityTokenFrom(Rand)
 	if err != nil {
-		// We panic because the only way to error out here is to pass in an
-		// illegal security token length.
 		panic(err)
 	}
 	return tok
 }
 
+// SecurityTokenFrom generates an alphanumeric security token from r.
+//
+// The token is SecurityTokenLength characters long. Bytes from r are
+// sampled without bias, so the same bytes always produce the same token.
+func SecurityTokenFrom(r io.Reader) (string, error) {
+	if SecurityTokenLength <= 0 {
+		return "", ErrTokenLength
+	}
+	// Bytes at or above max would favor the first characters, so they
+	// are discarded.
+	const max = 256 - 256%len(tokenChars)
+	tok := make([]byte, 0, SecurityTokenLength)
+	buf := make([]byte, SecurityTokenLength)
+	for len(tok) < SecurityTokenLength {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) < max && len(tok) < SecurityTokenLength {
+				tok = append(tok, tokenChars[int(b)%len(tokenChars)])
+			}
+		}
+	}
+	return string(tok), nil
+}
+
 // SecurityField returns a Hidden form element initialized with a security
 // token.
 func SecurityField() Hidden {
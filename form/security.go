@@ -0,0 +1,267 @@
+package form
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRateLimited indicates that a client has submitted a form more
+	// often than its SecurityPolicy allows.
+	ErrRateLimited = errors.New("Too many submissions")
+	// ErrOrigin indicates a submission from an origin that the form's
+	// SecurityPolicy does not allow.
+	ErrOrigin = errors.New("Submission from a disallowed origin")
+	// ErrHoneypot indicates a submission that filled in the honeypot field
+	// of the form's SecurityPolicy, as bots do.
+	ErrHoneypot = errors.New("Submission filled in the honeypot field")
+)
+
+// CSRFMode sets how long the security token of a prepared form is good for.
+type CSRFMode uint8
+
+const (
+	// CSRFSingleUse tokens are removed when the form is submitted, so a
+	// prepared form can be submitted once. This is what Retrieve does.
+	CSRFSingleUse CSRFMode = iota
+	// CSRFReusable tokens stay valid until they expire, so a form that is
+	// submitted repeatedly, such as a search box, keeps working.
+	CSRFReusable
+)
+
+// SecurityPolicy collects the security settings of a form in one place, so
+// that they are explicit and can be reviewed together.
+//
+// A policy is attached to a form with Form.Security, or to every form of a
+// handler with FormHandler.Security; a form's own policy takes precedence.
+// Policies are enforced by Prepare and RetrieveRequest. Forms with no
+// policy behave as they always have.
+type SecurityPolicy struct {
+	// CSRF sets whether a token can be used more than once.
+	CSRF CSRFMode
+	// TokenTTL is how long a prepared form is kept. If it is 0, the
+	// handler's Expiration is used.
+	TokenTTL time.Duration
+	// RateLimit is the number of submissions of the form allowed from one
+	// client in each RateWindow. If either is 0, there is no limit. Clients
+	// are identified by ClientIP, if the request carries it (see
+	// IPResolver.Handler), or by the request's RemoteAddr.
+	RateLimit  int
+	RateWindow time.Duration
+	// CheckOrigin rejects submissions whose Origin (or, failing that,
+	// Referer) header names an origin other than those in Origins, or the
+	// request's own host if Origins is empty. Requests with neither header
+	// are allowed, since browsers send one with every cross-origin post,
+	// unless RequireOrigin is set too. Set it to reject clients that strip
+	// both headers, at the cost of users whose privacy tools do.
+	CheckOrigin   bool
+	RequireOrigin bool
+	Origins       []string
+	// Honeypot is the name of a field that is added to the form when it is
+	// prepared. It is hidden from people, so a submission that fills it in
+	// was made by a bot.
+	Honeypot string
+	// MaxBodySize limits the size of a submission's body in bytes. The
+	// handler's policy limits the body as it is read; a form's own policy
+	// can only check the request's declared length, since the form is not
+	// known until its token has been read.
+	MaxBodySize int64
+}
+
+// HardenedPolicy is a strict profile, suitable as a handler's default.
+//
+// Tokens are single-use and expire after an hour, each client may submit a
+// form 20 times a minute, cross-origin submissions are rejected, a
+// honeypot field named "website" is added, and bodies are limited to 1MiB.
+var HardenedPolicy = &SecurityPolicy{
+	CSRF:        CSRFSingleUse,
+	TokenTTL:    time.Hour,
+	RateLimit:   20,
+	RateWindow:  time.Minute,
+	CheckOrigin: true,
+	Honeypot:    "website",
+	MaxBodySize: 1 << 20,
+}
+
+// policy returns the security policy of a form, or nil.
+func (f *FormHandler) policy(form *Form) *SecurityPolicy {
+	if form.Security != nil {
+		return form.Security
+	}
+	return f.Security
+}
+
+// honeypot adds the policy's honeypot field to a form that has none.
+func (p *SecurityPolicy) honeypot(form *Form) {
+	if len(p.Honeypot) == 0 || form.Field(p.Honeypot) != nil {
+		return
+	}
+	form.Fields = append(form.Fields, &Text{
+		HTML: HTML{
+			Style:    "position:absolute;left:-10000px",
+			TabIndex: "-1",
+			Data:     map[string]string{"aria-hidden": "true"},
+		},
+		Name:         p.Honeypot,
+		Autocomplete: "off",
+	})
+}
+
+// allowOrigin reports whether the request comes from an allowed origin.
+func (p *SecurityPolicy) allowOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 || origin == "null" {
+		ref := r.Header.Get("Referer")
+		if len(ref) == 0 {
+			return origin != "null" && !p.RequireOrigin
+		}
+		origin = ref
+	}
+	u, err := url.Parse(origin)
+	if err != nil || len(u.Host) == 0 {
+		return false
+	}
+	if len(p.Origins) == 0 {
+		return strings.EqualFold(u.Host, r.Host)
+	}
+	for _, o := range p.Origins {
+		if strings.EqualFold(o, u.Scheme+"://"+u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// check enforces the policy on a submission of a form.
+func (p *SecurityPolicy) check(h *FormHandler, fm *Form, r *http.Request) error {
+	if p.MaxBodySize > 0 && r.ContentLength > p.MaxBodySize {
+		return ErrSubmissionTooLarge
+	}
+	if p.CheckOrigin && !p.allowOrigin(r) {
+		return ErrOrigin
+	}
+	if len(p.Honeypot) > 0 && len(r.Form.Get(fm.prefixed(p.Honeypot))) > 0 {
+		return ErrHoneypot
+	}
+	if p.RateLimit > 0 && p.RateWindow > 0 {
+		// A RemoteAddr that is not an address, as from a test or a Unix
+		// socket, identifies the client as it is.
+		client := r.RemoteAddr
+		if ip := ClientIP(r.Context()); ip != nil {
+			client = ip.String()
+		} else if ip := parseHop(r.RemoteAddr); ip != nil {
+			client = ip.String()
+		}
+		if !h.limiter.allow(fm.Name+"\x00"+client, p.RateLimit, p.RateWindow, h.now()) {
+			return ErrRateLimited
+		}
+	}
+	return nil
+}
+
+// RetrieveRequest populates the cached form a request submits, like
+// Retrieve, and enforces the form's SecurityPolicy.
+//
+// The request's body is limited by the MaxBodySize of the handler's
+// policy, and parsed with ParseForm, so multipart submissions should use
+// RetrieveMultipart. The form is then checked against its policy. A
+// submission that fails a check returns ErrSubmissionTooLarge, ErrOrigin,
+// ErrHoneypot or ErrRateLimited; the form stays in the cache, except after
+// ErrHoneypot, since a bot has it. Single-use tokens are removed when the
+// form is retrieved, as Retrieve does; with reusable tokens, a copy of the
+// cached form is retrieved and the cached form is left for later.
+//
+// If the cache fails and the handler has a Fallback, the form is restored
+// from its cookie (see PrepareResponse).
+func (f *FormHandler) RetrieveRequest(r *http.Request) (*Form, error) {
+	fm, err := f.retrieveRequest(r)
+	return f.WithContext(r.Context()).published(r.Context(), fm, err)
+}
+
+func (f *FormHandler) retrieveRequest(r *http.Request) (*Form, error) {
+	if p := f.Security; p != nil && p.MaxBodySize > 0 && r.Body != nil {
+		r.Body = http.MaxBytesReader(nil, r.Body, p.MaxBodySize)
+	}
+	if err := r.ParseForm(); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, ErrSubmissionTooLarge
+		}
+		return nil, err
+	}
+	handler := f.WithContext(r.Context())
+	id := r.Form.Get(SecureTokenName)
+	if id == "" {
+		return nil, ErrNoToken
+	}
+	fm, err := handler.Get(id)
+	if err != nil && err != ErrFormNotFound && f.Fallback != nil {
+		fm, err = f.Fallback.restore(handler, r, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := handler.policy(fm)
+	if p == nil {
+		return handler.retrieve(fm, id, &r.Form)
+	}
+	if err := p.check(handler, fm, r); err != nil {
+		if err == ErrHoneypot {
+			handler.Remove(id)
+		}
+		return nil, err
+	}
+	if p.CSRF != CSRFReusable {
+		return handler.retrieve(fm, id, &r.Form)
+	}
+
+	if fm, err = copyForm(fm); err != nil {
+		return nil, err
+	}
+	if err := fm.Transition(Submitted); err != nil {
+		return nil, err
+	}
+	err = Reconcile(fm, &r.Form)
+	return fm, err
+}
+
+// rateLimiter counts submissions in fixed windows.
+type rateLimiter struct {
+	mx      sync.Mutex
+	windows map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	n     int
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{windows: map[string]*rateWindow{}}
+}
+
+// allow counts a submission under the key, and reports whether it is
+// within the limit.
+func (l *rateLimiter) allow(key string, limit int, window time.Duration, now time.Time) bool {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	if len(l.windows) > 10000 {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= window {
+				delete(l.windows, k)
+			}
+		}
+	}
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= window {
+		w = &rateWindow{start: now}
+		l.windows[key] = w
+	}
+	w.n++
+	return w.n <= limit
+}
@@ -1,10 +1,15 @@
 package form
 
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
 // Select defines a selection list form element.
 type Select struct {
 	HTML
 	Autofocus, Disabled, Multiple, Required bool
-	Form, Name                              string
+	Autocomplete, Form, Name                string
 	Size                                    uint64
 	Options                                 []OptionItem
 	Label                                   string
@@ -38,3 +43,113 @@ type Option struct {
 	// sent to the server. Label may be rendered as phrasing content.
 	Label, Value string
 }
+
+// Element retrieves the select list as an html.Node of type ElementNode.
+//
+// Options that are neither an *Option nor an *OptGroup are skipped, as are
+// nil options.
+func (s *Select) Element() *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Select,
+		Data:     "select",
+	}
+	n.Attr = structToAttrs(s, "Autocomplete", "Form", "Name")
+	n.Attr = append(n.Attr, nonZeroAttrs(s, "Size")...)
+	n.Attr = append(n.Attr, boolAttrs(s, "Autofocus", "Disabled", "Multiple", "Required")...)
+	s.HTML.Attach(n)
+
+	for _, o := range s.Options {
+		switch o := o.(type) {
+		case *Option:
+			if o != nil {
+				n.AppendChild(o.Element())
+			}
+		case *OptGroup:
+			if o != nil {
+				n.AppendChild(o.Element())
+			}
+		}
+	}
+	return n
+}
+
+// eachOption calls fn for each of the select's options, including the
+// options in its groups. Nil options are skipped.
+func (s *Select) eachOption(fn func(*Option)) {
+	for _, o := range s.Options {
+		switch o := o.(type) {
+		case *Option:
+			if o != nil {
+				fn(o)
+			}
+		case *OptGroup:
+			if o == nil {
+				continue
+			}
+			for _, oo := range o.Options {
+				if oo != nil {
+					fn(oo)
+				}
+			}
+		}
+	}
+}
+
+// Element retrieves the data list as an html.Node of type ElementNode.
+func (d *DataList) Element() *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Datalist,
+		Data:     "datalist",
+	}
+	d.HTML.Attach(n)
+	appendOptions(n, d.Options)
+	return n
+}
+
+// Element retrieves the option group as an html.Node of type ElementNode.
+func (o *OptGroup) Element() *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Optgroup,
+		Data:     "optgroup",
+	}
+	n.Attr = structToAttrs(o, "Label")
+	n.Attr = append(n.Attr, boolAttrs(o, "Disabled")...)
+	o.HTML.Attach(n)
+	appendOptions(n, o.Options)
+	return n
+}
+
+// Element retrieves the option as an html.Node of type ElementNode.
+//
+// The Label is used as the content of the option. If there is no Label,
+// the Value is used instead.
+func (o *Option) Element() *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Option,
+		Data:     "option",
+	}
+	n.Attr = structToAttrs(o, "Value")
+	n.Attr = append(n.Attr, boolAttrs(o, "Disabled", "Selected")...)
+	o.HTML.Attach(n)
+
+	text := o.Label
+	if len(text) == 0 {
+		text = o.Value
+	}
+	if len(text) > 0 {
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: text})
+	}
+	return n
+}
+
+func appendOptions(n *html.Node, options []*Option) {
+	for _, o := range options {
+		if o != nil {
+			n.AppendChild(o.Element())
+		}
+	}
+}
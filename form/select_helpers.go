@@ -0,0 +1,91 @@
+package form
+
+import (
+	"sort"
+	"strings"
+)
+
+// Translator localizes user-visible text.
+type Translator interface {
+	// Translate returns msg in the given locale, or msg itself if there is
+	// no translation.
+	Translate(locale, msg string) string
+}
+
+// CountrySelect creates a Select of countries.
+//
+// Option values are ISO 3166-1 alpha-2 codes (such as "US"), and the options
+// are ordered by their English names. See Countries, and Localize for other
+// languages.
+func CountrySelect(name string) *Select {
+	return codeSelect(name, Countries)
+}
+
+// LanguageSelect creates a Select of languages.
+//
+// Option values are ISO 639-1 codes (such as "en"), and the options are
+// ordered by their English names. See Languages.
+func LanguageSelect(name string) *Select {
+	return codeSelect(name, Languages)
+}
+
+// TimezoneSelect creates a Select of time zones.
+//
+// Option values are IANA zone names (such as "America/Chicago"), which can
+// be passed to time.LoadLocation. Zones are grouped by region. See
+// Timezones.
+func TimezoneSelect(name string) *Select {
+	s := &Select{Name: name}
+	groups := map[string]*OptGroup{}
+	for _, z := range Timezones {
+		i := strings.Index(z, "/")
+		if i < 0 {
+			s.Options = append(s.Options, &Option{Value: z, Label: z})
+			continue
+		}
+		region := z[:i]
+		g, ok := groups[region]
+		if !ok {
+			g = &OptGroup{Label: region}
+			groups[region] = g
+			s.Options = append(s.Options, g)
+		}
+		city := strings.Replace(z[i+1:], "_", " ", -1)
+		g.Options = append(g.Options, &Option{Value: z, Label: city})
+	}
+	return s
+}
+
+func codeSelect(name string, data []CodeLabel) *Select {
+	sorted := append([]CodeLabel{}, data...)
+	sort.Sort(byLabel(sorted))
+
+	s := &Select{Name: name, Options: make([]OptionItem, len(sorted))}
+	for i, c := range sorted {
+		s.Options[i] = &Option{Value: c.Code, Label: c.Label}
+	}
+	return s
+}
+
+type byLabel []CodeLabel
+
+func (b byLabel) Len() int           { return len(b) }
+func (b byLabel) Less(i, j int) bool { return b[i].Label < b[j].Label }
+func (b byLabel) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// Localize translates the labels of the select and its options.
+//
+// The order of the options is not changed.
+func (s *Select) Localize(t Translator, locale string) {
+	if len(s.Label) > 0 {
+		s.Label = t.Translate(locale, s.Label)
+	}
+	for _, o := range s.Options {
+		if g, ok := o.(*OptGroup); ok && g != nil {
+			g.Label = t.Translate(locale, g.Label)
+		}
+	}
+	s.eachOption(func(o *Option) {
+		o.Label = t.Translate(locale, o.Label)
+	})
+}
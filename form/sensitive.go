@@ -0,0 +1,163 @@
+package form
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/url"
+	"time"
+)
+
+// Mask replaces the values of sensitive fields in MaskedValues.
+var Mask = "********"
+
+// ErrDecrypt indicates that a sensitive value could not be decrypted.
+var ErrDecrypt = errors.New("Cannot decrypt sensitive value")
+
+// IsSensitive returns true if the named field is listed in Sensitive.
+//
+// Fields of embedded forms are checked against the embedded form's
+// Sensitive list, using their prefixed names.
+func (f *Form) IsSensitive(name string) bool {
+	for _, n := range f.sensitiveNames() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sensitiveNames returns the names of the form's sensitive fields, without
+// the form's Prefix.
+func (f *Form) sensitiveNames() []string {
+	names := append([]string{}, f.Sensitive...)
+	walkFields(f.allFields(), func(field Field) {
+		if sub, ok := field.(*Form); ok {
+			for _, n := range sub.sensitiveNames() {
+				names = append(names, sub.prefixed(n))
+			}
+		}
+	})
+	return names
+}
+
+// MaskedValues returns the form's values with sensitive values replaced by Mask.
+//
+// This should be used instead of AsValues whenever values are written to
+// logs or other records. It is a shorthand for DefaultRedactor.Values, so
+// password fields, and fields with names like "password" or "ssn", are
+// masked as well.
+func (f *Form) MaskedValues() *url.Values {
+	return DefaultRedactor.Values(f)
+}
+
+// EncryptSensitive returns a Cache that encrypts the values of sensitive fields.
+//
+// The key must be 16, 24, or 32 bytes long, selecting AES-128, AES-192, or
+// AES-256. Values are sealed with AES-GCM.
+//
+// The returned cache stores a copy of each form (see EncodeForm), so the
+// form passed to Set keeps its plaintext values, and can still be rendered.
+// As with any encoded form, function fields are not kept.
+func EncryptSensitive(c Cache, key []byte) (Cache, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &sensitiveCache{c, aead}, nil
+}
+
+type sensitiveCache struct {
+	c    Cache
+	aead cipher.AEAD
+}
+
+func (s *sensitiveCache) Get(id string) (*Form, error) {
+	f, err := s.c.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if f, err = copyForm(f); err != nil {
+		return nil, err
+	}
+	return f, eachSensitive(f, s.decrypt)
+}
+
+func (s *sensitiveCache) Set(id string, f *Form, expires time.Time) error {
+	f, err := copyForm(f)
+	if err != nil {
+		return err
+	}
+	if err := eachSensitive(f, s.encrypt); err != nil {
+		return err
+	}
+	return s.c.Set(id, f, expires)
+}
+
+func (s *sensitiveCache) Remove(id string) error {
+	return s.c.Remove(id)
+}
+
+func (s *sensitiveCache) encrypt(v string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(Rand, nonce); err != nil {
+		return "", err
+	}
+	b := s.aead.Seal(nonce, nonce, []byte(v), nil)
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *sensitiveCache) decrypt(v string) (string, error) {
+	b, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil || len(b) < s.aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+	n := s.aead.NonceSize()
+	p, err := s.aead.Open(nil, b[:n], b[n:], nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(p), nil
+}
+
+// eachSensitive replaces the non-empty Value of each sensitive field with fn(Value).
+func eachSensitive(f *Form, fn func(string) (string, error)) error {
+	sensitive := make(map[string]bool, len(f.Sensitive))
+	for _, n := range f.Sensitive {
+		sensitive[n] = true
+	}
+	var err error
+	walkFields(f.allFields(), func(field Field) {
+		if err != nil {
+			return
+		}
+		if sub, ok := field.(*Form); ok {
+			err = eachSensitive(sub, fn)
+			return
+		}
+		if !sensitive[nameOf(field)] {
+			return
+		}
+		if v := stringField(field, "Value"); len(v) > 0 {
+			if v, err = fn(v); err == nil {
+				setStringField(field, "Value", v)
+			}
+		}
+	})
+	return err
+}
+
+// copyForm returns a deep copy of a form.
+func copyForm(f *Form) (*Form, error) {
+	b, err := EncodeForm(f)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeForm(b)
+}
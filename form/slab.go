@@ -0,0 +1,179 @@
+package form
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// slabSize is the number of nodes, and of attributes, in each block of a slab.
+const slabSize = 256
+
+// nodeSlab allocates the nodes of a form in blocks.
+//
+// Allocating each node on the heap dominates the cost of rendering large
+// forms. A slab hands out nodes and attributes from a few large blocks
+// instead, and is reset once the form has been written, so that the blocks
+// are reused by the next render. A nil slab allocates on the heap.
+//
+// A slab is not safe for concurrent use.
+type nodeSlab struct {
+	nodes [][]html.Node
+	attrb [][]html.Attribute
+	// n and a are the indexes of the blocks in use.
+	n, a int
+}
+
+// slabs holds slabs for reuse between renders.
+var slabs = sync.Pool{
+	New: func() interface{} { return &nodeSlab{} },
+}
+
+// node returns a new node.
+func (s *nodeSlab) node(t html.NodeType, a atom.Atom, data string) *html.Node {
+	if s == nil {
+		return &html.Node{Type: t, DataAtom: a, Data: data}
+	}
+	if s.n == len(s.nodes) {
+		s.nodes = append(s.nodes, make([]html.Node, 0, slabSize))
+	}
+	b := s.nodes[s.n]
+	b = b[:len(b)+1]
+	s.nodes[s.n] = b
+	if len(b) == cap(b) {
+		s.n++
+	}
+	n := &b[len(b)-1]
+	*n = html.Node{Type: t, DataAtom: a, Data: data}
+	return n
+}
+
+// attrs returns an empty slice that can hold size attributes without
+// growing.
+func (s *nodeSlab) attrs(size int) []html.Attribute {
+	if s == nil || size > slabSize {
+		return make([]html.Attribute, 0, size)
+	}
+	if s.a == len(s.attrb) {
+		s.attrb = append(s.attrb, make([]html.Attribute, 0, slabSize))
+	}
+	b := s.attrb[s.a]
+	if len(b)+size > cap(b) {
+		s.a++
+		return s.attrs(size)
+	}
+	s.attrb[s.a] = b[:len(b)+size]
+	return b[len(b) : len(b) : len(b)+size]
+}
+
+// reset makes the whole slab available again.
+//
+// Nodes handed out before the reset must no longer be used.
+func (s *nodeSlab) reset() {
+	for i := range s.nodes {
+		s.nodes[i] = s.nodes[i][:0]
+	}
+	for i := range s.attrb {
+		s.attrb[i] = s.attrb[i][:0]
+	}
+	s.n, s.a = 0, 0
+}
+
+// inputNode creates an input node like inputElement, allocating from s.
+func inputNode(s *nodeSlab, typ string, in *Input) *html.Node {
+	n := s.node(html.ElementNode, atom.Input, "input")
+	a := s.attrs(8)
+	if len(typ) > 0 {
+		a = append(a, html.Attribute{Key: "type", Val: typ})
+	}
+	n.Attr = appendInputAttrs(a, in)
+	return n
+}
+
+// appendInputAttrs appends the attributes of an input, in rendering order.
+func appendInputAttrs(a []html.Attribute, in *Input) []html.Attribute {
+	a = appendNonEmpty(a, "accept", in.Accept)
+	a = appendNonEmpty(a, "alt", in.Alt)
+	a = appendNonEmpty(a, "autocomplete", in.Autocomplete)
+	a = appendNonEmpty(a, "capture", in.Capture)
+	a = appendNonEmpty(a, "dirname", in.Dirname)
+	a = appendNonEmpty(a, "form", in.Form)
+	a = appendNonEmpty(a, "list", in.List)
+	a = appendNonEmpty(a, "inputmode", in.InputMode)
+	a = appendNonEmpty(a, "max", in.Max)
+	a = appendNonEmpty(a, "min", in.Min)
+	a = appendNonEmpty(a, "maxlength", in.MaxLength)
+	a = appendNonEmpty(a, "name", in.Name)
+	a = appendNonEmpty(a, "pattern", in.Pattern)
+	a = appendNonEmpty(a, "placeholder", in.Placeholder)
+	a = appendNonEmpty(a, "src", in.Src)
+	a = appendNonEmpty(a, "step", in.Step)
+	a = appendNonEmpty(a, "value", in.Value)
+	for _, u := range []struct {
+		k string
+		v uint64
+	}{{"height", in.Height}, {"width", in.Width}, {"size", in.Size}} {
+		if u.v != 0 {
+			a = attr(a, u.k, strconv.FormatUint(u.v, 10))
+		}
+	}
+	for _, b := range []struct {
+		k string
+		v bool
+	}{
+		{"autofocus", in.Autofocus}, {"checked", in.Checked}, {"disabled", in.Disabled},
+		{"formnovalidate", in.FormNoValidate}, {"multiple", in.Multiple},
+		{"readonly", in.ReadOnly}, {"required", in.Required},
+	} {
+		if b.v {
+			a = attr(a, b.k, b.k)
+		}
+	}
+	return in.HTML.appendAttrs(a)
+}
+
+// appendAttrs appends the global attributes, in the order of Attach.
+func (g *HTML) appendAttrs(a []html.Attribute) []html.Attribute {
+	switch g.ContentEditable {
+	case OTrue:
+		a = attr(a, "contenteditable", "true")
+	case OFalse:
+		a = attr(a, "contenteditable", "false")
+	}
+	// Hidden is a boolean attribute, so any value (even "false") hides
+	// the element.
+	if g.Hidden == OTrue {
+		a = attr(a, "hidden", "hidden")
+	}
+	switch g.Spellcheck {
+	case OTrue:
+		a = attr(a, "spellcheck", "true")
+	case OFalse:
+		a = attr(a, "spellcheck", "false")
+	}
+	for k, v := range g.Data {
+		a = attr(a, k, v)
+	}
+	if len(g.Class) > 0 {
+		a = attr(a, "class", strings.Join(g.Class, " "))
+	}
+	a = appendNonEmpty(a, "accesskey", g.AccessKey)
+	a = appendNonEmpty(a, "id", g.Id)
+	a = appendNonEmpty(a, "dir", g.Dir)
+	a = appendNonEmpty(a, "lang", g.Lang)
+	a = appendNonEmpty(a, "style", g.Style)
+	a = appendNonEmpty(a, "tabindex", g.TabIndex)
+	a = appendNonEmpty(a, "title", g.Title)
+	return appendNonEmpty(a, "translate", g.Translate)
+}
+
+// appendNonEmpty appends an attribute, unless its value is empty.
+func appendNonEmpty(a []html.Attribute, key, val string) []html.Attribute {
+	if len(val) == 0 {
+		return a
+	}
+	return attr(a, key, val)
+}
@@ -0,0 +1,150 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrNoColumns indicates that none of the columns to be written are fields
+// of the form.
+var ErrNoColumns = errors.New("No columns to write")
+
+// ColumnError indicates that a column cannot be written from a form.
+type ColumnError struct {
+	Column, Problem string
+}
+
+func (e *ColumnError) Error() string {
+	return fmt.Sprintf("Column %s %s", e.Column, e.Problem)
+}
+
+// identifier matches the names of tables and columns that may be written
+// into a statement: plain SQL identifiers, optionally qualified.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Values returns the values of the form's fields that are named by the
+// columns, keyed by column, for writing a validated submission to a table.
+//
+// Only the listed columns are written, so a field added to the form by a
+// user, or one that must not be changed, never reaches the database. A
+// listed column that is not a field of the form is left out. A checkbox's
+// value is a bool, and an empty value of a field that is not text, such as
+// a Number or a Date, is nil, for NULL. Other values are strings, which
+// the database converts to the columns' types. A field with several values,
+// such as a multiple Select, returns a *ColumnError.
+//
+// The map can be passed to query builders, such as squirrel's SetMap.
+func (s *Schema) Values(f *Form, columns ...string) (map[string]interface{}, error) {
+	vals := f.recordValues()
+	row := map[string]interface{}{}
+	for _, col := range columns {
+		if !identifier.MatchString(col) {
+			return nil, &ColumnError{Column: col, Problem: "is not a plain identifier"}
+		}
+		field := f.Field(col)
+		vv, ok := vals[col]
+		if field == nil || !ok {
+			continue
+		}
+		if len(vv) > 1 {
+			return nil, &ColumnError{Column: col, Problem: "has several values"}
+		}
+		switch field.(type) {
+		case *Checkbox:
+			row[col] = parseBool(vv[0])
+		case *Text, *Search, *TextArea, *Password, *Email, *Tel, *URL, *Hidden, *Select, *Radio:
+			row[col] = vv[0]
+		default:
+			if len(vv[0]) == 0 {
+				row[col] = nil
+			} else {
+				row[col] = vv[0]
+			}
+		}
+	}
+	if len(row) == 0 {
+		return nil, ErrNoColumns
+	}
+	return row, nil
+}
+
+// Insert returns a parameterized INSERT statement that writes the form's
+// values (see Values) for the columns to a table, and its arguments. The
+// columns are written in the order they are given, with the Schema's
+// Placeholder.
+func (s *Schema) Insert(f *Form, table string, columns ...string) (string, []interface{}, error) {
+	if !identifier.MatchString(table) {
+		return "", nil, &ColumnError{Column: table, Problem: "is not a plain identifier"}
+	}
+	row, err := s.Values(f, columns...)
+	if err != nil {
+		return "", nil, err
+	}
+	names, args := s.columnsOf(row, columns)
+	marks := make([]string, len(names))
+	for i := range names {
+		marks[i] = s.placeholder(i + 1)
+	}
+	q := "INSERT INTO " + table + " (" + strings.Join(names, ", ") + ") VALUES (" + strings.Join(marks, ", ") + ")"
+	return q, args, nil
+}
+
+// Update returns a parameterized UPDATE statement that writes the form's
+// values (see Values) for the columns to the row of a table whose key
+// column has the value of the form's field of the same name, usually a
+// Hidden field. The key is not written.
+//
+// The key's value is the one the form had before the submission was
+// reconciled (see Reconcile), since a user can change any submitted value,
+// hidden or not. If the submission changed the key, or the form has no
+// value for it, a *ColumnError is returned.
+func (s *Schema) Update(f *Form, table, key string, columns ...string) (string, []interface{}, error) {
+	if !identifier.MatchString(table) {
+		return "", nil, &ColumnError{Column: table, Problem: "is not a plain identifier"}
+	}
+	if !identifier.MatchString(key) {
+		return "", nil, &ColumnError{Column: key, Problem: "is not a plain identifier"}
+	}
+	id := f.recordValues()[key]
+	if f.initial != nil {
+		if prepared := (*f.initial)[key]; !equalValues(prepared, id) {
+			return "", nil, &ColumnError{Column: key, Problem: "was changed by the submission"}
+		}
+	}
+	if len(id) != 1 || len(id[0]) == 0 {
+		return "", nil, &ColumnError{Column: key, Problem: "has no value in the form"}
+	}
+	set := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if col != key {
+			set = append(set, col)
+		}
+	}
+	row, err := s.Values(f, set...)
+	if err != nil {
+		return "", nil, err
+	}
+	names, args := s.columnsOf(row, set)
+	for i, name := range names {
+		names[i] = name + " = " + s.placeholder(i+1)
+	}
+	q := "UPDATE " + table + " SET " + strings.Join(names, ", ") + " WHERE " + key + " = " + s.placeholder(len(names)+1)
+	return q, append(args, id[0]), nil
+}
+
+// columnsOf returns the columns of a row in the order given, and their
+// values. A column that is given twice is returned once.
+func (s *Schema) columnsOf(row map[string]interface{}, columns []string) ([]string, []interface{}) {
+	names := make([]string, 0, len(row))
+	args := make([]interface{}, 0, len(row))
+	for _, col := range columns {
+		if v, ok := row[col]; ok {
+			names = append(names, col)
+			args = append(args, v)
+			delete(row, col)
+		}
+	}
+	return names, args
+}
@@ -0,0 +1,81 @@
+package form
+
+import "fmt"
+
+// State describes where a form is in its lifecycle.
+//
+// A form moves through the states in order: it is built in code, prepared
+// (see FormHandler.Prepare), rendered, submitted (see FormHandler.Retrieve),
+// and finally validated. Some steps may be skipped; for example, a form that
+// is never rendered by this package goes straight from Prepared to
+// Submitted.
+type State uint8
+
+const (
+	// Built is the state of a newly declared form.
+	Built State = iota
+	// Prepared forms have a security token and are cached.
+	Prepared
+	// Rendered forms have been rendered after being prepared.
+	Rendered
+	// Submitted forms have had submitted data reconciled into them.
+	Submitted
+	// Validated forms have been validated after being submitted.
+	Validated
+)
+
+var stateNames = []string{"built", "prepared", "rendered", "submitted", "validated"}
+
+func (s State) String() string {
+	if int(s) < len(stateNames) {
+		return stateNames[s]
+	}
+	return fmt.Sprintf("State(%d)", s)
+}
+
+// transitions lists the allowed transitions from each state.
+var transitions = map[State][]State{
+	Built:     {Prepared},
+	Prepared:  {Prepared, Rendered, Submitted},
+	Rendered:  {Rendered, Submitted},
+	Submitted: {Validated},
+	Validated: {},
+}
+
+// StateError indicates a lifecycle step that was attempted out of order.
+type StateError struct {
+	From, To State
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("Form cannot change from %s to %s", e.From, e.To)
+}
+
+// State returns the form's current lifecycle state.
+func (f *Form) State() State {
+	return f.state
+}
+
+// Transition moves the form to a new lifecycle state.
+//
+// If the transition is not allowed from the current state, a *StateError
+// is returned and the state is unchanged.
+func (f *Form) Transition(to State) error {
+	for _, s := range transitions[f.state] {
+		if s == to {
+			f.state = to
+			return nil
+		}
+	}
+	return &StateError{From: f.state, To: to}
+}
+
+// rendered marks a prepared form as rendered.
+//
+// Forms in any other state may be rendered freely (for example, to show
+// errors after submission), so their state is unchanged.
+func (f *Form) rendered() {
+	if f.state == Prepared {
+		f.state = Rendered
+	}
+}
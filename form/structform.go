@@ -0,0 +1,217 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrNotStruct indicates that a form was to be declared from something
+// other than a struct.
+var ErrNotStruct = errors.New("Forms can only be declared from structs")
+
+// StructTagError indicates that a struct field cannot be turned into a form
+// field, because of its type or its "form" tag.
+type StructTagError struct {
+	Field, Problem string
+}
+
+func (e *StructTagError) Error() string {
+	return fmt.Sprintf("Struct field %s %s", e.Field, e.Problem)
+}
+
+// parseTag splits a "form" tag into the name and its options.
+//
+// Options are separated by commas, and are either flags ("required") or
+// key=value pairs ("label=Name").
+func parseTag(tag string) (string, map[string]string) {
+	parts := strings.Split(tag, ",")
+	opts := map[string]string{}
+	for _, p := range parts[1:] {
+		if i := strings.Index(p, "="); i >= 0 {
+			opts[strings.TrimSpace(p[:i])] = p[i+1:]
+		} else if p = strings.TrimSpace(p); len(p) > 0 {
+			opts[p] = ""
+		}
+	}
+	return parts[0], opts
+}
+
+// FromStruct declares a form with a field for each field of a struct, for
+// scaffolding create and edit pages.
+//
+// v is a struct or a pointer to one, and the fields are given its values,
+// so that a form for a new record can be made from a zero value, and a
+// form for editing from the record itself. The form's Name is the struct
+// type's name; its Action is left for the caller to set. Struct fields are
+// matched to form fields as Bind matches them, so the submitted form can be
+// bound back onto the struct.
+//
+// By default, strings are Text fields, integers and floats are Numbers
+// (floats with the step "any"), bools are Checkboxes, time.Times are
+// Dates, and slices of strings are multiple Selects. The "form" tag can
+// follow the name with comma-separated options:
+//
+//	label=TEXT        the label; the default is the struct field's name
+//	placeholder=TEXT  the placeholder of a text field
+//	required          the field is required
+//	widget=TYPE       the type of field: text, textarea, password, email,
+//	                  tel, url, search, hidden, number, range, checkbox,
+//	                  select, date, time, month, week, datetime-local,
+//	                  or color
+//	options=A|B|C     the options of a select
+//
+// For example:
+//
+//	Email string `form:"email,label=Email address,required,widget=email"`
+//
+// Labels and placeholders cannot contain commas. A struct field whose type
+// has no default widget, or whose tag cannot be applied, returns a
+// *StructTagError; a v that is not a struct returns ErrNotStruct.
+func FromStruct(v interface{}) (*Form, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrNotStruct
+	}
+	f := &Form{Name: rv.Type().Name()}
+	for _, sf := range structFields(rv.Type()) {
+		field := rv.Type().FieldByIndex(sf.index)
+		_, opts := parseTag(field.Tag.Get("form"))
+		fields, err := declareField(sf.name, field, rv.FieldByIndex(sf.index), opts)
+		if err != nil {
+			return nil, err
+		}
+		f.Fields = append(f.Fields, fields...)
+	}
+	return f, nil
+}
+
+// defaultWidget returns the widget of a struct field's type, or "".
+func defaultWidget(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return "date"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "text"
+	case reflect.Bool:
+		return "checkbox"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return "select"
+		}
+	}
+	return ""
+}
+
+// declareField declares the form fields of a struct field: the field, and
+// a Label for fields that have no Label of their own.
+func declareField(name string, sf reflect.StructField, v reflect.Value, opts map[string]string) ([]Field, error) {
+	widget, ok := opts["widget"]
+	if !ok {
+		widget = defaultWidget(sf.Type)
+	}
+	label, ok := opts["label"]
+	if !ok {
+		label = sf.Name
+	}
+	in := Input{Name: name, Label: label}
+
+	var field Field
+	switch widget {
+	case "text":
+		field = (*Text)(&in)
+	case "password":
+		field = (*Password)(&in)
+	case "email":
+		field = (*Email)(&in)
+	case "tel":
+		field = (*Tel)(&in)
+	case "url":
+		field = (*URL)(&in)
+	case "hidden":
+		in.Label = ""
+		field = (*Hidden)(&in)
+	case "number":
+		t := sf.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64 {
+			in.Step = "any"
+		}
+		field = (*Number)(&in)
+	case "range":
+		field = (*Range)(&in)
+	case "date":
+		field = (*Date)(&in)
+	case "time":
+		field = (*Time)(&in)
+	case "month":
+		field = (*Month)(&in)
+	case "week":
+		field = (*Week)(&in)
+	case "datetime-local":
+		field = (*DatetimeLocal)(&in)
+	case "search":
+		field = (*Search)(&in)
+	case "color":
+		field = (*Color)(&in)
+	case "checkbox":
+		in.Value = "true"
+		field = (*Checkbox)(&in)
+	case "textarea":
+		field = &TextArea{Name: name}
+	case "select":
+		sel := &Select{Name: name, Label: label, Multiple: sf.Type.Kind() == reflect.Slice}
+		if o, ok := opts["options"]; ok {
+			for _, val := range strings.Split(o, "|") {
+				sel.Options = append(sel.Options, &Option{Label: val, Value: val})
+			}
+		}
+		field = sel
+	case "":
+		return nil, &StructTagError{Field: sf.Name, Problem: "has a type with no default widget: " + sf.Type.String()}
+	default:
+		return nil, &StructTagError{Field: sf.Name, Problem: "has an unknown widget: " + widget}
+	}
+
+	if p, ok := opts["placeholder"]; ok && !setStringField(field, "Placeholder", p) {
+		return nil, &StructTagError{Field: sf.Name, Problem: "has a placeholder, which a " + widget + " cannot have"}
+	}
+	if _, ok := opts["required"]; ok && !setBoolField(field, "Required", true) {
+		return nil, &StructTagError{Field: sf.Name, Problem: "is required, which a " + widget + " cannot be"}
+	}
+
+	vals := formatValue(v, timeLayout(field))
+	switch field := field.(type) {
+	case *Checkbox:
+		field.Checked = len(vals) > 0 && len(vals[0]) > 0 && parseBool(vals[0])
+	case *Select:
+		for _, val := range vals {
+			for _, o := range field.Options {
+				if o := o.(*Option); o.Value == val {
+					o.Selected = true
+				}
+			}
+		}
+	case *TextArea:
+		if len(vals) > 0 {
+			field.Value = vals[0]
+		}
+		return []Field{&Label{Field: name, Text: label}, field}, nil
+	default:
+		if len(vals) > 0 {
+			setStringField(field, "Value", vals[0])
+		}
+	}
+	return []Field{field}, nil
+}
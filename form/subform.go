@@ -0,0 +1,81 @@
+package form
+
+import (
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Embed adds another form to this one as a field.
+//
+// This allows reusable groups of fields, like an address block, to be
+// declared once as a Form and composed into other forms. An embedded form
+// is rendered as a fieldset (not as a nested form element), and its fields
+// are prefixed with its Prefix. If the embedded form does not have a
+// Prefix, its Name followed by an underscore is used.
+//
+// Values, reconciliation, and computed fields are delegated to the embedded
+// form. A *Form may also be added to Fields directly, in which case its
+// Prefix is used as-is.
+func (f *Form) Embed(sub *Form) *Form {
+	if len(sub.Prefix) == 0 && len(sub.Name) > 0 {
+		sub.Prefix = sub.Name + "_"
+	}
+	return f.Add(sub)
+}
+
+// embeddedElement renders a form that has been embedded in another form.
+func (f *Form) embeddedElement(ctx *RenderContext) *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Fieldset,
+		Data:     "fieldset",
+	}
+	n.Attr = structToAttrs(f, "Name")
+	f.HTML.Attach(n)
+
+	f.ResolveLabels()
+	f.ApplyConstraints()
+	if ctx.direct() && !f.direct() {
+		ctx = ctx.withDirect(false)
+	}
+	appendElements(ctx, n, f.Fields)
+	f.applyEcho(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		f.prefixNode(c)
+	}
+	return n
+}
+
+// names returns the names submitted for a form's fields, without the form's Prefix.
+//
+// The names of embedded forms' fields are returned with the embedded
+// form's Prefix.
+func (f *Form) names() []string {
+	names := []string{}
+	add := func(n string) {
+		if len(n) > 0 {
+			names = append(names, n)
+		}
+	}
+	walkFields(f.allFields(), func(field Field) {
+		if sub, ok := field.(*Form); ok {
+			for _, n := range sub.names() {
+				add(sub.prefixed(n))
+			}
+			return
+		}
+		add(nameOf(field))
+		add(stringField(field, "Dirname"))
+		switch field := field.(type) {
+		case *Image:
+			x, y := coordNames(field.Name)
+			add(x)
+			add(y)
+		case *Money:
+			add(field.CurrencyName())
+		case *Duration:
+			add(field.UnitName())
+		}
+	})
+	return names
+}
@@ -0,0 +1,70 @@
+package form
+
+import "strconv"
+
+// AssignTabIndex numbers the form's focusable fields, in rendering order.
+//
+// Numbering begins at start (which should be at least 1). Fields that
+// already have a TabIndex keep it, and their numbers are not reused.
+// Hidden and disabled fields are skipped. Fields of embedded forms are
+// numbered along with the rest, and external fields are numbered after the
+// form's own fields.
+//
+// This is useful for layouts where the order of the fields in the document
+// is not the order in which they should be visited, such as multi-column
+// forms. It returns the next unused number, so that numbering can continue
+// across several forms on one page.
+//
+// A form with AutoTabIndex set calls AssignTabIndex(1) when it is rendered.
+func (f *Form) AssignTabIndex(start int) int {
+	used := map[int]bool{}
+	fields := []*HTML{}
+	f.eachFocusable(func(h *HTML) {
+		if len(h.TabIndex) == 0 {
+			fields = append(fields, h)
+		} else if i, err := strconv.Atoi(h.TabIndex); err == nil {
+			used[i] = true
+		}
+	})
+
+	next := start
+	for _, h := range fields {
+		for used[next] {
+			next++
+		}
+		h.TabIndex = strconv.Itoa(next)
+		next++
+	}
+	return next
+}
+
+// eachFocusable calls fn with the attributes of each focusable field.
+func (f *Form) eachFocusable(fn func(*HTML)) {
+	walkFields(f.allFields(), func(field Field) {
+		if sub, ok := field.(*Form); ok {
+			sub.eachFocusable(fn)
+			return
+		}
+		if !focusable(field) {
+			return
+		}
+		if h := htmlOf(field); h != nil {
+			fn(h)
+		}
+	})
+}
+
+// focusable returns true if a field can receive keyboard focus.
+func focusable(f Field) bool {
+	switch f.(type) {
+	case *Input, *Password, *Text, *Submit, *Tel, *URL, *Email, *Date,
+		*Time, *Month, *Week, *DatetimeLocal, *Search, *Number, *Range, *Color, *AlphaColor, *Checkbox, *Radio, *File, *Image,
+		*Reset, *ButtonInput, *Select, *TextArea, *Button, *Keygen:
+	default:
+		return false
+	}
+	if h := htmlOf(f); h == nil || h.Hidden == OTrue {
+		return false
+	}
+	return !boolField(f, "Disabled")
+}
@@ -0,0 +1,131 @@
+package form
+
+import (
+	"net/url"
+	"strconv"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Table lays out its fields as the rows of a table.
+//
+// Each field is a row. The fields of an embedded form are the cells of its
+// row, one to a field, except that hidden fields share the first cell; the
+// form's Prefix is applied to them, as it is when the form is embedded
+// elsewhere. The rows of a table are usually copies of one form with
+// different prefixes (see NewBulkEdit). Cells do not have labels, so the
+// Headers should label the columns. Any other field is a row with a single
+// cell spanning the table.
+type Table struct {
+	HTML
+	Caption string
+	Headers []string
+	Fields  []Field
+}
+
+// Element retrieves the table as an html.Node of type ElementNode.
+func (t *Table) Element() *html.Node {
+	return t.RenderElement(nil)
+}
+
+// RenderElement retrieves the table, passing the context to its fields.
+func (t *Table) RenderElement(ctx *RenderContext) *html.Node {
+	s := ctx.nodes()
+	n := s.node(html.ElementNode, atom.Table, "table")
+	t.HTML.Attach(n)
+
+	if len(t.Caption) > 0 {
+		c := s.node(html.ElementNode, atom.Caption, "caption")
+		c.AppendChild(s.node(html.TextNode, 0, t.Caption))
+		n.AppendChild(c)
+	}
+	if len(t.Headers) > 0 {
+		head := s.node(html.ElementNode, atom.Thead, "thead")
+		tr := s.node(html.ElementNode, atom.Tr, "tr")
+		for _, h := range t.Headers {
+			th := s.node(html.ElementNode, atom.Th, "th")
+			th.Attr = attr(th.Attr, "scope", "col")
+			th.AppendChild(s.node(html.TextNode, 0, h))
+			tr.AppendChild(th)
+		}
+		head.AppendChild(tr)
+		n.AppendChild(head)
+	}
+
+	body := s.node(html.ElementNode, atom.Tbody, "tbody")
+	for _, f := range t.Fields {
+		if isNil(f) {
+			continue
+		}
+		tr := s.node(html.ElementNode, atom.Tr, "tr")
+		if row, ok := f.(*Form); ok {
+			row.rowCells(ctx, tr)
+		} else {
+			td := s.node(html.ElementNode, atom.Td, "td")
+			if len(t.Headers) > 1 {
+				td.Attr = attr(td.Attr, "colspan", strconv.Itoa(len(t.Headers)))
+			}
+			appendElements(ctx, td, []Field{f})
+			tr.AppendChild(td)
+		}
+		body.AppendChild(tr)
+	}
+	n.AppendChild(body)
+	return n
+}
+
+// rowCells appends the cells of a form that is a row of a Table to tr.
+func (f *Form) rowCells(ctx *RenderContext, tr *html.Node) {
+	s := ctx.nodes()
+	if ctx.direct() && !f.direct() {
+		ctx = ctx.withDirect(false)
+	}
+	var hidden []*html.Node
+	for _, field := range f.Fields {
+		if isNil(field) {
+			continue
+		}
+		n := elementOf(ctx, field)
+		if n == nil {
+			continue
+		}
+		if _, ok := field.(*Hidden); ok {
+			hidden = append(hidden, n)
+			continue
+		}
+		td := s.node(html.ElementNode, atom.Td, "td")
+		td.AppendChild(n)
+		tr.AppendChild(td)
+	}
+	if len(hidden) > 0 {
+		first := tr.FirstChild
+		if first == nil {
+			first = s.node(html.ElementNode, atom.Td, "td")
+			tr.AppendChild(first)
+		}
+		for i := len(hidden) - 1; i >= 0; i-- {
+			first.InsertBefore(hidden[i], first.FirstChild)
+		}
+	}
+	f.applyEcho(tr)
+	f.prefixNode(tr)
+}
+
+// reconcile reconciles the rows of a table.
+//
+// A table is submitted whole, so the checkboxes of its rows are cleared
+// first; an unchecked box is not submitted, and would otherwise keep the
+// value it was rendered with.
+func (t *Table) reconcile(data *url.Values, fm *Form) {
+	for _, f := range t.Fields {
+		if row, ok := f.(*Form); ok {
+			walkFields(row.allFields(), func(field Field) {
+				if c, ok := field.(*Checkbox); ok {
+					c.Checked = false
+				}
+			})
+		}
+	}
+	reconcileFields(t.Fields, data, fm)
+}
@@ -0,0 +1,72 @@
+package form
+
+import (
+	"errors"
+	"strings"
+)
+
+// Keywords for Form.Target.
+//
+// Any other target is the name of a browsing context, and must not begin
+// with an underscore.
+const (
+	TargetSelf   = "_self"
+	TargetBlank  = "_blank"
+	TargetParent = "_parent"
+	TargetTop    = "_top"
+)
+
+// ErrInvalidTarget indicates a target that is neither a keyword nor a valid name.
+var ErrInvalidTarget = errors.New("Invalid target")
+
+// ValidTarget returns true if t is a target keyword or a valid browsing context name.
+//
+// Keywords are matched case-insensitively, as user agents do.
+func ValidTarget(t string) bool {
+	if len(t) == 0 {
+		return false
+	}
+	if t[0] != '_' {
+		return true
+	}
+	switch strings.ToLower(t) {
+	case TargetSelf, TargetBlank, TargetParent, TargetTop:
+		return true
+	}
+	return false
+}
+
+// SetTarget sets the form's Target, returning ErrInvalidTarget if it is not valid.
+//
+// A mistyped keyword (like "blank" or "_new") is treated by user agents as
+// the name of a new window, so it is best caught when the form is built.
+func (f *Form) SetTarget(t string) error {
+	if !ValidTarget(t) {
+		return ErrInvalidTarget
+	}
+	f.Target = t
+	return nil
+}
+
+// Relationship returns the value of the form's rel attribute.
+//
+// When the Target is TargetBlank, "noopener" is added unless Rel already
+// contains "noopener", "noreferrer", or "opener". This keeps the page that
+// receives the submission from controlling the page with the form, which
+// older user agents otherwise allow. Add "opener" to Rel to allow it.
+func (f *Form) Relationship() string {
+	rel := f.Rel
+	if strings.ToLower(f.Target) == TargetBlank {
+		safe := false
+		for _, r := range rel {
+			switch strings.ToLower(r) {
+			case "noopener", "noreferrer", "opener":
+				safe = true
+			}
+		}
+		if !safe {
+			rel = append(append([]string{}, rel...), "noopener")
+		}
+	}
+	return strings.Join(rel, " ")
+}
@@ -0,0 +1,59 @@
+package form
+
+import "errors"
+
+// ErrInvalidPhone may be returned by a PhoneFormatter for an invalid number.
+var ErrInvalidPhone = errors.New("Invalid phone number")
+
+// PhoneFormatter validates and formats submitted phone numbers.
+//
+// The interface matches the parse-then-format flow of libphonenumber, so
+// that a port of it can be adapted in a few lines. For example, with
+// github.com/nyaruka/phonenumbers:
+//
+//	form.TelFormatter = form.PhoneFormatterFunc(func(number, region string) (string, error) {
+//		n, err := phonenumbers.Parse(number, region)
+//		if err != nil || !phonenumbers.IsValidNumber(n) {
+//			return "", form.ErrInvalidPhone
+//		}
+//		return phonenumbers.Format(n, phonenumbers.E164), nil
+//	})
+type PhoneFormatter interface {
+	// Format returns number in the form in which it should be stored (such
+	// as E.164), or an error if it is not valid. The region is an ISO
+	// 3166-1 alpha-2 code used for numbers without a country code.
+	Format(number, region string) (string, error)
+}
+
+// PhoneFormatterFunc adapts a function to a PhoneFormatter.
+type PhoneFormatterFunc func(number, region string) (string, error)
+
+// Format calls fn(number, region).
+func (fn PhoneFormatterFunc) Format(number, region string) (string, error) {
+	return fn(number, region)
+}
+
+// TelFormatter formats the values submitted for Tel fields.
+//
+// If it is nil, values are accepted as submitted.
+var TelFormatter PhoneFormatter
+
+// DefaultPhoneRegion is the region passed to TelFormatter for fields that
+// do not have a "data-region" attribute in their Data.
+var DefaultPhoneRegion = ""
+
+// Region returns the region used to format the field's number.
+func (i *Tel) Region() string {
+	if r, ok := i.Data["data-region"]; ok {
+		return r
+	}
+	return DefaultPhoneRegion
+}
+
+// reconcileTel formats a submitted phone number with the TelFormatter.
+func reconcileTel(t *Tel, val string) (string, error) {
+	if TelFormatter == nil {
+		return val, nil
+	}
+	return TelFormatter.Format(val, t.Region())
+}
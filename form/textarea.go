@@ -1,5 +1,22 @@
 package form
 
+import (
+	"strconv"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Values for the TextArea Wrap attribute.
+const (
+	// WrapSoft submits the text without the line breaks added by wrapping.
+	// This is the default.
+	WrapSoft = "soft"
+	// WrapHard submits the text with line breaks added at the wrapping
+	// points. It requires Cols to be set.
+	WrapHard = "hard"
+)
+
 // TextArea describes a multi-line multi-column text entry form field.
 type TextArea struct {
 	HTML
@@ -7,4 +24,30 @@ type TextArea struct {
 	Autofocus, Disabled, ReadOnly, Required              bool
 	Cols, MaxLength, MinLength, Rows                     uint64
 	Value                                                string
+
+	// Counter adds a data-counter attribute, which EnhancementScript uses
+	// to show the number of characters entered (and the MaxLength, if it
+	// is set).
+	Counter bool
+}
+
+// Element retrieves the text area as an html.Node of type ElementNode.
+func (t *TextArea) Element() *html.Node {
+	n := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Textarea,
+		Data:     "textarea",
+	}
+	n.Attr = structToAttrs(t, "Autocomplete", "Dirname", "Form", "Name", "Placeholder", "Wrap")
+	n.Attr = append(n.Attr, nonZeroAttrs(t, "Cols", "MaxLength", "MinLength", "Rows")...)
+	n.Attr = append(n.Attr, boolAttrs(t, "Autofocus", "Disabled", "ReadOnly", "Required")...)
+	if t.Counter {
+		n.Attr = attr(n.Attr, "data-counter", strconv.FormatUint(t.MaxLength, 10))
+	}
+	t.HTML.Attach(n)
+
+	if len(t.Value) > 0 {
+		n.AppendChild(&html.Node{Type: html.TextNode, Data: t.Value})
+	}
+	return n
 }
@@ -0,0 +1,152 @@
+package form
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrUndoExpired indicates that a submission can no longer be undone,
+// because it has been committed, or was never submitted to the queue.
+var ErrUndoExpired = errors.New("Submission can no longer be undone")
+
+// CommitFunc carries out a submitted form.
+type CommitFunc func(ctx context.Context, f *Form) error
+
+// UndoQueue delays the commit of submissions for a grace period, during
+// which they can be undone, like the "Undo send" of a mail client.
+//
+// A handler passes a retrieved (and validated) form to Submit in place of
+// carrying it out, and shows the user the returned undo token, for example
+// as a button that posts it back. Unless Undo is called with the token
+// before the Grace period is over, the form is then passed to the commit
+// function. Undo returns the form, so that it can be shown again for the
+// user to change.
+//
+// Pending forms are held in the handler's cache, and the commit is
+// scheduled in this process, so submissions that are pending when it exits
+// are lost unless Flush is called first. A form can be undone from another
+// process that shares the cache, but only by a narrow margin before its
+// commit starts.
+type UndoQueue struct {
+	// Grace is how long a submission can be undone. The default is 10s.
+	Grace time.Duration
+	// OnError, if set, is called with the errors of the commit function,
+	// which has no request to report them to.
+	OnError func(f *Form, err error)
+
+	h      *FormHandler
+	commit CommitFunc
+	mx     sync.Mutex
+	timers map[string]*time.Timer
+}
+
+// NewUndoQueue creates an UndoQueue that holds forms in the handler's
+// cache and carries them out with commit.
+func NewUndoQueue(h *FormHandler, grace time.Duration, commit CommitFunc) *UndoQueue {
+	return &UndoQueue{
+		Grace:  grace,
+		h:      h,
+		commit: commit,
+		timers: map[string]*time.Timer{},
+	}
+}
+
+// undoKey returns the cache ID of the form with an undo token.
+func undoKey(tok string) string {
+	return "undo:" + tok
+}
+
+func (q *UndoQueue) grace() time.Duration {
+	if q.Grace <= 0 {
+		return 10 * time.Second
+	}
+	return q.Grace
+}
+
+// Submit holds a form until the grace period is over, then commits it. It
+// returns the token that undoes the submission.
+func (q *UndoQueue) Submit(form *Form) (string, error) {
+	tok, err := SecurityTokenFrom(q.h.rand())
+	if err != nil {
+		return "", err
+	}
+	grace := q.grace()
+	// The record outlives the grace period, so that a slow commit still
+	// finds it.
+	if err := q.h.cache.Set(q.h.key(undoKey(tok)), form, q.h.now().Add(2*grace+time.Minute)); err != nil {
+		return "", err
+	}
+	q.mx.Lock()
+	q.timers[tok] = time.AfterFunc(grace, func() { q.fire(tok) })
+	q.mx.Unlock()
+	return tok, nil
+}
+
+// claim removes the pending form with the token, and returns it. The lock
+// must be held.
+func (q *UndoQueue) claim(tok string) (*Form, error) {
+	if t, ok := q.timers[tok]; ok {
+		t.Stop()
+		delete(q.timers, tok)
+	}
+	form, err := q.h.Get(undoKey(tok))
+	if err != nil {
+		return nil, err
+	}
+	return form, q.h.Remove(undoKey(tok))
+}
+
+// fire commits the pending form with the token.
+func (q *UndoQueue) fire(tok string) {
+	q.mx.Lock()
+	form, err := q.claim(tok)
+	q.mx.Unlock()
+	if err != nil {
+		return
+	}
+	q.run(context.Background(), form)
+}
+
+func (q *UndoQueue) run(ctx context.Context, form *Form) {
+	if err := q.commit(ctx, form); err != nil && q.OnError != nil {
+		q.OnError(form, err)
+	}
+}
+
+// Undo cancels the submission with the token, and returns its form. If the
+// submission has been committed, or the token is unknown, ErrUndoExpired
+// is returned.
+func (q *UndoQueue) Undo(tok string) (*Form, error) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+	form, err := q.claim(tok)
+	if err == ErrFormNotFound {
+		return nil, ErrUndoExpired
+	}
+	return form, err
+}
+
+// Pending returns the number of submissions waiting to be committed.
+func (q *UndoQueue) Pending() int {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+	return len(q.timers)
+}
+
+// Flush commits every pending submission at once, for example when the
+// server is shutting down.
+func (q *UndoQueue) Flush(ctx context.Context) {
+	q.mx.Lock()
+	forms := make([]*Form, 0, len(q.timers))
+	for tok := range q.timers {
+		if form, err := q.claim(tok); err == nil {
+			forms = append(forms, form)
+		}
+	}
+	q.mx.Unlock()
+	for _, form := range forms {
+		q.run(ctx, form)
+	}
+}
@@ -0,0 +1,288 @@
+package form
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// ErrTooManyParts indicates that a multipart submission has more parts than
+// its limits allow.
+var ErrTooManyParts = errors.New("Submission has too many parts")
+
+// ErrSubmissionTooLarge indicates that the parts of a multipart submission
+// together exceed the memory its limits allow.
+var ErrSubmissionTooLarge = errors.New("Submission is too large")
+
+// PartTooLargeError indicates that one part of a multipart submission is
+// larger than its limits allow.
+type PartTooLargeError struct {
+	Name  string
+	Limit int64
+}
+
+func (e *PartTooLargeError) Error() string {
+	return fmt.Sprintf("Part %q is larger than %d bytes", e.Name, e.Limit)
+}
+
+// MultipartLimits bound the memory used to read a multipart submission.
+//
+// A zero value means that the corresponding value of
+// DefaultMultipartLimits is used.
+type MultipartLimits struct {
+	// MaxParts limits the number of parts.
+	MaxParts int
+	// MaxValueSize limits the size of each value that is not a file.
+	MaxValueSize int64
+	// MaxFileSize limits the size of each file.
+	MaxFileSize int64
+	// MaxMemory limits the size of all parts together.
+	MaxMemory int64
+}
+
+// DefaultMultipartLimits are the limits used by RetrieveMultipart when none
+// are given.
+var DefaultMultipartLimits = MultipartLimits{
+	MaxParts:     1000,
+	MaxValueSize: 64 << 10,
+	MaxFileSize:  10 << 20,
+	MaxMemory:    32 << 20,
+}
+
+// withDefaults fills in the zero limits from DefaultMultipartLimits.
+func (l MultipartLimits) withDefaults() MultipartLimits {
+	if l.MaxParts <= 0 {
+		l.MaxParts = DefaultMultipartLimits.MaxParts
+	}
+	if l.MaxValueSize <= 0 {
+		l.MaxValueSize = DefaultMultipartLimits.MaxValueSize
+	}
+	if l.MaxFileSize <= 0 {
+		l.MaxFileSize = DefaultMultipartLimits.MaxFileSize
+	}
+	if l.MaxMemory <= 0 {
+		l.MaxMemory = DefaultMultipartLimits.MaxMemory
+	}
+	return l
+}
+
+// RetrieveMultipart reads a multipart/form-data submission part by part,
+// and populates the cached form it belongs to, like Retrieve.
+//
+// Unlike http.Request.ParseMultipartForm, this does not read the whole
+// submission before looking at it. Each part is checked against the limits
+// as it is read, and reading stops with an error (ErrTooManyParts,
+// ErrSubmissionTooLarge, or a *PartTooLargeError) as soon as a limit is
+// exceeded. Once the security token has been read, parts that do not
+// belong to a field of the form are discarded without being kept, and each
+// value is checked by the field's validators as soon as it is read. Values
+// read before the token are kept within the limits, and checked when the
+// form is found.
+//
+// Files are attached to the form (see Attachments). If the handler has a
+// Scanner, each file is scanned first; a rejected file is not attached,
+// and the rejection is added to the form's Errors for its field. Filenames
+// are made safe by the handler's FilenamePolicy, which may also reject a
+// file for its extension; the name the user gave is kept as the
+// attachment's Original.
+//
+// When the submission has been read, it is reconciled with the form, and
+// the validators of fields that were not submitted are run, so the form is
+// returned in the Validated state. If the form has errors, ErrInvalid is
+// returned along with the form.
+func (f *FormHandler) RetrieveMultipart(r *http.Request, limits MultipartLimits) (*Form, error) {
+	fm, err := f.retrieveMultipart(r, limits)
+	return f.published(r.Context(), fm, err)
+}
+
+func (f *FormHandler) retrieveMultipart(r *http.Request, limits MultipartLimits) (*Form, error) {
+	limits = limits.withDefaults()
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ctx       = r.Context()
+		fm        *Form
+		names     map[string]string
+		data      = &url.Values{}
+		files     = map[string][]Attachment{}
+		validated = map[string]bool{}
+	)
+	keep := func(name string) bool {
+		_, ok := names[name]
+		return names == nil || ok
+	}
+	err = readParts(mr, limits, keep, func(p *multipart.Part, b []byte) error {
+		name := p.FormName()
+		if len(p.FileName()) > 0 {
+			files[name] = append(files[name], Attachment{Content: b, Original: originalFilename(p)})
+			return nil
+		}
+		data.Add(name, string(b))
+		if fm != nil {
+			fm.validateStreamed(ctx, names[name], string(b), validated)
+			return nil
+		}
+		if name == SecureTokenName {
+			var err error
+			if fm, err = f.Get(string(b)); err != nil {
+				return err
+			}
+			names = fm.submittedNames()
+			for n, vv := range *data {
+				for _, v := range vv {
+					if field, ok := names[n]; ok {
+						fm.validateStreamed(ctx, field, v, validated)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if fm == nil {
+		return nil, ErrNoToken
+	}
+
+	if err := fm.Transition(Submitted); err != nil {
+		return nil, err
+	}
+	policy := f.Filenames
+	if policy == nil {
+		policy = DefaultFilenamePolicy
+	}
+	if fm.files == nil {
+		fm.files = map[string][]Attachment{}
+	}
+	for name, ff := range files {
+		if field, ok := names[name]; ok {
+			for _, a := range ff {
+				var err error
+				if a.Filename, err = policy.SafeName(a.Original); err != nil {
+					fm.Errors.Add(field, err.Error())
+					continue
+				}
+				rejected, err := scanFile(ctx, f.Scanner, a)
+				if err != nil {
+					return nil, err
+				}
+				if rejected != nil {
+					fm.Errors.Add(field, rejected.Error())
+					continue
+				}
+				fm.files[field] = append(fm.files[field], a)
+			}
+		}
+	}
+	if err := Reconcile(fm, data); err != nil {
+		return fm, err
+	}
+	f.Remove(data.Get(SecureTokenName))
+
+	// Validators of fields that were not submitted check the fields'
+	// current values, as Validate would.
+	vals := fm.values()
+	rest := []string{}
+	for name := range fm.validators {
+		if !validated[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		fm.validateStreamed(ctx, name, vals.Get(name), validated)
+	}
+	fm.Transition(Validated)
+	if len(fm.Errors) > 0 {
+		return fm, ErrInvalid
+	}
+	return fm, nil
+}
+
+// readParts reads the parts of a multipart submission, checking each
+// against the limits as it is read, and calls fn with each named part that
+// keep accepts and its content. Parts that keep rejects are discarded
+// without being read into memory.
+func readParts(mr *multipart.Reader, limits MultipartLimits, keep func(name string) bool, fn func(p *multipart.Part, b []byte) error) error {
+	var (
+		parts int
+		used  int64
+	)
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if parts++; parts > limits.MaxParts {
+			return ErrTooManyParts
+		}
+		name := p.FormName()
+		if len(name) == 0 || !keep(name) {
+			continue
+		}
+
+		limit := limits.MaxValueSize
+		if len(p.FileName()) > 0 {
+			limit = limits.MaxFileSize
+		}
+		b, err := ioutil.ReadAll(io.LimitReader(p, limit+1))
+		if err != nil {
+			return err
+		}
+		if int64(len(b)) > limit {
+			return &PartTooLargeError{Name: name, Limit: limit}
+		}
+		if used += int64(len(b)); used > limits.MaxMemory {
+			return ErrSubmissionTooLarge
+		}
+		if err := fn(p, b); err != nil {
+			return err
+		}
+	}
+}
+
+// validateStreamed runs the validators of the named field against one
+// value, and records the problems in the form's Errors.
+func (f *Form) validateStreamed(ctx context.Context, name, value string, validated map[string]bool) {
+	validated[name] = true
+	for _, v := range f.validators[name] {
+		if err := v.Validate(ctx, value); err != nil {
+			f.Errors.Add(name, err.Error())
+		}
+	}
+}
+
+// submittedNames maps the names under which the form's fields are
+// submitted, with the Prefix applied, to the names of the fields. The
+// security token is included.
+func (f *Form) submittedNames() map[string]string {
+	names := map[string]string{SecureTokenName: SecureTokenName}
+	for _, n := range f.names() {
+		names[f.prefixed(n)] = n
+	}
+	return names
+}
+
+// originalFilename returns the filename of a part as the user agent sent
+// it. Part.FileName removes directories, which a FilenamePolicy does too,
+// but the original name should be kept as it was.
+func originalFilename(p *multipart.Part) string {
+	_, params, err := mime.ParseMediaType(p.Header.Get("Content-Disposition"))
+	if err != nil {
+		return p.FileName()
+	}
+	return params["filename"]
+}
@@ -0,0 +1,65 @@
+package form
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrInvalidURL indicates a URL that cannot be parsed, or is not absolute.
+	ErrInvalidURL = errors.New("Invalid URL")
+	// ErrURLScheme indicates a URL whose scheme is not in URLSchemes.
+	ErrURLScheme = errors.New("URL scheme not allowed")
+)
+
+// URLSchemes lists the schemes allowed in submitted URL fields.
+//
+// By default, only https is allowed. Schemes like javascript: and data:
+// should never be added, since the URLs are likely to be placed in links.
+var URLSchemes = []string{"https"}
+
+// NormalizeURL parses an absolute URL, and returns it in a normal form.
+//
+// The scheme must be in URLSchemes. The scheme and host are lower cased,
+// the default port for the scheme is removed, and an empty path becomes
+// "/".
+func NormalizeURL(s string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || !u.IsAbs() {
+		return "", ErrInvalidURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	allowed := false
+	for _, sc := range URLSchemes {
+		if u.Scheme == sc {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return "", ErrURLScheme
+	}
+	if len(u.Host) == 0 || len(u.Opaque) > 0 {
+		return "", ErrInvalidURL
+	}
+
+	u.Host = strings.ToLower(u.Host)
+	if host, port, err := net.SplitHostPort(u.Host); err == nil {
+		if (u.Scheme == "https" && port == "443") || (u.Scheme == "http" && port == "80") {
+			u.Host = host
+		}
+	}
+	if len(u.Path) == 0 {
+		u.Path = "/"
+	}
+	return u.String(), nil
+}
+
+// Parsed returns the field's Value as a *url.URL.
+//
+// Submitted values have already been normalized (see NormalizeURL), but a
+// default Value is parsed as-is.
+func (i *URL) Parsed() (*url.URL, error) {
+	return url.Parse(i.Value)
+}
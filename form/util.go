@@ -3,6 +3,7 @@ package form
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/html"
@@ -89,3 +90,239 @@ func structToAttrs(s interface{}, names ...string) []html.Attribute {
 	}
 	return a
 }
+
+// setAttr sets an attribute on a node, replacing any existing value.
+func setAttr(n *html.Node, key, val string) {
+	for i, a := range n.Attr {
+		if a.Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = attr(n.Attr, key, val)
+}
+
+// boolAttrs converts boolean fields on a struct into boolean attributes.
+//
+// Unlike structToAttrs, false values are omitted entirely, and true values
+// are rendered with the attribute name as the value (e.g. disabled="disabled").
+func boolAttrs(s interface{}, names ...string) []html.Attribute {
+	v := reflect.Indirect(reflect.ValueOf(s))
+	a := []html.Attribute{}
+	if v.Kind() != reflect.Struct {
+		return a
+	}
+	for _, n := range names {
+		if fv := v.FieldByName(n); fv.Kind() == reflect.Bool && fv.Bool() {
+			k := strings.ToLower(n)
+			a = attr(a, k, k)
+		}
+	}
+	return a
+}
+
+// nonZeroAttrs converts numeric fields on a struct into attributes.
+//
+// Unlike structToAttrs, zero values are omitted.
+func nonZeroAttrs(s interface{}, names ...string) []html.Attribute {
+	v := reflect.Indirect(reflect.ValueOf(s))
+	a := []html.Attribute{}
+	if v.Kind() != reflect.Struct {
+		return a
+	}
+	for _, n := range names {
+		fv := v.FieldByName(n)
+		switch fv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if fv.Int() != 0 {
+				a = attr(a, strings.ToLower(n), strconv.FormatInt(fv.Int(), 10))
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if fv.Uint() != 0 {
+				a = attr(a, strings.ToLower(n), strconv.FormatUint(fv.Uint(), 10))
+			}
+		case reflect.Float32, reflect.Float64:
+			if fv.Float() != 0 {
+				a = attr(a, strings.ToLower(n), strconv.FormatFloat(fv.Float(), 'f', -1, 64))
+			}
+		}
+	}
+	return a
+}
+
+// elementOf converts a field to an html.Node.
+//
+// A renderer registered in the context's Renderers takes precedence.
+// Otherwise, String fields become text nodes. Fields that do not implement
+// FormElement (and nil fields) return nil.
+func elementOf(ctx *RenderContext, f Field) *html.Node {
+	if isNil(f) {
+		return nil
+	}
+	if fn := ctx.renderers().Lookup(f); fn != nil {
+		if n := fn(f, ctx); n != nil {
+			return n
+		}
+	}
+	if ctx.direct() {
+		if n := directElement(f); n != nil {
+			return n
+		}
+	}
+	if s := ctx.nodes(); s != nil {
+		if typ, in := plainInput(f); in != nil {
+			return inputNode(s, typ, in)
+		}
+	}
+	switch f := f.(type) {
+	case String:
+		return ctx.nodes().node(html.TextNode, 0, string(f))
+	case *Form:
+		return f.embeddedElement(ctx)
+	case ContextElement:
+		return f.RenderElement(ctx)
+	case FormElement:
+		return f.Element()
+	}
+	return nil
+}
+
+// appendElements appends the elements (and labels) for each field as children of n.
+func appendElements(ctx *RenderContext, n *html.Node, fields []Field) {
+	for _, f := range fields {
+		for _, c := range fieldNodes(ctx, f) {
+			n.AppendChild(c)
+		}
+	}
+}
+
+// walkFields calls fn for each field, descending into containers.
+//
+// Containers are passed to fn before their children. Nil fields are skipped.
+func walkFields(fields []Field, fn func(Field)) {
+	for _, f := range fields {
+		if isNil(f) {
+			continue
+		}
+		fn(f)
+		switch f := f.(type) {
+		case *Div:
+			walkFields(f.Fields, fn)
+		case *FieldSet:
+			walkFields(f.Fields, fn)
+		case *Lazy:
+			walkFields(f.Resolve(), fn)
+		case *Label:
+			walkFields(f.Fields, fn)
+		case *Table:
+			walkFields(f.Fields, fn)
+		}
+	}
+}
+
+// isNil returns true if v is nil, or is a nil pointer, map, or slice.
+//
+// A Field holding a typed nil pointer is not itself nil, so a type switch
+// on it will match, and using it will panic.
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// nameOf returns the value of a field's Name, or the empty string.
+func nameOf(f Field) string {
+	return stringField(f, "Name")
+}
+
+// stringField returns the value of the named string field on a struct.
+//
+// If there is no such string field, this returns the empty string.
+func stringField(s interface{}, name string) string {
+	v := reflect.Indirect(reflect.ValueOf(s))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if n := v.FieldByName(name); n.IsValid() && n.Kind() == reflect.String {
+		return n.String()
+	}
+	return ""
+}
+
+// boolField returns the value of the named bool field on a struct.
+//
+// If there is no such bool field, this returns false.
+func boolField(s interface{}, name string) bool {
+	v := reflect.Indirect(reflect.ValueOf(s))
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	if n := v.FieldByName(name); n.IsValid() && n.Kind() == reflect.Bool {
+		return n.Bool()
+	}
+	return false
+}
+
+// setStringField sets the named string field on a pointer to a struct.
+//
+// This returns false if there is no such settable string field.
+func setStringField(s interface{}, name, val string) bool {
+	v := reflect.ValueOf(s)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	if fv := v.Elem().FieldByName(name); fv.Kind() == reflect.String && fv.CanSet() {
+		fv.SetString(val)
+		return true
+	}
+	return false
+}
+
+// setBoolField sets the named bool field on a pointer to a struct.
+//
+// This returns false if there is no such settable bool field.
+func setBoolField(s interface{}, name string, val bool) bool {
+	v := reflect.ValueOf(s)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return false
+	}
+	if fv := v.Elem().FieldByName(name); fv.Kind() == reflect.Bool && fv.CanSet() {
+		fv.SetBool(val)
+		return true
+	}
+	return false
+}
+
+// htmlOf returns a pointer to a field's embedded HTML attributes.
+//
+// This returns nil if the field is not a pointer to a struct that embeds HTML.
+func htmlOf(f Field) *HTML {
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	h := v.Elem().FieldByName("HTML")
+	if !h.IsValid() || !h.CanAddr() {
+		return nil
+	}
+	if h, ok := h.Addr().Interface().(*HTML); ok {
+		return h
+	}
+	return nil
+}
+
+// setFormAttr sets the Form attribute on a field, if it has one.
+func setFormAttr(f Field, id string) {
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	if fv := v.Elem().FieldByName("Form"); fv.Kind() == reflect.String && fv.CanSet() {
+		fv.SetString(id)
+	}
+}
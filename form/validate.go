@@ -0,0 +1,168 @@
+package form
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ErrInvalid indicates that a form has errors after validation.
+var ErrInvalid = errors.New("Form is invalid")
+
+// Validator checks the submitted value of a field.
+//
+// The error returned describes the problem to the user, as in "is already
+// taken".
+type Validator interface {
+	Validate(ctx context.Context, value string) error
+}
+
+// ValidatorFunc adapts a function to the Validator interface.
+type ValidatorFunc func(ctx context.Context, value string) error
+
+// Validate calls fn.
+func (fn ValidatorFunc) Validate(ctx context.Context, value string) error {
+	return fn(ctx, value)
+}
+
+// AddValidator adds validators for the named field.
+//
+// Like Computed.Compute, validators are not encoded with the form, so they
+// are lost if the form is cached by a Cache that serializes its values.
+func (f *Form) AddValidator(name string, v ...Validator) *Form {
+	if f.validators == nil {
+		f.validators = map[string][]Validator{}
+	}
+	f.validators[name] = append(f.validators[name], v...)
+	return f
+}
+
+// Validate runs the form's validators against the values of its fields.
+//
+// Validators are run concurrently, and each problem is added to the form's
+// Errors, in the order the validators were added. If the form has any
+// errors afterward (including those added when submitted data was
+// reconciled), ErrInvalid is returned. A submitted form is moved to the
+// Validated state.
+func (f *Form) Validate(ctx context.Context) error {
+	vals := f.values()
+	names := make([]string, 0, len(f.validators))
+	for name := range f.validators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make(map[string][]error, len(names))
+	var wg sync.WaitGroup
+	for _, name := range names {
+		errs := make([]error, len(f.validators[name]))
+		results[name] = errs
+		for i, v := range f.validators[name] {
+			wg.Add(1)
+			go func(i int, v Validator, value string) {
+				defer wg.Done()
+				errs[i] = v.Validate(ctx, value)
+			}(i, v, vals.Get(name))
+		}
+	}
+	wg.Wait()
+
+	for _, name := range names {
+		for _, err := range results[name] {
+			if err != nil {
+				f.Errors.Add(name, err.Error())
+			}
+		}
+	}
+	if f.state == Submitted {
+		f.Transition(Validated)
+	}
+	if len(f.Errors) > 0 {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// ValidateField checks a single value for the named field, and returns a
+// message for each problem.
+//
+// The value is checked as it would be when submitted (for example, an
+// Email field's value must be an email address), and then by the field's
+// validators. The form is not modified.
+func (f *Form) ValidateField(ctx context.Context, name, value string) []string {
+	msgs := []string{}
+	if field := f.Field(name); field != nil {
+		// Reconcile a copy of the field, so that problems are found without
+		// changing its value.
+		scratch := &Form{}
+		reconcileFields([]Field{copyField(field)}, &url.Values{name: []string{value}}, scratch)
+		msgs = append(msgs, scratch.Errors.Get(name)...)
+	}
+	for _, v := range f.validators[name] {
+		if err := v.Validate(ctx, value); err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	return msgs
+}
+
+// copyField returns a shallow copy of a field that is a pointer to a struct.
+//
+// Other fields are returned as-is.
+func copyField(field Field) Field {
+	v := reflect.ValueOf(field)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return field
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	return c.Interface()
+}
+
+// FieldValidation is the response of a ValidationHandler.
+type FieldValidation struct {
+	Path   string   `json:"path"`
+	Valid  bool     `json:"valid"`
+	Errors []string `json:"errors"`
+}
+
+// ValidationHandler returns an endpoint that validates one field of the form
+// at a time, so that problems can be shown as the user fills in the form
+// (for example, when a field loses focus).
+//
+// The request has three parameters: the path of the field (see Walk), its
+// value, and the form's security token. The token must belong to a form
+// prepared by this handler that has not yet been submitted or expired, so
+// the endpoint cannot be used by other sites. The field is checked with
+// ValidateField, and the response is a FieldValidation encoded as JSON.
+//
+// The form given is the declaration of the form, with its validators; it is
+// not modified.
+func (h *FormHandler) ValidationHandler(f *Form) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		handler := h.WithContext(r.Context())
+		if _, err := handler.prepared(r.Form.Get(SecureTokenName)); err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		path := r.Form.Get("path")
+		field := f.FieldAt(path)
+		if field == nil {
+			http.Error(w, ErrFieldNotFound.Error(), http.StatusNotFound)
+			return
+		}
+
+		msgs := f.ValidateField(r.Context(), nameOf(field), r.Form.Get("value"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(FieldValidation{Path: path, Valid: len(msgs) == 0, Errors: msgs})
+	})
+}
@@ -0,0 +1,160 @@
+package form
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// VersionName is the name of the hidden field that holds the version of
+// the record a form edits. See SetVersion.
+var VersionName = "__version__"
+
+// ErrVersion indicates that the version submitted with a form was not the
+// one it was prepared with.
+var ErrVersion = errors.New("Form version is missing or has been altered")
+
+// ConflictError indicates that the record a form edits was changed by
+// someone else while the form was being filled in.
+type ConflictError struct {
+	// Version is the version the form was prepared with, and Current is
+	// the record's version now.
+	Version, Current string
+	// Changes lists the fields whose values were changed by someone else.
+	Changes []Change
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("Record was changed from version %s to %s while it was edited", e.Version, e.Current)
+}
+
+// Change describes a field that was changed while a form was being filled
+// in: its value when the form was prepared, as submitted, and as it is now.
+type Change struct {
+	Name                string
+	Base, Yours, Theirs []string
+	// Conflict is true if the submission changed the field, too, to a
+	// different value.
+	Conflict bool
+}
+
+// SetVersion records the version of the record the form edits, such as a
+// revision number or an ETag, for optimistic concurrency control.
+//
+// When the form is prepared, the version is signed and stored in a hidden
+// field. When the form is submitted, FormHandler.CheckVersion compares it
+// with the record's current version, so that two people editing the same
+// record do not silently overwrite each other's changes. The version
+// should identify the record as well as its revision, since a signed
+// version can be submitted with any form of the same name.
+func (f *Form) SetVersion(version string) *Form {
+	f.versionSigned = false
+	if h, ok := f.Field(VersionName).(*Hidden); ok {
+		h.Value = version
+		return f
+	}
+	f.Fields = append(f.Fields, &Hidden{Name: VersionName, Value: version})
+	return f
+}
+
+// Version returns the version of the record the form edits, or "" if it
+// has none. See SetVersion. The signature of a prepared form's version is
+// not part of it; versions that contain dots are returned whole.
+func (f *Form) Version() string {
+	h, ok := f.Field(VersionName).(*Hidden)
+	if !ok {
+		return ""
+	}
+	if i := strings.LastIndex(h.Value, "."); i >= 0 && f.versionSigned {
+		return h.Value[:i]
+	}
+	return h.Value
+}
+
+// newVersionKey returns a random key for signing versions, or nil. It
+// does not read from Rand, so that creating a handler does not change the
+// tokens a test expects.
+func newVersionKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil
+	}
+	return key
+}
+
+// versionSignature returns the signature of a form's version.
+func (f *FormHandler) versionSignature(form *Form, version string) string {
+	key := f.VersionKey
+	if key == nil {
+		key = f.versionKey
+	}
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(form.Name + "\x00" + version))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+}
+
+// signVersion signs the version of a form that is being prepared.
+func (f *FormHandler) signVersion(form *Form) {
+	h, ok := form.Field(VersionName).(*Hidden)
+	if !ok || form.versionSigned || (f.VersionKey == nil && f.versionKey == nil) {
+		return
+	}
+	h.Value += "." + f.versionSignature(form, h.Value)
+	form.versionSigned = true
+}
+
+// CheckVersion checks the version submitted with a form against the
+// current version of the record it edits (see SetVersion).
+//
+// If the submitted version is missing, or its signature is not valid,
+// ErrVersion is returned. If it is not the current version, a
+// *ConflictError is returned. If latest is not nil, it is a form holding
+// the record's current values, such as one declared with FromStruct, and
+// the error lists the fields that were changed since the form was
+// prepared; the form must have been retrieved, so that its values when it
+// was prepared are known.
+func (f *FormHandler) CheckVersion(form *Form, current string, latest *Form) error {
+	h, ok := form.Field(VersionName).(*Hidden)
+	if !ok || (f.VersionKey == nil && f.versionKey == nil) {
+		return ErrVersion
+	}
+	i := strings.LastIndex(h.Value, ".")
+	if i < 0 {
+		return ErrVersion
+	}
+	version, sig := h.Value[:i], h.Value[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(f.versionSignature(form, version))) {
+		return ErrVersion
+	}
+	if version == current {
+		return nil
+	}
+
+	err := &ConflictError{Version: version, Current: current}
+	if latest == nil || form.initial == nil {
+		return err
+	}
+	base, yours, theirs := *form.initial, *form.values(), *latest.values()
+	known := map[string]bool{}
+	for _, name := range latest.names() {
+		known[name] = true
+	}
+	seen := map[string]bool{}
+	for _, name := range form.names() {
+		switch {
+		case name == SecureTokenName || name == FormIDName || name == VersionName:
+			continue
+		case seen[name] || !known[name] || equalValues(base[name], theirs[name]):
+			continue
+		}
+		seen[name] = true
+		c := Change{Name: name, Base: base[name], Yours: yours[name], Theirs: theirs[name]}
+		c.Conflict = !equalValues(c.Yours, c.Base) && !equalValues(c.Yours, c.Theirs)
+		err.Changes = append(err.Changes, c)
+	}
+	return err
+}
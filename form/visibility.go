@@ -0,0 +1,59 @@
+package form
+
+// Hide hides the named field from presentation.
+//
+// The field is rendered with the hidden attribute, so it is still part of
+// the form: its value is submitted, reconciled, and included in AsValues.
+// To keep a value out of sight without showing a widget at all, use a
+// Hidden field. To take a field out of the form entirely, use Remove.
+//
+// Hide returns false if there is no such field.
+func (f *Form) Hide(name string) bool {
+	h := htmlOf(f.Field(name))
+	if h == nil {
+		return false
+	}
+	h.Hidden = OTrue
+	return true
+}
+
+// Remove removes the named field from the form, and returns it.
+//
+// A removed field is not rendered, submitted, reconciled, or included in
+// AsValues. Nested and external fields are searched, as with Field.
+// Remove returns nil if there is no such field.
+func (f *Form) Remove(name string) Field {
+	var removed Field
+	f.Fields = removeField(f.Fields, name, &removed)
+	if removed == nil {
+		f.External = removeField(f.External, name, &removed)
+	}
+	return removed
+}
+
+// removeField removes the first field with the given name from fields or
+// their containers, storing it in removed.
+func removeField(fields []Field, name string, removed *Field) []Field {
+	for i, field := range fields {
+		if nameOf(field) == name {
+			*removed = field
+			return append(fields[:i:i], fields[i+1:]...)
+		}
+		switch c := field.(type) {
+		case *Div:
+			c.Fields = removeField(c.Fields, name, removed)
+		case *FieldSet:
+			c.Fields = removeField(c.Fields, name, removed)
+		case *Lazy:
+			c.Fields = removeField(c.Resolve(), name, removed)
+		case *Label:
+			c.Fields = removeField(c.Fields, name, removed)
+		case *Table:
+			c.Fields = removeField(c.Fields, name, removed)
+		}
+		if *removed != nil {
+			return fields
+		}
+	}
+	return fields
+}
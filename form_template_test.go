@@ -3,6 +3,7 @@ package engine
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Masterminds/engine/form"
 	"golang.org/x/net/html"
@@ -21,7 +22,8 @@ func TestFormTemplate(t *testing.T) {
 	f.Hidden = form.OFalse
 	f.Class = []string{"foo", "bar", "baz"}
 	f.Method = "POST"
-	f.Autocomplete = true
+	f.Autocomplete = form.AutocompleteOff
+	f.AcceptCharset = []string{form.CharsetUTF8}
 	f.Fields = []form.Field{
 		&form.Button{
 			HTML:  form.HTML{Id: "button-1"},
@@ -37,6 +39,11 @@ func TestFormTemplate(t *testing.T) {
 					Name:  "button-2",
 					Value: "Push Me Too!",
 				},
+				&form.Button{
+					Name:   "button-3",
+					Type:   form.ButtonReset,
+					Fields: []form.Field{form.String("Reset")},
+				},
 			},
 		},
 		&form.Keygen{
@@ -45,7 +52,14 @@ func TestFormTemplate(t *testing.T) {
 			Challenge: "How much would could a wood chuck chuck?",
 		},
 		&form.Label{For: "ever", Text: "Don't Label Me"},
+		&form.Label{Field: "text", Text: "Label ", Fields: []form.Field{form.String("Me")}},
 		&form.Output{For: "your eyes only", Name: "Bond, James Bond"},
+		&form.Computed{For: "text", Name: "computed", Value: "42"},
+		&form.Computed{Name: "computed-input", Value: "42", ReadOnly: true},
+		&form.Duration{Name: "timeout", Units: []time.Duration{time.Second, time.Minute}, Value: 90 * time.Second},
+		&form.Duration{Name: "shift", Value: 90 * time.Minute},
+		&form.Money{Name: "price", Currencies: []string{"USD", "JPY"}, Currency: "USD", Amount: 1999},
+		&form.Script{Src: "/form.js", Nonce: "abc123", Defer: true},
 		&form.Progress{Value: 0.5, Max: 1.0},
 		&form.Meter{Value: 0.5, Max: 1.0, Min: 0.2, Optimum: 0.7, Low: 0.1, High: 0.5},
 		&form.DataList{
@@ -71,25 +85,33 @@ func TestFormTemplate(t *testing.T) {
 			},
 		},
 		&form.TextArea{
-			Name:  "textarea",
-			Cols:  80,
-			Rows:  5,
-			Value: "Default text",
+			Name:    "textarea",
+			Cols:    80,
+			Rows:    5,
+			Value:   "Default text",
+			Wrap:    form.WrapHard,
+			Counter: true,
 		},
 		&form.Password{Name: "password", Label: "Enter Password"},
-		&form.Text{Name: "text"},
+		&form.Text{Name: "text", Dirname: "text.dir", HTML: form.HTML{Spellcheck: form.OFalse}},
 		&form.Submit{Name: "submit"},
 		&form.Tel{Name: "tel"},
 		&form.URL{Name: "url"},
 		&form.Email{Name: "email"},
 		&form.Date{Name: "date"},
 		&form.Time{Name: "time"},
+		&form.Month{Name: "month"},
+		&form.Week{Name: "week"},
+		&form.DatetimeLocal{Name: "starts", Min: "2026-01-01T00:00"},
+		&form.Search{Name: "q"},
 		&form.Number{Name: "number"},
 		&form.Range{Name: "range"},
 		&form.Color{Name: "color"},
+		&form.AlphaColor{Name: "alpha", Value: "#ff000080"},
 		&form.Checkbox{Name: "checkbox"},
 		&form.Radio{Name: "radio"},
 		&form.File{Name: "file"},
+		&form.File{Name: "photo", Accept: "image/*", Capture: form.CaptureUser},
 		&form.Image{Name: "image"},
 		&form.Reset{Name: "reset"},
 		&form.Hidden{Name: "hidden"},
@@ -108,4 +130,16 @@ func TestFormTemplate(t *testing.T) {
 		t.Errorf("Failed to parse generated markup: %s", err)
 	}
 
+	for _, want := range []string{
+		`<input type="month" name="month"`,
+		`<input type="week" name="week"`,
+		`<input type="datetime-local" name="starts"`,
+		`min="2026-01-01T00:00"`,
+		`<input type="search" name="q"`,
+		`capture="user"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %s in the rendered form", want)
+		}
+	}
 }